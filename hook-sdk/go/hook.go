@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hooksdk wraps the contract between the secureCodeBox operator and ScanCompletionHooks written in Go.
+//
+// The operator starts hook jobs with the urls of the raw result and findings of the scan as arguments.
+// ReadAndWrite hooks additionally get the urls to upload the updated raw result and findings to.
+// The name and namespace of the scan are passed in the SCAN_NAME and NAMESPACE environment variables.
+// Hooks only have to implement a HandleFunc and call Run from their main function:
+//
+//	func main() {
+//		hooksdk.Run(handle)
+//	}
+package hooksdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Finding is a single finding in the secureCodeBox finding format. Fields unknown to the finding format, e.g. added by other hooks, are kept when updating findings
+type Finding = findingsv1.Finding
+
+// ErrReadOnlyHook is returned when a ReadOnly hook tries to update the findings or raw result of a scan
+var ErrReadOnlyHook = errors.New("hook didn't get an url to upload to, this probably means that this hook is a ReadOnly hook. If you want to change findings or raw results you'll need to use a ReadAndWrite hook")
+
+// HandleFunc is implemented by hooks and called once with the hook of the scan.
+type HandleFunc func(ctx context.Context, hook *Hook) error
+
+// Hook gives access to the scan, its raw result and its findings.
+type Hook struct {
+	// Scan is the scan the hook is run for
+	Scan *Scan
+	// HTTPClient is used to download and upload the files of the scan. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// IncludeSuppressedFindings makes ReadOnly hooks receive the findings suppressed by FindingSuppressions as well, e.g. to audit the suppressions.
+	// ReadAndWrite hooks always receive them, as UpdateFindings replaces all findings of the scan.
+	IncludeSuppressedFindings bool
+
+	RawResultURL string
+	FindingsURL  string
+	// RawResultUploadURL and FindingsUploadURL are only set for ReadAndWrite hooks
+	RawResultUploadURL string
+	FindingsUploadURL  string
+}
+
+// Run fetches the scan passed by the operator, calls the handle function and exits the process with a non zero exit code if the hook failed.
+func Run(handle HandleFunc) {
+	ctx := context.Background()
+	scanName := os.Getenv("SCAN_NAME")
+	namespace := os.Getenv("NAMESPACE")
+	log.Printf("Starting hook for Scan %q", scanName)
+
+	if scanName == "" || namespace == "" {
+		log.Fatal("Hook was started without `SCAN_NAME` or `NAMESPACE` environment variable set. This is normally done by the operator.")
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		log.Fatalf("Failed to load the kubernetes config: %s", err)
+	}
+	kubeclient, err := client.New(cfg, client.Options{})
+	if err != nil {
+		log.Fatalf("Failed to create the kubernetes client: %s", err)
+	}
+
+	hook, err := NewHook(ctx, kubeclient, scanName, namespace, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := handle(ctx, hook); err != nil {
+		log.Fatalf("Error was returned by the handle function of the hook: %s", err)
+	}
+	log.Println("Hook completed")
+}
+
+// NewHook fetches the scan and reads the urls of its files from the arguments passed to the hook by the operator.
+func NewHook(ctx context.Context, kubeclient client.Client, scanName string, namespace string, args []string) (*Hook, error) {
+	if len(args) != 2 && len(args) != 4 {
+		return nil, fmt.Errorf("hook expects 2 (ReadOnly) or 4 (ReadAndWrite) url arguments, got %d. These are normally passed by the operator", len(args))
+	}
+
+	scan, err := getScan(ctx, kubeclient, scanName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &Hook{
+		Scan:         scan,
+		RawResultURL: args[0],
+		FindingsURL:  args[1],
+	}
+	if len(args) == 4 {
+		hook.RawResultUploadURL = args[2]
+		hook.FindingsUploadURL = args[3]
+	}
+	return hook, nil
+}
+
+// IsReadAndWrite returns true if the hook is allowed to update the findings and raw result of the scan.
+func (h *Hook) IsReadAndWrite() bool {
+	return h.FindingsUploadURL != "" && h.RawResultUploadURL != ""
+}
+
+// GetRawResult downloads the raw result file of the scanner. The caller has to close the returned reader.
+func (h *Hook) GetRawResult(ctx context.Context) (io.ReadCloser, error) {
+	return h.download(ctx, h.RawResultURL)
+}
+
+// GetFindings downloads the findings of the scan. Suppressed findings are skipped unless IncludeSuppressedFindings is set or the hook is a ReadAndWrite hook.
+func (h *Hook) GetFindings(ctx context.Context) ([]Finding, error) {
+	findings := []Finding{}
+	err := h.StreamFindings(ctx, func(finding Finding) error {
+		findings = append(findings, finding)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Fetched %d findings from the file storage", len(findings))
+	return findings, nil
+}
+
+// StreamFindings decodes the findings of the scan one by one and calls fn for each of them, without loading all findings into memory.
+// Skips suppressed findings like GetFindings. Stops at the first error returned by fn.
+func (h *Hook) StreamFindings(ctx context.Context, fn func(Finding) error) error {
+	body, err := h.download(ctx, h.FindingsURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("findings must be a json array: %w", err)
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("findings must be a json array, expected '[' but got '%v'", token)
+	}
+	for decoder.More() {
+		var finding Finding
+		if err := decoder.Decode(&finding); err != nil {
+			return fmt.Errorf("failed to decode finding: %w", err)
+		}
+		if finding.Suppression != nil && !h.includesSuppressedFindings() {
+			continue
+		}
+		if err := fn(finding); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("findings must be a json array: %w", err)
+	}
+	return nil
+}
+
+// includesSuppressedFindings checks if the findings suppressed by FindingSuppressions are passed to the hook
+func (h *Hook) includesSuppressedFindings() bool {
+	return h.IncludeSuppressedFindings || h.IsReadAndWrite()
+}
+
+// UpdateFindings replaces the findings of the scan. Only available in ReadAndWrite hooks.
+// Always pass all findings, not just the changed ones, otherwise the unchanged findings get lost.
+// The operator validates the findings and recomputes the finding stats of the scan once the hook is completed.
+func (h *Hook) UpdateFindings(ctx context.Context, findings []Finding) error {
+	if h.FindingsUploadURL == "" {
+		return ErrReadOnlyHook
+	}
+	if findings == nil {
+		findings = []Finding{}
+	}
+	body, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %w", err)
+	}
+	return h.upload(ctx, h.FindingsUploadURL, bytes.NewReader(body))
+}
+
+// UpdateRawResult replaces the raw result file of the scan. Only available in ReadAndWrite hooks.
+func (h *Hook) UpdateRawResult(ctx context.Context, rawResult io.Reader) error {
+	if h.RawResultUploadURL == "" {
+		return ErrReadOnlyHook
+	}
+	// read the raw result upfront, as presigned s3 urls don't support chunked uploads
+	body, err := io.ReadAll(rawResult)
+	if err != nil {
+		return fmt.Errorf("failed to read raw result: %w", err)
+	}
+	return h.upload(ctx, h.RawResultUploadURL, bytes.NewReader(body))
+}
+
+func (h *Hook) download(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid download url: %w", err)
+	}
+	res, err := h.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
+		return nil, fmt.Errorf("failed to download file: HTTP %d", res.StatusCode)
+	}
+	return res.Body, nil
+}
+
+func (h *Hook) upload(ctx context.Context, url string, body *bytes.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return fmt.Errorf("invalid upload url: %w", err)
+	}
+	res, err := h.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("file upload failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		return fmt.Errorf("file upload failed with response code %d: %s", res.StatusCode, text)
+	}
+	return nil
+}
+
+func (h *Hook) httpClient() *http.Client {
+	if h.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return h.HTTPClient
+}
@@ -0,0 +1,234 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hooksdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const findingsJSON = `[
+	{"id": "e18cdc5e-6b49-4346-b623-28a4e878e154", "name": "Open Port: 22", "category": "Open Port", "severity": "INFORMATIONAL", "parsed_at": "2026-10-19T08:00:00Z", "false_positive": false},
+	{"id": "4b0ef4c1-3e42-4f0c-9a0c-95c5c0bc4bd5", "name": "Open Port: 80", "category": "Open Port", "severity": "INFORMATIONAL", "parsed_at": "2026-10-19T08:00:00Z"},
+	{"id": "9c4f3a6e-8f0b-4f7e-b1d2-5a6c7d8e9f00", "name": "Open Port: 8080", "category": "Open Port", "severity": "INFORMATIONAL", "parsed_at": "2026-10-19T08:00:00Z",
+	 "suppression": {"name": "accepted-ports", "owner": "platform-team", "justification": "Internal port", "suppressed_at": "2026-10-19T08:01:00Z"}}
+]`
+
+type fileServer struct {
+	*httptest.Server
+	uploads map[string]string
+}
+
+func newFileServer() *fileServer {
+	server := &fileServer{uploads: map[string]string{}}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			switch req.URL.Path {
+			case "/findings.json":
+				_, _ = w.Write([]byte(findingsJSON))
+			case "/nmap-results.xml":
+				_, _ = w.Write([]byte("<nmaprun/>"))
+			case "/findings-object.json":
+				_, _ = w.Write([]byte(`{"name": "Open Port: 22"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			body, _ := io.ReadAll(req.Body)
+			server.uploads[req.URL.Path] = string(body)
+		}
+	}))
+	return server
+}
+
+func newScan() *unstructured.Unstructured {
+	scan := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"scanType": "nmap", "parameters": []any{"scanme.nmap.org"}},
+		"status": map[string]any{
+			"state":    "Done",
+			"findings": map[string]any{"count": int64(2), "severities": map[string]any{"informational": int64(2)}},
+		},
+	}}
+	scan.SetGroupVersionKind(scanGVK)
+	scan.SetName("nmap")
+	scan.SetNamespace("default")
+	return scan
+}
+
+func newTestHook(t *testing.T, server *fileServer, args []string) *Hook {
+	kubeclient := fake.NewClientBuilder().WithObjects(newScan()).Build()
+
+	hook, err := NewHook(context.Background(), kubeclient, "nmap", "default", args)
+	assert.NoError(t, err)
+	hook.HTTPClient = server.Client()
+	return hook
+}
+
+func TestNewHook(t *testing.T) {
+	testcases := []struct {
+		name                 string
+		args                 []string
+		expectedError        string
+		expectedReadAndWrite bool
+	}{
+		{
+			name: "Should create a ReadOnly hook",
+			args: []string{"https://s3/raw", "https://s3/findings"},
+		},
+		{
+			name:                 "Should create a ReadAndWrite hook",
+			args:                 []string{"https://s3/raw", "https://s3/findings", "https://s3/raw-upload", "https://s3/findings-upload"},
+			expectedReadAndWrite: true,
+		},
+		{
+			name:          "Should return an error for missing urls",
+			args:          []string{"https://s3/raw"},
+			expectedError: "hook expects 2 (ReadOnly) or 4 (ReadAndWrite) url arguments, got 1. These are normally passed by the operator",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeclient := fake.NewClientBuilder().WithObjects(newScan()).Build()
+
+			hook, err := NewHook(context.Background(), kubeclient, "nmap", "default", tc.args)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "nmap", hook.Scan.Name)
+			assert.Equal(t, ScanSpec{ScanType: "nmap", Parameters: []string{"scanme.nmap.org"}}, hook.Scan.Spec)
+			assert.Equal(t, "Done", hook.Scan.Status.State)
+			assert.Equal(t, uint64(2), hook.Scan.Status.Findings.FindingSeverities.Informational)
+			assert.Equal(t, tc.expectedReadAndWrite, hook.IsReadAndWrite())
+		})
+	}
+}
+
+func TestGetFindingsAndRawResult(t *testing.T) {
+	server := newFileServer()
+	defer server.Close()
+	hook := newTestHook(t, server, []string{server.URL + "/nmap-results.xml", server.URL + "/findings.json"})
+
+	findings, err := hook.GetFindings(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, findings, 2)
+	assert.Equal(t, "Open Port: 80", findings[1].Name)
+
+	rawResult, err := hook.GetRawResult(context.Background())
+	assert.NoError(t, err)
+	defer rawResult.Close()
+	data, _ := io.ReadAll(rawResult)
+	assert.Equal(t, "<nmaprun/>", string(data))
+}
+
+func TestSuppressedFindings(t *testing.T) {
+	server := newFileServer()
+	defer server.Close()
+
+	testcases := []struct {
+		name              string
+		args              []string
+		includeSuppressed bool
+		expectedFindings  int
+	}{
+		{
+			name:             "Should skip suppressed findings in ReadOnly hooks",
+			args:             []string{server.URL + "/nmap-results.xml", server.URL + "/findings.json"},
+			expectedFindings: 2,
+		},
+		{
+			name:              "Should include suppressed findings if requested",
+			args:              []string{server.URL + "/nmap-results.xml", server.URL + "/findings.json"},
+			includeSuppressed: true,
+			expectedFindings:  3,
+		},
+		{
+			name:             "Should always include suppressed findings in ReadAndWrite hooks, so that updating the findings keeps them",
+			args:             []string{server.URL + "/nmap-results.xml", server.URL + "/findings.json", server.URL + "/raw-upload", server.URL + "/findings-upload"},
+			expectedFindings: 3,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := newTestHook(t, server, tc.args)
+			hook.IncludeSuppressedFindings = tc.includeSuppressed
+
+			findings, err := hook.GetFindings(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, findings, tc.expectedFindings)
+		})
+	}
+}
+
+func TestStreamFindingsStopsOnError(t *testing.T) {
+	server := newFileServer()
+	defer server.Close()
+	hook := newTestHook(t, server, []string{server.URL + "/nmap-results.xml", server.URL + "/findings.json"})
+
+	var names []string
+	err := hook.StreamFindings(context.Background(), func(finding Finding) error {
+		names = append(names, finding.Name)
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, []string{"Open Port: 22"}, names)
+}
+
+func TestStreamFindingsRejectsNonArrays(t *testing.T) {
+	server := newFileServer()
+	defer server.Close()
+	hook := newTestHook(t, server, []string{server.URL + "/nmap-results.xml", server.URL + "/findings-object.json"})
+
+	err := hook.StreamFindings(context.Background(), func(finding Finding) error {
+		return nil
+	})
+	assert.EqualError(t, err, "findings must be a json array, expected '[' but got '{'")
+}
+
+func TestUpdateFindingsAndRawResult(t *testing.T) {
+	server := newFileServer()
+	defer server.Close()
+	hook := newTestHook(t, server, []string{
+		server.URL + "/nmap-results.xml",
+		server.URL + "/findings.json",
+		server.URL + "/nmap-results.update-field.xml",
+		server.URL + "/findings.update-field.json",
+	})
+
+	findings, err := hook.GetFindings(context.Background())
+	assert.NoError(t, err)
+	findings[0].Severity = "HIGH"
+
+	assert.NoError(t, hook.UpdateFindings(context.Background(), findings))
+	assert.NoError(t, hook.UpdateRawResult(context.Background(), strings.NewReader("<nmaprun></nmaprun>")))
+
+	assert.Contains(t, server.uploads["/findings.update-field.json"], `"severity":"HIGH"`)
+	assert.Contains(t, server.uploads["/findings.update-field.json"], `"false_positive":false`)
+	assert.Equal(t, "<nmaprun></nmaprun>", server.uploads["/nmap-results.update-field.xml"])
+}
+
+func TestReadOnlyHooksCantUpdateFindings(t *testing.T) {
+	server := newFileServer()
+	defer server.Close()
+	hook := newTestHook(t, server, []string{server.URL + "/nmap-results.xml", server.URL + "/findings.json"})
+
+	assert.ErrorIs(t, hook.UpdateFindings(context.Background(), nil), ErrReadOnlyHook)
+	assert.ErrorIs(t, hook.UpdateRawResult(context.Background(), strings.NewReader("")), ErrReadOnlyHook)
+	assert.Empty(t, server.uploads)
+}
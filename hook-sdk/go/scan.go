@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hooksdk
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scanGVK identifies the Scan resource of the secureCodeBox operator
+var scanGVK = schema.GroupVersionKind{Group: "execution.securecodebox.io", Version: "v1", Kind: "Scan"}
+
+// Scan is the subset of the Scan resource of the operator relevant for hooks.
+// The hook-sdk reads the Scan as unstructured object, so that hooks don't depend on the operator module.
+type Scan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScanSpec   `json:"spec,omitempty"`
+	Status ScanStatus `json:"status,omitempty"`
+}
+
+// ScanSpec is the subset of the spec of a Scan relevant for hooks
+type ScanSpec struct {
+	ScanType   string   `json:"scanType,omitempty"`
+	Parameters []string `json:"parameters,omitempty"`
+}
+
+// ScanStatus is the subset of the status of a Scan relevant for hooks
+type ScanStatus struct {
+	State         string       `json:"state,omitempty"`
+	RawResultType string       `json:"rawResultType,omitempty"`
+	RawResultFile string       `json:"rawResultFile,omitempty"`
+	Findings      FindingStats `json:"findings,omitempty"`
+}
+
+// FindingStats contains the number of findings of the scan by severity and category
+type FindingStats struct {
+	Count             uint64            `json:"count,omitempty"`
+	FindingSeverities FindingSeverities `json:"severities,omitempty"`
+	FindingCategories map[string]uint64 `json:"categories,omitempty"`
+}
+
+// FindingSeverities contains the number of findings per severity
+type FindingSeverities struct {
+	Informational uint64 `json:"informational,omitempty"`
+	Low           uint64 `json:"low,omitempty"`
+	Medium        uint64 `json:"medium,omitempty"`
+	High          uint64 `json:"high,omitempty"`
+}
+
+func getScan(ctx context.Context, kubeclient client.Client, name string, namespace string) (*Scan, error) {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(scanGVK)
+	if err := kubeclient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, object); err != nil {
+		return nil, fmt.Errorf("failed to get Scan from the kubernetes api: %w", err)
+	}
+
+	var scan Scan
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &scan); err != nil {
+		return nil, fmt.Errorf("failed to decode Scan: %w", err)
+	}
+	return &scan, nil
+}
@@ -2,12 +2,7 @@
 //
 // SPDX-License-Identifier: Apache-2.0
 
-import {
-  KubeConfig,
-  CustomObjectsApi,
-  setHeaderOptions,
-  PatchStrategy,
-} from "@kubernetes/client-node";
+import { KubeConfig, CustomObjectsApi } from "@kubernetes/client-node";
 
 import { handle } from "./hook/hook.js";
 
@@ -29,12 +24,27 @@ async function getRawResults() {
   return await response.text();
 }
 
-async function getFindings() {
+// Findings suppressed by FindingSuppressions are skipped unless `includeSuppressed` is set, e.g. by hooks auditing the suppressions.
+// ReadAndWrite hooks always receive them, as updateFindings replaces all findings of the scan.
+async function getFindings({ includeSuppressed = false } = {}) {
   const findingsUrl = process.argv[3];
   const response = await downloadFile(findingsUrl);
   const findings = await response.json();
   console.log(`Fetched ${findings.length} findings from the file storage`);
-  return findings;
+
+  const isReadAndWrite = process.argv[5] !== undefined;
+  if (includeSuppressed || isReadAndWrite) {
+    return findings;
+  }
+  const unsuppressedFindings = findings.filter(
+    (finding) => finding.suppression == null,
+  );
+  if (unsuppressedFindings.length < findings.length) {
+    console.log(
+      `Skipped ${findings.length - unsuppressedFindings.length} suppressed findings`,
+    );
+  }
+  return unsuppressedFindings;
 }
 
 async function uploadFile(url, fileContents) {
@@ -83,13 +93,6 @@ function updateRawResults(fileContents) {
   return uploadFile(rawResultUploadUrl, fileContents);
 }
 
-function severityCount(findings, severity) {
-  return findings.filter(
-    ({ severity: findingSeverity }) =>
-      findingSeverity.toUpperCase() === severity,
-  ).length;
-}
-
 async function updateFindings(findings) {
   const findingsUploadUrl = process.argv[5];
   if (findingsUploadUrl === undefined) {
@@ -101,43 +104,8 @@ async function updateFindings(findings) {
       "If you want to change Findings you'll need to use a ReadAndWrite Hook.",
     );
   }
+  // The operator validates the new findings and recomputes the finding stats of the scan once the hook is completed
   await uploadFile(findingsUploadUrl, JSON.stringify(findings));
-
-  // Update the scans findingStats (severities, categories, or the count) of the scan results
-  const findingCategories = new Map();
-  for (const { category } of findings) {
-    if (findingCategories.has(category)) {
-      findingCategories.set(category, findingCategories.get(category) + 1);
-    } else {
-      findingCategories.set(category, 1);
-    }
-  }
-
-  await k8sApi.patchNamespacedCustomObjectStatus(
-    {
-      group: "execution.securecodebox.io",
-      version: "v1",
-      namespace,
-      plural: "scans",
-      name: scanName,
-      body: {
-        status: {
-          findings: {
-            count: findings.length,
-            severities: {
-              informational: severityCount(findings, "INFORMATIONAL"),
-              low: severityCount(findings, "LOW"),
-              medium: severityCount(findings, "MEDIUM"),
-              high: severityCount(findings, "HIGH"),
-            },
-            categories: Object.fromEntries(findingCategories.entries()),
-          },
-        },
-      },
-    },
-    setHeaderOptions("Content-Type", PatchStrategy.MergePatch),
-  );
-  console.log("Updated status successfully");
 }
 
 async function main() {
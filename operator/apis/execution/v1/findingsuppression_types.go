@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+)
+
+// FindingSuppressionSpec defines which findings are suppressed, e.g. because the risk was accepted, and who is responsible for it
+type FindingSuppressionSpec struct {
+	// Matches selects the findings which are suppressed
+	Matches FindingSuppressionMatches `json:"matches"`
+
+	// ScanSelector restricts the suppression to scans with matching labels. Applies to all scans in the namespace if not set
+	// +kubebuilder:validation:Optional
+	ScanSelector *metav1.LabelSelector `json:"scanSelector,omitempty"`
+
+	// ExpiresAt is the time after which the suppression isn't applied anymore, e.g. to review the risk acceptance regularly
+	// +kubebuilder:validation:Optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Owner is the person or team responsible for the suppression
+	// +kubebuilder:validation:MinLength=1
+	Owner string `json:"owner"`
+
+	// Justification explains why the findings are suppressed
+	// +kubebuilder:validation:MinLength=1
+	Justification string `json:"justification"`
+}
+
+// FindingSuppressionMatches defines which findings are suppressed. Findings matching any of the rules are suppressed
+type FindingSuppressionMatches struct {
+	// +kubebuilder:validation:MinItems=1
+	AnyOf []FindingSuppressionRule `json:"anyOf"`
+}
+
+// FindingSuppressionRule matches findings whose fields match all fields set in the rule. Values can contain "*" wildcards, e.g. "*.example.com"
+type FindingSuppressionRule struct {
+	Name       string                        `json:"name,omitempty"`
+	Category   string                        `json:"category,omitempty"`
+	Location   string                        `json:"location,omitempty"`
+	Severity   string                        `json:"severity,omitempty"`
+	Attributes map[string]intstr.IntOrString `json:"attributes,omitempty"`
+}
+
+// FindingSuppressionStatus records where the suppression was applied
+type FindingSuppressionStatus struct {
+	// SuppressedFindings counts the findings suppressed in all scans
+	SuppressedFindings uint64 `json:"suppressedFindings,omitempty"`
+
+	// LastScan is the name of the last scan with findings suppressed by the suppression
+	LastScan string `json:"lastScan,omitempty"`
+
+	// LastAppliedTime is the last time findings were suppressed by the suppression
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`,description="Owner of the suppression"
+// +kubebuilder:printcolumn:name="Expires At",type=string,JSONPath=`.spec.expiresAt`,description="Time the suppression expires"
+// +kubebuilder:printcolumn:name="Suppressed",type=integer,JSONPath=`.status.suppressedFindings`,description="Number of suppressed findings"
+
+// FindingSuppression is the Schema for the FindingSuppressions API
+type FindingSuppression struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FindingSuppressionSpec   `json:"spec,omitempty"`
+	Status FindingSuppressionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FindingSuppressionList contains a list of FindingSuppression
+type FindingSuppressionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FindingSuppression `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FindingSuppression{}, &FindingSuppressionList{})
+}
+
+// IsExpired checks if the suppression expired at the given time
+func (s FindingSuppression) IsExpired(now time.Time) bool {
+	return s.Spec.ExpiresAt != nil && !now.Before(s.Spec.ExpiresAt.Time)
+}
+
+// MatchesFinding checks if any of the rules matches the finding
+func (m FindingSuppressionMatches) MatchesFinding(finding findingsv1.Finding) bool {
+	return m.Compile()(finding)
+}
+
+// Compile compiles the patterns of the rules once, to match them against many findings
+func (m FindingSuppressionMatches) Compile() func(finding findingsv1.Finding) bool {
+	rules := make([]func(finding findingsv1.Finding) bool, len(m.AnyOf))
+	for i, rule := range m.AnyOf {
+		rules[i] = rule.Compile()
+	}
+	return func(finding findingsv1.Finding) bool {
+		for _, matchesRule := range rules {
+			if matchesRule(finding) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchesFinding checks if all fields set in the rule match the finding, like the matches of CascadingRules do
+func (r FindingSuppressionRule) MatchesFinding(finding findingsv1.Finding) bool {
+	return r.Compile()(finding)
+}
+
+// Compile compiles the patterns of the rule once, to match it against many findings
+func (r FindingSuppressionRule) Compile() func(finding findingsv1.Finding) bool {
+	type fieldMatcher struct {
+		matches func(value string) bool
+		field   func(finding findingsv1.Finding) string
+	}
+	var fields []fieldMatcher
+	addField := func(rule string, field func(finding findingsv1.Finding) string) {
+		if rule != "" {
+			fields = append(fields, fieldMatcher{findingsv1.CompilePattern(rule), field})
+		}
+	}
+	addField(r.Name, func(finding findingsv1.Finding) string { return finding.Name })
+	addField(r.Category, func(finding findingsv1.Finding) string { return finding.Category })
+	addField(r.Location, func(finding findingsv1.Finding) string { return finding.Location })
+	addField(string(findingsv1.Severity(r.Severity).Normalize()), func(finding findingsv1.Finding) string { return string(finding.Severity.Normalize()) })
+
+	attributes := map[string]func(value any) bool{}
+	for key, ruleValue := range r.Attributes {
+		attributes[key] = findingsv1.CompileAttribute(ruleValue)
+	}
+
+	return func(finding findingsv1.Finding) bool {
+		for _, field := range fields {
+			if !field.matches(field.field(finding)) {
+				return false
+			}
+		}
+		for key, matchesAttribute := range attributes {
+			value, ok := finding.Attributes[key]
+			if !ok || !matchesAttribute(value) {
+				return false
+			}
+		}
+		return true
+	}
+}
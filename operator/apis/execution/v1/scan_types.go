@@ -7,6 +7,7 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -152,6 +153,46 @@ type ScanSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=false
 	Suspend *bool `json:"suspend,omitempty"`
+	// PodOverrides is a strategic merge patch which is applied to the pod spec of the scan job after it has been created from the jobTemplate of the ScanType. Only fields allowed by the operator configuration can be overridden, by default: priorityClassName, runtimeClassName, imagePullSecrets, hostAliases and dnsConfig.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodOverrides *runtime.RawExtension `json:"podOverrides,omitempty"`
+	// JobPlacement allows to control on which nodes the parser and hook jobs of the scan are executed. Takes precedence over the nodeSelector, affinity and tolerations configured for the scan and the ones configured in the ParseDefinition or ScanCompletionHook.
+	// +kubebuilder:validation:Optional
+	JobPlacement *JobPlacement `json:"jobPlacement,omitempty"`
+}
+
+// JobPlacementSpec describes on which nodes a job should be scheduled.
+type JobPlacementSpec struct {
+	// NodeSelector allows to specify a node selector, to control on which nodes the jobs run. See: https://kubernetes.io/docs/tasks/configure-pod-container/assign-pods-nodes/
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Affinity allows to specify a node affinity, to control on which nodes the jobs run. See: https://kubernetes.io/docs/tasks/configure-pod-container/assign-pods-nodes-using-node-affinity/
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Tolerations are a different way to control on which nodes the jobs are executed. See https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration/
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// JobPlacementOverride overrides the placement of the jobs of a single phase (parser or hooks) of the scan.
+type JobPlacementOverride struct {
+	JobPlacementSpec `json:",inline"`
+
+	// Resources lets you control resource limits and requests for the container of the jobs. See https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// JobPlacement configures where the parser and hook jobs of a scan are scheduled.
+// The nodeSelector, affinity and tolerations set directly on the JobPlacement apply to both parser and hook jobs, the parser and hooks fields can override them for the respective phase.
+type JobPlacement struct {
+	JobPlacementSpec `json:",inline"`
+
+	// Parser overrides the placement for the parser job.
+	// +kubebuilder:validation:Optional
+	Parser *JobPlacementOverride `json:"parser,omitempty"`
+	// Hooks overrides the placement for the jobs of the ScanCompletionHooks.
+	// +kubebuilder:validation:Optional
+	Hooks *JobPlacementOverride `json:"hooks,omitempty"`
 }
 
 type ScanState string
@@ -183,6 +224,11 @@ type ScanStatus struct {
 	// RawResultFile Filename of the result file of the scanner. e.g. `nmap-result.xml`
 	RawResultFile string `json:"rawResultFile,omitempty"`
 
+	// CurrentFindingsFile is the latest valid version of the findings, written by the parser, a ReadAndWrite hook or the operator when findings are suppressed. Defaults to `findings.json`
+	CurrentFindingsFile string `json:"currentFindingsFile,omitempty"`
+	// CurrentRawResultFile is the latest version of the raw results, written by the scanner or a ReadAndWrite hook. Defaults to the RawResultFile
+	CurrentRawResultFile string `json:"currentRawResultFile,omitempty"`
+
 	// FindingDownloadLink link to download the finding json file from. Valid for 7 days
 	FindingDownloadLink string `json:"findingDownloadLink,omitempty"`
 	// RawResultDownloadLink link to download the raw result file from. Valid for 7 days
@@ -195,9 +241,23 @@ type ScanStatus struct {
 
 	Findings FindingStats `json:"findings,omitempty"`
 
+	// SuppressedFindings lists the FindingSuppressions which suppressed findings of the scan. Suppressed findings aren't counted in the finding stats
+	SuppressedFindings []SuppressedFindingsStats `json:"suppressedFindings,omitempty"`
+
+	// FindingsDiff compares the findings of the scan with the previous completed scan of the same ScheduledScan. Only set for scans created by a ScheduledScan
+	FindingsDiff *FindingsDiffStats `json:"findingsDiff,omitempty"`
+	// FindingsDiffDownloadLink link to download the findings-diff.json file from, if the ScheduledScan enabled writeFindingsDiff. Valid for 7 days
+	FindingsDiffDownloadLink string `json:"findingsDiffDownloadLink,omitempty"`
+
 	ReadAndWriteHookStatus []HookStatus `json:"readAndWriteHookStatus,omitempty"`
 
 	OrderedHookStatuses [][]*HookStatus `json:"orderedHookStatuses,omitempty"`
+
+	// FailedHooks lists the hooks which failed but were ignored because of their "Ignore" failure policy. The scan is still marked as "Done" if all other hooks completed.
+	FailedHooks []string `json:"failedHooks,omitempty"`
+
+	// RerunHooksRequest is the value of the "securecodebox.io/rerun-hooks" annotation of the last rerun of the hooks. It's set before the annotation is removed, so that a rerun isn't started twice if removing the annotation fails.
+	RerunHooksRequest string `json:"rerunHooksRequest,omitempty"`
 }
 
 // HookState Describes the State of a Hook on a Scan
@@ -209,6 +269,8 @@ const (
 	Completed  HookState = "Completed"
 	Cancelled  HookState = "Cancelled"
 	Failed     HookState = "Failed"
+	// Skipped hooks weren't executed as the scan didn't match the `when` condition of the hook
+	Skipped HookState = "Skipped"
 )
 
 type HookStatus struct {
@@ -217,6 +279,21 @@ type HookStatus struct {
 	JobName  string    `json:"jobName,omitempty"`
 	Priority int       `json:"priority"`
 	Type     HookType  `json:"type"`
+
+	// FailurePolicy of the hook at the time the scan started processing its hooks
+	FailurePolicy *HookFailurePolicy `json:"failurePolicy,omitempty"`
+	// Retries counts how often the hook has been restarted after failing
+	Retries int32 `json:"retries,omitempty"`
+	// RetryAfter is the earliest time the next retry of the hook will be started
+	RetryAfter *metav1.Time `json:"retryAfter,omitempty"`
+	// Reruns counts how often the hook has been rerun using the "securecodebox.io/rerun-hooks" annotation
+	Reruns int32 `json:"reruns,omitempty"`
+	// HTTPStatus is the status code of the last request sent by a webhook hook
+	HTTPStatus int32 `json:"httpStatus,omitempty"`
+	// FindingsFile is the version of the findings written by a ReadAndWrite hook, e.g. `findings.<hook>.json`
+	FindingsFile string `json:"findingsFile,omitempty"`
+	// RawResultFile is the version of the raw results written by a ReadAndWrite hook
+	RawResultFile string `json:"rawResultFile,omitempty"`
 }
 
 // FindingStats contains the general stats about the results of the scan
@@ -237,6 +314,26 @@ type FindingSeverities struct {
 	High          uint64 `json:"high,omitempty"`
 }
 
+// FindingsDiffStats counts the changes of the findings compared to a previous scan. Findings are matched by their fingerprint, which is derived from their category, name, location and osi layer
+type FindingsDiffStats struct {
+	// PreviousScan is the name of the scan the findings were compared to
+	PreviousScan string `json:"previousScan"`
+	// New counts the findings which weren't identified by the previous scan
+	New uint64 `json:"new"`
+	// Resolved counts the findings of the previous scan which weren't identified again
+	Resolved uint64 `json:"resolved"`
+	// Unchanged counts the findings which were identified by both scans
+	Unchanged uint64 `json:"unchanged"`
+}
+
+// SuppressedFindingsStats counts the findings of a scan suppressed by a FindingSuppression
+type SuppressedFindingsStats struct {
+	// Name of the FindingSuppression
+	Name string `json:"name"`
+	// Count of the suppressed findings
+	Count uint64 `json:"count"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="UID",type=string,JSONPath=`.metadata.uid`,description="K8s Resource UID",priority=1
@@ -22,7 +22,74 @@ const (
 	ReadAndWrite HookType = "ReadAndWrite"
 )
 
+// WebhookHookSpec configures a hook which is executed by the operator itself by sending the results of the scan to an HTTP endpoint.
+type WebhookHookSpec struct {
+	// URL the payload is sent to using a POST request
+	URL string `json:"url"`
+
+	// SecretRef references a key of a secret in the namespace of the scan. If set, the payload is signed using HMAC-SHA256 with the value of the key as secret. The signature is sent in the "X-SecureCodeBox-Signature-256" header.
+	// +kubebuilder:validation:Optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// Headers are additional HTTP headers sent with the request
+	// +kubebuilder:validation:Optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Timeout of a single request, at most 5m. Failed requests are handled by the failurePolicy of the hook.
+	// +kubebuilder:default="10s"
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// HookCondition restricts the scans a hook is executed for.
+// All configured conditions have to match, otherwise the hook is skipped.
+type HookCondition struct {
+	// Findings is a list of expressions evaluated against the finding stats of the scan, e.g. "findings.count > 0" or "findings.severities.high >= 1". All expressions have to be true.
+	// +kubebuilder:validation:Optional
+	Findings []string `json:"findings,omitempty"`
+
+	// ScanTypes restricts the hook to scans of the listed scan types, e.g. "nmap".
+	// +kubebuilder:validation:Optional
+	ScanTypes []string `json:"scanTypes,omitempty"`
+}
+
+// HookFailurePolicyType defines what happens to the scan and the following hooks when a hook fails.
+type HookFailurePolicyType string
+
+const (
+	// HookFailurePolicyFail marks the scan as errored and stops all following hooks
+	HookFailurePolicyFail HookFailurePolicyType = "Fail"
+	// HookFailurePolicyIgnore continues with the following hooks, the scan is marked as done with failed hooks
+	HookFailurePolicyIgnore HookFailurePolicyType = "Ignore"
+	// HookFailurePolicyRetry restarts the hook and fails the scan once all retries are used up
+	HookFailurePolicyRetry HookFailurePolicyType = "Retry"
+)
+
+// HookFailurePolicy defines how the operator handles a failing hook.
+type HookFailurePolicy struct {
+	// Type of the failure policy. "Fail" marks the scan as errored and stops all following hooks, "Ignore" continues with the following hooks and "Retry" restarts the hook before failing the scan.
+	// +kubebuilder:validation:Enum=Fail;Ignore;Retry
+	// +kubebuilder:default=Fail
+	Type HookFailurePolicyType `json:"type"`
+
+	// Retry configures how often and after which delay a failed hook is restarted. Only used with the "Retry" type.
+	// +kubebuilder:validation:Optional
+	Retry *HookRetryPolicy `json:"retry,omitempty"`
+}
+
+// HookRetryPolicy configures the retries of a hook using the "Retry" failure policy.
+type HookRetryPolicy struct {
+	// Max is the number of times a failed hook is restarted before it is marked as failed
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	Max int32 `json:"max,omitempty"`
+
+	// Backoff is the time to wait before a failed hook is restarted
+	// +kubebuilder:default="30s"
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
 // ScanCompletionHookSpec defines the desired state of ScanCompletionHook
+// +kubebuilder:validation:XValidation:rule="!has(self.webhook) || self.type == 'ReadOnly'",message="webhook hooks must be of type ReadOnly"
 type ScanCompletionHookSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -35,8 +102,12 @@ type ScanCompletionHookSpec struct {
 	// +kubebuilder:validation:Optional
 	Priority int `json:"priority"`
 
-	// Image is the container image for the hooks kubernetes job
+	// Image is the container image for the hooks kubernetes job. Not used for webhook hooks.
 	Image string `json:"image,omitempty"`
+
+	// Webhook turns the hook into a webhook hook. Instead of starting a job, the operator sends the scan metadata and presigned urls to the findings and raw results to the configured url. Only supported for ReadOnly hooks.
+	// +kubebuilder:validation:Optional
+	Webhook *WebhookHookSpec `json:"webhook,omitempty"`
 	// ImagePullSecrets used to access private hooks images
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 	// Image pull policy. One of Always, Never, IfNotPresent. Defaults to Always if :latest tag is specified, or IfNotPresent otherwise. More info: https://kubernetes.io/docs/concepts/containers/images#updating-images
@@ -63,8 +134,15 @@ type ScanCompletionHookSpec struct {
 	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 
 	// Resources lets you control resource limits and requests for the parser container. See https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
-	// +kubebuilder:default={requests:{cpu:"200m",memory:"100Mi"},limits:{cpu:"400m",memory:"200Mi"}}
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// FailurePolicy defines how a failure of the hook is handled. Defaults to "Fail", which marks the scan as errored and stops all following hooks.
+	// +kubebuilder:validation:Optional
+	FailurePolicy *HookFailurePolicy `json:"failurePolicy,omitempty"`
+
+	// When restricts the execution of the hook to scans matching the condition, e.g. only scans with findings. Hooks of scans not matching the condition are marked as "Skipped".
+	// +kubebuilder:validation:Optional
+	When *HookCondition `json:"when,omitempty"`
 }
 
 // ScanCompletionHookStatus defines the observed state of ScanCompletionHook
@@ -5,6 +5,7 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,6 +26,12 @@ type ScheduledScanSpec struct {
 	// +kubebuilder:validation:Optional
 	Schedule string `json:"schedule"`
 
+	// TimeZone the schedule is evaluated in as IANA time zone name, e.g. 'Europe/Berlin'. Schedules follow the daylight saving time changes of the time zone.
+	// Defaults to the time zone of the operator, which is usually UTC. The pattern only checks the syntax of the name, unknown time zones are reported by the TimeZoneValid condition and no scans are scheduled until the time zone is fixed.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$`
+	TimeZone *string `json:"timeZone,omitempty"`
+
 	// SuccessfulJobsHistoryLimit determines how many past Scans will be kept until the oldest one will be deleted, defaults to 3. When set to 0, Scans will be deleted directly after completion
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Minimum=0
@@ -35,6 +42,10 @@ type ScheduledScanSpec struct {
 	// +kubebuilder:validation:Minimum=0
 	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
 
+	// HistoryRetention extends the history limits with retention by age and rules for scans which are always kept, e.g. for audits
+	// +kubebuilder:validation:Optional
+	HistoryRetention *HistoryRetention `json:"historyRetention,omitempty"`
+
 	// Specifies how to treat concurrent executions of a Job.
 	// Valid values are:
 	// - "Allow" (default): allows CronJobs to run concurrently;
@@ -55,6 +66,194 @@ type ScheduledScanSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=false
 	Suspend *bool `json:"suspend,omitempty"`
+
+	// WriteFindingsDiff makes the operator write a findings-diff.json file for every scan, listing the new, resolved and unchanged findings compared to the previous scan. Hooks can download it using the findingsDiffDownloadLink of the scan status.
+	// The counts of the diff are always added to the status of the scans and the ScheduledScan.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	WriteFindingsDiff bool `json:"writeFindingsDiff,omitempty"`
+
+	// AllowedWindows restricts the times at which scans are started. Scans scheduled outside of all windows are deferred to the start of the next window.
+	// If no windows are configured, scans can be started at any time.
+	// +kubebuilder:validation:Optional
+	AllowedWindows []TimeWindow `json:"allowedWindows,omitempty"`
+
+	// Blackouts are periods in which no scans are started, e.g. change freezes. Scans scheduled during a blackout are deferred to its end.
+	// +kubebuilder:validation:Optional
+	Blackouts []Blackout `json:"blackouts,omitempty"`
+
+	// Jitter is the maximum offset added to the scheduled time of every scan, e.g. '30m'. Spreads the start of ScheduledScans sharing the same schedule or interval.
+	// The offset is chosen randomly for every scan, unless the spreadMode is 'Hash'. Should be smaller than the time between two scans.
+	// +kubebuilder:validation:Optional
+	Jitter *metav1.Duration `json:"jitter,omitempty"`
+
+	// SpreadMode configures how the start of scans is spread. With 'Random' (default) every scan is offset by a random duration up to the jitter.
+	// With 'Hash' the offset is derived from the namespace and name of the ScheduledScan, and scans using an interval are started at fixed slots spread evenly across the interval.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Random
+	SpreadMode SpreadMode `json:"spreadMode,omitempty"`
+
+	// StartingDeadlineSeconds is the deadline in seconds for starting a scan after it was due. Scans which couldn't be started within the deadline, e.g. because the operator wasn't running, count as missed and are handled according to the missedRunPolicy.
+	// Without a deadline only runs which are superseded by a later run count as missed.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// MissedRunPolicy specifies how to treat missed runs.
+	// Valid values are:
+	// - "RunOnce" (default): starts a single scan for all missed runs;
+	// - "Skip": skips missed runs, the next scan is started at the next scheduled time;
+	// - "RunAll": starts a scan for every missed run, up to maxMissedRuns
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=RunOnce
+	MissedRunPolicy MissedRunPolicy `json:"missedRunPolicy,omitempty"`
+
+	// MaxMissedRuns limits the number of missed runs started with the missedRunPolicy 'RunAll', defaults to 10. Only the most recent missed runs are started, older ones are skipped
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxMissedRuns *int32 `json:"maxMissedRuns,omitempty"`
+
+	// Triggers start a scan whenever a scan of another ScheduledScan in the same namespace is completed successfully, e.g. to run nuclei after subfinder.
+	// ScheduledScans with triggers don't require a schedule or interval.
+	// +kubebuilder:validation:Optional
+	Triggers []ScheduledScanTrigger `json:"triggers,omitempty"`
+
+	// HTTPTrigger allows starting scans using the trigger endpoint of the operator (POST /trigger/{namespace}/{name}), e.g. from CI pipelines without access to the cluster.
+	// ScheduledScans with a httpTrigger don't require a schedule or interval.
+	// +kubebuilder:validation:Optional
+	HTTPTrigger *HTTPTrigger `json:"httpTrigger,omitempty"`
+}
+
+// HTTPTrigger configures the authentication and the allowed overrides for requests to the trigger endpoint of the operator
+type HTTPTrigger struct {
+	// TokenSecretRef references a key of a secret in the namespace of the ScheduledScan. Requests have to send its value as bearer token in the "Authorization" header.
+	TokenSecretRef corev1.SecretKeySelector `json:"tokenSecretRef"`
+
+	// AllowedParameterPatterns allows requests to replace the parameters of the scan. Every parameter of the request has to fully match one of the regular expressions, e.g. '-u' and 'https://[a-z0-9-]+\.example\.com'.
+	// Overrides are rejected if no patterns are set, as parameters can change what is scanned.
+	// +kubebuilder:validation:Optional
+	AllowedParameterPatterns []string `json:"allowedParameterPatterns,omitempty"`
+}
+
+// HistoryRetention configures which completed scans of a ScheduledScan are kept
+type HistoryRetention struct {
+	// MaxAge deletes completed scans which finished longer ago than the duration, e.g. '720h' to keep the scans of the last 30 days.
+	// If set, the history limits only apply when they are set explicitly.
+	// +kubebuilder:validation:Optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// KeepLastWithHighFindings always keeps the most recent successful scan with findings of high severity
+	// +kubebuilder:validation:Optional
+	KeepLastWithHighFindings bool `json:"keepLastWithHighFindings,omitempty"`
+
+	// KeepFirstOfMonth always keeps the first successful scan of every month, e.g. for audits. Months are evaluated in the timeZone of the ScheduledScan
+	// +kubebuilder:validation:Optional
+	KeepFirstOfMonth bool `json:"keepFirstOfMonth,omitempty"`
+
+	// Archive moves the files of deleted scans to the archive prefix of the s3 bucket configured for the operator, instead of deleting them
+	// +kubebuilder:validation:Optional
+	Archive bool `json:"archive,omitempty"`
+}
+
+// TriggerStatus contains the most recent completed scan of a triggering ScheduledScan which was evaluated
+type TriggerStatus struct {
+	// ScheduledScan is the name of the triggering ScheduledScan
+	ScheduledScan string `json:"scheduledScan"`
+
+	// LastScan is the name of the most recent completed scan of the triggering ScheduledScan which was evaluated
+	LastScan string `json:"lastScan,omitempty"`
+
+	// LastScanFinishedAt is the time the most recent evaluated scan was completed. Only scans completed afterwards can start a new scan
+	LastScanFinishedAt *metav1.Time `json:"lastScanFinishedAt,omitempty"`
+
+	// LastTriggerTime is the time the trigger started a scan the last time
+	LastTriggerTime *metav1.Time `json:"lastTriggerTime,omitempty"`
+}
+
+// ScheduledScanTrigger starts a scan when a scan of another ScheduledScan is done.
+// The started scan gets the environment variables SCB_TRIGGERING_SCHEDULED_SCAN, SCB_TRIGGERING_SCAN and SCB_TRIGGERING_SCAN_FINDINGS_URL referencing the completed scan.
+type ScheduledScanTrigger struct {
+	// ScheduledScan is the name of the ScheduledScan in the same namespace whose completed scans start a scan
+	ScheduledScan string `json:"scheduledScan"`
+
+	// When restricts the trigger to completed scans matching the condition, e.g. only scans with findings using "findings.count > 0"
+	// +kubebuilder:validation:Optional
+	When *HookCondition `json:"when,omitempty"`
+}
+
+// MissedRunPolicy describes how runs of a ScheduledScan are handled which weren't started in time.
+// +kubebuilder:validation:Enum=RunOnce;Skip;RunAll
+type MissedRunPolicy string
+
+const (
+	// RunOnceMissedRuns starts a single scan for all missed runs.
+	RunOnceMissedRuns MissedRunPolicy = "RunOnce"
+
+	// SkipMissedRuns skips missed runs.
+	SkipMissedRuns MissedRunPolicy = "Skip"
+
+	// RunAllMissedRuns starts a scan for every missed run.
+	RunAllMissedRuns MissedRunPolicy = "RunAll"
+)
+
+// SpreadMode describes how the start of scans is spread
+// +kubebuilder:validation:Enum=Random;Hash
+type SpreadMode string
+
+const (
+	// RandomSpread offsets every scan by a random duration up to the jitter
+	RandomSpread SpreadMode = "Random"
+
+	// HashSpread offsets every scan by the same duration derived from the namespace and name of the ScheduledScan
+	HashSpread SpreadMode = "Hash"
+)
+
+// Weekday is a day of the week
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// TimeWindow is a recurring period in which scans can be started.
+// Windows are either defined by a daily time range using start and end, optionally restricted to some days, or by a cron schedule at which the window opens and the duration for which it stays open.
+type TimeWindow struct {
+	// Days the window applies to. Defaults to every day. For windows spanning midnight the day refers to the day the window starts
+	// +kubebuilder:validation:Optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// Start time of the window in the format HH:MM, e.g. '22:00'
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start,omitempty"`
+
+	// End time of the window in the format HH:MM, e.g. '06:00'. Windows ending before they start span midnight, windows ending when they start span the whole day
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end,omitempty"`
+
+	// Schedule in Cron format at which the window opens, e.g. '0 22 * * 1-5'. Requires a duration
+	// +kubebuilder:validation:Optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Duration for which a window defined by a schedule stays open, e.g. '8h'
+	// +kubebuilder:validation:Optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// TimeZone the window is defined in as IANA time zone name, e.g. 'Europe/Berlin'. Defaults to the timeZone of the ScheduledScan or UTC. Unknown time zones are reported by the TimeZoneValid condition of the ScheduledScan
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+)*$`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Blackout is an absolute period in which no scans are started
+type Blackout struct {
+	// Start of the blackout
+	Start metav1.Time `json:"start"`
+
+	// End of the blackout
+	End metav1.Time `json:"end"`
+
+	// Reason for the blackout, e.g. 'Christmas change freeze'. Included in the event emitted when a scan is deferred
+	// +kubebuilder:validation:Optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // ConcurrencyPolicy describes how the job will be handled.
@@ -83,15 +282,42 @@ type ScheduledScanStatus struct {
 
 	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
 
+	// NextScheduleTime is the time the next scan will be started, taking the allowed windows and blackouts into account. Unset while the ScheduledScan is suspended
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// MissedRuns is the number of runs which were skipped because they were missed
+	MissedRuns int64 `json:"missedRuns,omitempty"`
+
+	// LastMissedTime is the time the most recent missed run was scheduled for
+	LastMissedTime *metav1.Time `json:"lastMissedTime,omitempty"`
+
+	// Triggers contains the most recent completed scan evaluated for every trigger
+	Triggers []TriggerStatus `json:"triggers,omitempty"`
+
 	// Findings Contains the findings stats of the most recent completed scan
 	Findings FindingStats `json:"findings,omitempty"`
 
+	// FindingsDiff contains the changes of the findings of the most recent completed scan compared to the scan before
+	FindingsDiff *FindingsDiffStats `json:"findingsDiff,omitempty"`
+
 	// Note this is stored in a string not a uint64 as OpenAPI doesn't support unsigned data types and the normal int64 format is obviously one bit too short for uint64's...
 
 	// ScanTypeHash contains a hash of the scanType used. Hash is generated after the ScheduledScan is applied to the cluster and is currently not guaranteed to be the one used by the scan controller.
 	ScanTypeHash string `json:"scanTypeHash,omitempty"`
+
+	// Conditions contain the TimeZoneValid condition, reporting unknown time zones of the schedule and allowed windows, and the TriggersValid condition, reporting triggers forming a cycle
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// TimeZoneValidCondition is the type of the condition reporting if all time zones of the ScheduledScan are known
+const TimeZoneValidCondition = "TimeZoneValid"
+
+// TriggersValidCondition is the type of the condition reporting if the triggers of the ScheduledScan form a cycle with other ScheduledScans
+const TriggersValidCondition = "TriggersValid"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="UID",type=string,JSONPath=`.metadata.uid`,description="K8s Resource UID",priority=1
@@ -99,6 +325,7 @@ type ScheduledScanStatus struct {
 // +kubebuilder:printcolumn:name="Interval",type=string,JSONPath=`.spec.interval`,description="Interval"
 // +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`,description="Schedule"
 // +kubebuilder:printcolumn:name="Findings",type=string,JSONPath=`.status.findings.count`,description="Total Finding Count"
+// +kubebuilder:printcolumn:name="Next Schedule",type=date,JSONPath=`.status.nextScheduleTime`,description="Time the next Scan will be started",priority=1
 // +kubebuilder:printcolumn:name="Parameters",type=string,JSONPath=`.spec.scanSpec.parameters`,description="Arguments passed to the Scanner",priority=1
 
 // ScheduledScan is the Schema for the scheduledscans API
@@ -12,8 +12,26 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Blackout) DeepCopyInto(out *Blackout) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Blackout.
+func (in *Blackout) DeepCopy() *Blackout {
+	if in == nil {
+		return nil
+	}
+	out := new(Blackout)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CascadeSpec) DeepCopyInto(out *CascadeSpec) {
 	*out = *in
@@ -274,9 +292,286 @@ func (in *FindingStats) DeepCopy() *FindingStats {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FindingSuppression) DeepCopyInto(out *FindingSuppression) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FindingSuppression.
+func (in *FindingSuppression) DeepCopy() *FindingSuppression {
+	if in == nil {
+		return nil
+	}
+	out := new(FindingSuppression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FindingSuppression) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FindingSuppressionList) DeepCopyInto(out *FindingSuppressionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FindingSuppression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FindingSuppressionList.
+func (in *FindingSuppressionList) DeepCopy() *FindingSuppressionList {
+	if in == nil {
+		return nil
+	}
+	out := new(FindingSuppressionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FindingSuppressionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FindingSuppressionMatches) DeepCopyInto(out *FindingSuppressionMatches) {
+	*out = *in
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]FindingSuppressionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FindingSuppressionMatches.
+func (in *FindingSuppressionMatches) DeepCopy() *FindingSuppressionMatches {
+	if in == nil {
+		return nil
+	}
+	out := new(FindingSuppressionMatches)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FindingSuppressionRule) DeepCopyInto(out *FindingSuppressionRule) {
+	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]intstr.IntOrString, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FindingSuppressionRule.
+func (in *FindingSuppressionRule) DeepCopy() *FindingSuppressionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FindingSuppressionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FindingSuppressionSpec) DeepCopyInto(out *FindingSuppressionSpec) {
+	*out = *in
+	in.Matches.DeepCopyInto(&out.Matches)
+	if in.ScanSelector != nil {
+		in, out := &in.ScanSelector, &out.ScanSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FindingSuppressionSpec.
+func (in *FindingSuppressionSpec) DeepCopy() *FindingSuppressionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FindingSuppressionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FindingSuppressionStatus) DeepCopyInto(out *FindingSuppressionStatus) {
+	*out = *in
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FindingSuppressionStatus.
+func (in *FindingSuppressionStatus) DeepCopy() *FindingSuppressionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FindingSuppressionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FindingsDiffStats) DeepCopyInto(out *FindingsDiffStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FindingsDiffStats.
+func (in *FindingsDiffStats) DeepCopy() *FindingsDiffStats {
+	if in == nil {
+		return nil
+	}
+	out := new(FindingsDiffStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPTrigger) DeepCopyInto(out *HTTPTrigger) {
+	*out = *in
+	in.TokenSecretRef.DeepCopyInto(&out.TokenSecretRef)
+	if in.AllowedParameterPatterns != nil {
+		in, out := &in.AllowedParameterPatterns, &out.AllowedParameterPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTrigger.
+func (in *HTTPTrigger) DeepCopy() *HTTPTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistoryRetention) DeepCopyInto(out *HistoryRetention) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryRetention.
+func (in *HistoryRetention) DeepCopy() *HistoryRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(HistoryRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookCondition) DeepCopyInto(out *HookCondition) {
+	*out = *in
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScanTypes != nil {
+		in, out := &in.ScanTypes, &out.ScanTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookCondition.
+func (in *HookCondition) DeepCopy() *HookCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(HookCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookFailurePolicy) DeepCopyInto(out *HookFailurePolicy) {
+	*out = *in
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(HookRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookFailurePolicy.
+func (in *HookFailurePolicy) DeepCopy() *HookFailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HookFailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookRetryPolicy) DeepCopyInto(out *HookRetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookRetryPolicy.
+func (in *HookRetryPolicy) DeepCopy() *HookRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HookRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HookStatus) DeepCopyInto(out *HookStatus) {
 	*out = *in
+	if in.FailurePolicy != nil {
+		in, out := &in.FailurePolicy, &out.FailurePolicy
+		*out = new(HookFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryAfter != nil {
+		in, out := &in.RetryAfter, &out.RetryAfter
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
@@ -289,6 +584,87 @@ func (in *HookStatus) DeepCopy() *HookStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobPlacement) DeepCopyInto(out *JobPlacement) {
+	*out = *in
+	in.JobPlacementSpec.DeepCopyInto(&out.JobPlacementSpec)
+	if in.Parser != nil {
+		in, out := &in.Parser, &out.Parser
+		*out = new(JobPlacementOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(JobPlacementOverride)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobPlacement.
+func (in *JobPlacement) DeepCopy() *JobPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(JobPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobPlacementOverride) DeepCopyInto(out *JobPlacementOverride) {
+	*out = *in
+	in.JobPlacementSpec.DeepCopyInto(&out.JobPlacementSpec)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobPlacementOverride.
+func (in *JobPlacementOverride) DeepCopy() *JobPlacementOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(JobPlacementOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobPlacementSpec) DeepCopyInto(out *JobPlacementSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobPlacementSpec.
+func (in *JobPlacementSpec) DeepCopy() *JobPlacementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobPlacementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ParseDefinition) DeepCopyInto(out *ParseDefinition) {
 	*out = *in
@@ -525,6 +901,11 @@ func (in *ScanCompletionHookList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScanCompletionHookSpec) DeepCopyInto(out *ScanCompletionHookSpec) {
 	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookHookSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]corev1.LocalObjectReference, len(*in))
@@ -581,6 +962,16 @@ func (in *ScanCompletionHookSpec) DeepCopyInto(out *ScanCompletionHookSpec) {
 		**out = **in
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.FailurePolicy != nil {
+		in, out := &in.FailurePolicy, &out.FailurePolicy
+		*out = new(HookFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.When != nil {
+		in, out := &in.When, &out.When
+		*out = new(HookCondition)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanCompletionHookSpec.
@@ -721,6 +1112,16 @@ func (in *ScanSpec) DeepCopyInto(out *ScanSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PodOverrides != nil {
+		in, out := &in.PodOverrides, &out.PodOverrides
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobPlacement != nil {
+		in, out := &in.JobPlacement, &out.JobPlacement
+		*out = new(JobPlacement)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanSpec.
@@ -741,10 +1142,22 @@ func (in *ScanStatus) DeepCopyInto(out *ScanStatus) {
 		*out = (*in).DeepCopy()
 	}
 	in.Findings.DeepCopyInto(&out.Findings)
+	if in.SuppressedFindings != nil {
+		in, out := &in.SuppressedFindings, &out.SuppressedFindings
+		*out = make([]SuppressedFindingsStats, len(*in))
+		copy(*out, *in)
+	}
+	if in.FindingsDiff != nil {
+		in, out := &in.FindingsDiff, &out.FindingsDiff
+		*out = new(FindingsDiffStats)
+		**out = **in
+	}
 	if in.ReadAndWriteHookStatus != nil {
 		in, out := &in.ReadAndWriteHookStatus, &out.ReadAndWriteHookStatus
 		*out = make([]HookStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.OrderedHookStatuses != nil {
 		in, out := &in.OrderedHookStatuses, &out.OrderedHookStatuses
@@ -757,12 +1170,17 @@ func (in *ScanStatus) DeepCopyInto(out *ScanStatus) {
 					if (*in)[i] != nil {
 						in, out := &(*in)[i], &(*out)[i]
 						*out = new(HookStatus)
-						**out = **in
+						(*in).DeepCopyInto(*out)
 					}
 				}
 			}
 		}
 	}
+	if in.FailedHooks != nil {
+		in, out := &in.FailedHooks, &out.FailedHooks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanStatus.
@@ -929,6 +1347,11 @@ func (in *ScheduledScanList) DeepCopyObject() runtime.Object {
 func (in *ScheduledScanSpec) DeepCopyInto(out *ScheduledScanSpec) {
 	*out = *in
 	out.Interval = in.Interval
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
 	if in.SuccessfulJobsHistoryLimit != nil {
 		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
 		*out = new(int32)
@@ -939,6 +1362,11 @@ func (in *ScheduledScanSpec) DeepCopyInto(out *ScheduledScanSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.HistoryRetention != nil {
+		in, out := &in.HistoryRetention, &out.HistoryRetention
+		*out = new(HistoryRetention)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ScanSpec != nil {
 		in, out := &in.ScanSpec, &out.ScanSpec
 		*out = new(ScanSpec)
@@ -949,6 +1377,47 @@ func (in *ScheduledScanSpec) DeepCopyInto(out *ScheduledScanSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AllowedWindows != nil {
+		in, out := &in.AllowedWindows, &out.AllowedWindows
+		*out = make([]TimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Blackouts != nil {
+		in, out := &in.Blackouts, &out.Blackouts
+		*out = make([]Blackout, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Jitter != nil {
+		in, out := &in.Jitter, &out.Jitter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxMissedRuns != nil {
+		in, out := &in.MaxMissedRuns, &out.MaxMissedRuns
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]ScheduledScanTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HTTPTrigger != nil {
+		in, out := &in.HTTPTrigger, &out.HTTPTrigger
+		*out = new(HTTPTrigger)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledScanSpec.
@@ -968,7 +1437,34 @@ func (in *ScheduledScanStatus) DeepCopyInto(out *ScheduledScanStatus) {
 		in, out := &in.LastScheduleTime, &out.LastScheduleTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastMissedTime != nil {
+		in, out := &in.LastMissedTime, &out.LastMissedTime
+		*out = (*in).DeepCopy()
+	}
 	in.Findings.DeepCopyInto(&out.Findings)
+	if in.FindingsDiff != nil {
+		in, out := &in.FindingsDiff, &out.FindingsDiff
+		*out = new(FindingsDiffStats)
+		**out = **in
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]TriggerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledScanStatus.
@@ -981,6 +1477,26 @@ func (in *ScheduledScanStatus) DeepCopy() *ScheduledScanStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledScanTrigger) DeepCopyInto(out *ScheduledScanTrigger) {
+	*out = *in
+	if in.When != nil {
+		in, out := &in.When, &out.When
+		*out = new(HookCondition)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledScanTrigger.
+func (in *ScheduledScanTrigger) DeepCopy() *ScheduledScanTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledScanTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScopeLimiter) DeepCopyInto(out *ScopeLimiter) {
 	*out = *in
@@ -1036,3 +1552,98 @@ func (in *ScopeLimiterRequirement) DeepCopy() *ScopeLimiterRequirement {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SuppressedFindingsStats) DeepCopyInto(out *SuppressedFindingsStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SuppressedFindingsStats.
+func (in *SuppressedFindingsStats) DeepCopy() *SuppressedFindingsStats {
+	if in == nil {
+		return nil
+	}
+	out := new(SuppressedFindingsStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWindow.
+func (in *TimeWindow) DeepCopy() *TimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerStatus) DeepCopyInto(out *TriggerStatus) {
+	*out = *in
+	if in.LastScanFinishedAt != nil {
+		in, out := &in.LastScanFinishedAt, &out.LastScanFinishedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTriggerTime != nil {
+		in, out := &in.LastTriggerTime, &out.LastTriggerTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerStatus.
+func (in *TriggerStatus) DeepCopy() *TriggerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookHookSpec) DeepCopyInto(out *WebhookHookSpec) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookHookSpec.
+func (in *WebhookHookSpec) DeepCopy() *WebhookHookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookHookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
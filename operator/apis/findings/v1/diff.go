@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Fingerprint identifies a finding across consecutive scans of the same target.
+// It is derived from the category, name, location and osi layer, as the id and dates change with every scan. The severity isn't part of the fingerprint, so a finding whose severity was changed is still recognized.
+func (f Finding) Fingerprint() string {
+	hash := sha256.New()
+	for _, field := range []string{f.Category, f.Name, f.Location, f.OSILayer} {
+		hash.Write([]byte(strings.TrimSpace(field)))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// FindingsDiff lists the changes of the findings of a scan compared to a previous scan
+type FindingsDiff struct {
+	// PreviousScan is the name of the scan the findings were compared to
+	PreviousScan string `json:"previous_scan"`
+	// New contains the findings which weren't identified by the previous scan
+	New []Finding `json:"new"`
+	// Resolved contains the findings of the previous scan which weren't identified again
+	Resolved []Finding `json:"resolved"`
+	// Unchanged contains the findings which were identified by both scans
+	Unchanged []Finding `json:"unchanged"`
+}
+
+// Diff compares the findings of two consecutive scans by their fingerprints.
+// Findings with the same fingerprint are matched in order, e.g. a finding identified twice by the current scan but only once before counts as one unchanged and one new finding.
+func Diff(previous []Finding, current []Finding) FindingsDiff {
+	diff := FindingsDiff{New: []Finding{}, Resolved: []Finding{}, Unchanged: []Finding{}}
+
+	previousByFingerprint := map[string][]Finding{}
+	for _, finding := range previous {
+		fingerprint := finding.Fingerprint()
+		previousByFingerprint[fingerprint] = append(previousByFingerprint[fingerprint], finding)
+	}
+
+	for _, finding := range current {
+		fingerprint := finding.Fingerprint()
+		if matches := previousByFingerprint[fingerprint]; len(matches) > 0 {
+			previousByFingerprint[fingerprint] = matches[1:]
+			diff.Unchanged = append(diff.Unchanged, finding)
+		} else {
+			diff.New = append(diff.New, finding)
+		}
+	}
+
+	// iterate over the previous findings again to report the resolved findings in a stable order
+	for _, finding := range previous {
+		fingerprint := finding.Fingerprint()
+		if matches := previousByFingerprint[fingerprint]; len(matches) > 0 {
+			previousByFingerprint[fingerprint] = matches[1:]
+			diff.Resolved = append(diff.Resolved, matches[0])
+		}
+	}
+	return diff
+}
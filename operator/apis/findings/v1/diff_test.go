@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func openPort(port string) Finding {
+	return Finding{ID: NewID(), Name: "Open Port: " + port, Category: "Open Port", Location: "tcp://10.0.0.1:" + port, Severity: SeverityInformational}
+}
+
+var _ = Describe("Fingerprint", func() {
+	It("should ignore the id, dates and severity of the finding", func() {
+		finding := openPort("22")
+		rescanned := openPort("22")
+		rescanned.Severity = SeverityHigh
+		rescanned.Attributes = map[string]any{"state": "open"}
+
+		Expect(rescanned.Fingerprint()).To(Equal(finding.Fingerprint()))
+	})
+
+	It("should differ for findings at different locations", func() {
+		Expect(openPort("22").Fingerprint()).NotTo(Equal(openPort("80").Fingerprint()))
+	})
+})
+
+var _ = Describe("Diff", func() {
+	It("should list the new, resolved and unchanged findings", func() {
+		previous := []Finding{openPort("22"), openPort("80")}
+		current := []Finding{openPort("80"), openPort("443")}
+
+		diff := Diff(previous, current)
+
+		Expect(diff.New).To(Equal([]Finding{current[1]}))
+		Expect(diff.Resolved).To(Equal([]Finding{previous[0]}))
+		Expect(diff.Unchanged).To(Equal([]Finding{current[0]}))
+	})
+
+	It("should match findings with the same fingerprint one by one", func() {
+		previous := []Finding{openPort("22"), openPort("22")}
+		current := []Finding{openPort("22")}
+
+		diff := Diff(previous, current)
+
+		Expect(diff.New).To(BeEmpty())
+		Expect(diff.Unchanged).To(Equal([]Finding{current[0]}))
+		Expect(diff.Resolved).To(Equal([]Finding{previous[1]}))
+	})
+
+	It("should report all findings as new if there were no previous findings", func() {
+		diff := Diff(nil, []Finding{openPort("22")})
+
+		Expect(diff.New).To(HaveLen(1))
+		Expect(diff.Resolved).To(BeEmpty())
+		Expect(diff.Unchanged).To(BeEmpty())
+	})
+})
@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package v1 contains the secureCodeBox finding format shared by the operator, parsers, hooks and scanners written in Go.
+// See https://www.securecodebox.io/docs/api/finding
+package v1
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity indicates the severity of a finding
+type Severity string
+
+const (
+	SeverityInformational Severity = "INFORMATIONAL"
+	SeverityLow           Severity = "LOW"
+	SeverityMedium        Severity = "MEDIUM"
+	SeverityHigh          Severity = "HIGH"
+)
+
+// Severities lists all valid severities, ordered from the least to the most severe
+var Severities = []Severity{SeverityInformational, SeverityLow, SeverityMedium, SeverityHigh}
+
+// Normalize returns the severity in upper case, as some parsers and hooks write lower case severities.
+func (s Severity) Normalize() Severity {
+	return Severity(strings.ToUpper(string(s)))
+}
+
+// IsValid checks if the severity is one of the known severities, ignoring its case.
+func (s Severity) IsValid() bool {
+	for _, severity := range Severities {
+		if s.Normalize() == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// Finding is a single finding in the secureCodeBox finding format.
+// Parsers only have to set the name, category and severity, the id, parsed_at and scan fields are set by the parser-sdk.
+type Finding struct {
+	// ID is the unique identifier of the finding according to RFC4122
+	ID string `json:"id" format:"uuid" description:"The unique identifier for a Finding according to RFC4122."`
+	// IdentifiedAt is the time the finding was exactly identified. This information will often not be present
+	IdentifiedAt *time.Time `json:"identified_at,omitempty" description:"Date-Time when the Finding was exactly identified according to ISO8601. This information will often not be present."`
+	// ParsedAt is the time the finding was parsed
+	ParsedAt time.Time `json:"parsed_at" description:"Date-Time when the Finding was parsed according to ISO8601. This information will always be present."`
+	// Name contains a short description of the finding
+	Name string `json:"name" description:"Contains a short description of the Finding."`
+	// Description is an in depth description, can span multiple paragraphs
+	Description string `json:"description,omitempty" nullable:"true" description:"In depth description, can span multiple paragraphs."`
+	// Category is often used to group findings based on their types
+	Category string `json:"category" description:"Is often used to group finding based on their types."`
+	// Severity indicates the severity of the finding
+	Severity Severity `json:"severity" description:"Indicates the severity of the finding."`
+	// OSILayer is the layer of the OSI model the finding belongs to, e.g. APPLICATION
+	OSILayer string `json:"osi_layer,omitempty" nullable:"true" description:"Layer of the OSI model the Finding belongs to, e.g. APPLICATION."`
+	// Mitigation contains a short description of how to mitigate the issue
+	Mitigation string `json:"mitigation,omitempty" nullable:"true" description:"Contains a short description of how to mitigate the issue."`
+	// References link to further information on the finding, e.g. CVEs
+	References []Reference `json:"references,omitempty" nullable:"true"`
+	// Attributes are not standardized. They differ from scanner to scanner
+	Attributes map[string]any `json:"attributes,omitempty" description:"Attributes are not standardized. They differ from Scanner to Scanner."`
+	// Location is the full URL with protocol, port, and path if existing
+	Location string `json:"location,omitempty" nullable:"true" description:"Full URL with protocol, port, and path if existing."`
+	// Scan contains information about the scan that identified the finding
+	Scan ScanSummary `json:"scan" description:"Contains information about the scan that identified the finding. This will always be present"`
+	// Suppression is set by the operator if the finding is suppressed by a FindingSuppression. Suppressed findings aren't counted in the finding stats of the scan.
+	// It isn't part of the schema of the findings written by parsers, see ValidateParsed
+	Suppression *Suppression `json:"suppression,omitempty" schema:"-" description:"Set by the operator if the Finding is suppressed by a FindingSuppression, e.g. because the risk was accepted. Suppressed Findings aren't counted in the finding stats of the scan."`
+
+	// AdditionalProperties keeps fields which aren't part of the finding format, e.g. added by hooks, when findings are read and written again
+	AdditionalProperties map[string]json.RawMessage `json:"-"`
+}
+
+// Reference links a finding to further information, e.g. {Type: "CVE", Value: "CVE-2021-44228"}
+type Reference struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// ScanSummary contains information about the scan that identified a finding
+type ScanSummary struct {
+	CreatedAt time.Time `json:"created_at" description:"Date-Time when the scan was created according to ISO8601"`
+	Name      string    `json:"name" description:"Name of the scan."`
+	Namespace string    `json:"namespace" description:"Namespace in which the scan was run."`
+	ScanType  string    `json:"scan_type" description:"Type of the scan."`
+}
+
+// Suppression records which FindingSuppression suppressed a finding and why
+type Suppression struct {
+	Name          string     `json:"name" description:"Name of the FindingSuppression."`
+	Owner         string     `json:"owner" description:"Person or team responsible for the suppression."`
+	Justification string     `json:"justification" description:"Reason why the Finding is suppressed."`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty" description:"Date-Time when the suppression expires according to ISO8601."`
+	SuppressedAt  time.Time  `json:"suppressed_at" description:"Date-Time when the Finding was suppressed according to ISO8601."`
+}
+
+// finding is used to (un)marshal the known fields of a Finding without recursing into its custom (un)marshal functions
+type finding Finding
+
+// UnmarshalJSON decodes the finding and keeps unknown fields in AdditionalProperties.
+func (f *Finding) UnmarshalJSON(data []byte) error {
+	var known finding
+	if err := json.Unmarshal(data, &known); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, name := range findingFieldNames() {
+		delete(fields, name)
+	}
+	known.AdditionalProperties = nil
+	if len(fields) > 0 {
+		known.AdditionalProperties = fields
+	}
+	*f = Finding(known)
+	return nil
+}
+
+// MarshalJSON encodes the finding including its AdditionalProperties.
+func (f Finding) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(finding(f))
+	if err != nil || len(f.AdditionalProperties) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range f.AdditionalProperties {
+		if _, known := fields[name]; !known {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// AddIDsAndDates sets a random id and the parse time on all findings which don't have them yet.
+func AddIDsAndDates(findings []Finding) []Finding {
+	parsedAt := time.Now().UTC()
+	for i := range findings {
+		if findings[i].ID == "" {
+			findings[i].ID = NewID()
+		}
+		if findings[i].ParsedAt.IsZero() {
+			findings[i].ParsedAt = parsedAt
+		}
+	}
+	return findings
+}
+
+// NewID returns a random (version 4) uuid to be used as id of a finding
+func NewID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"encoding/json"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const validFinding = `{
+	"id": "e18cdc5e-6b49-4346-b623-28a4e878e154",
+	"parsed_at": "2026-10-19T08:00:00Z",
+	"name": "Open Port: 22",
+	"category": "Open Port",
+	"severity": "informational",
+	"attributes": {"port": 22, "service": "ssh"},
+	"scan": {"created_at": "2026-10-19T07:55:00Z", "name": "nmap", "namespace": "default", "scan_type": "nmap"},
+	"false_positive": true
+}`
+
+var _ = Describe("Finding", func() {
+	It("should keep unknown fields when decoding and encoding a finding", func() {
+		var finding Finding
+		Expect(json.Unmarshal([]byte(validFinding), &finding)).To(Succeed())
+
+		Expect(finding.Name).To(Equal("Open Port: 22"))
+		Expect(finding.Scan.Name).To(Equal("nmap"))
+		Expect(finding.AdditionalProperties).To(HaveKeyWithValue("false_positive", json.RawMessage("true")))
+
+		data, err := json.Marshal(finding)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(data).To(MatchJSON(validFinding))
+	})
+
+	It("should normalize severities ignoring their case", func() {
+		Expect(Severity("medium").Normalize()).To(Equal(SeverityMedium))
+		Expect(Severity("High").IsValid()).To(BeTrue())
+		Expect(Severity("CRITICAL").IsValid()).To(BeFalse())
+	})
+
+	It("should add ids and parse dates to findings without them", func() {
+		findings := AddIDsAndDates([]Finding{{Name: "Open Port: 22"}, {ID: "e18cdc5e-6b49-4346-b623-28a4e878e154"}})
+
+		Expect(findings[0].ID).To(MatchRegexp(uuidPattern.String()))
+		Expect(findings[0].ParsedAt.IsZero()).To(BeFalse())
+		Expect(findings[1].ID).To(Equal("e18cdc5e-6b49-4346-b623-28a4e878e154"))
+	})
+})
+
+var _ = Describe("JSONSchema", func() {
+	It("should match the schema used by the Node.js parser-sdk", func() {
+		// regenerate the schema with `task generate` if this fails
+		expected, err := os.ReadFile("../../../../parser-sdk/nodejs/findings-schema.json")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		schema, err := JSONSchema()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(schema)).To(Equal(string(expected)))
+	})
+})
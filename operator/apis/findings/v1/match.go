@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// CompileAttribute compiles the value of a rule once, to match it against the attributes of many findings.
+// Strings are matched using CompilePattern, numbers have to be equal.
+// Besides the types returned by json.Unmarshal, numbers decoded using json.Decoder.UseNumber are supported.
+func CompileAttribute(ruleValue intstr.IntOrString) func(value any) bool {
+	matchesPattern := CompilePattern(ruleValue.String())
+	return func(value any) bool {
+		switch value := value.(type) {
+		case string:
+			return matchesPattern(value)
+		case float64:
+			if ruleValue.Type == intstr.Int {
+				return value == float64(ruleValue.IntVal)
+			}
+			return ruleValue.StrVal == strconv.FormatFloat(value, 'f', -1, 64)
+		case int:
+			return ruleValue.Type == intstr.Int && value == int(ruleValue.IntVal)
+		case json.Number:
+			if ruleValue.Type == intstr.Int {
+				number, err := value.Int64()
+				return err == nil && number == int64(ruleValue.IntVal)
+			}
+			if ruleValue.StrVal == value.String() {
+				return true
+			}
+			number, err := value.Float64()
+			return err == nil && ruleValue.StrVal == strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		return false
+	}
+}
+
+// CompilePattern compiles a pattern in which "*" matches any sequence of characters once, to match it against many values.
+// Like the matcher package used by the cascading-scans hook, a leading "!" negates the pattern.
+func CompilePattern(pattern string) func(value string) bool {
+	if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+		matchesNegated := CompilePattern(negated)
+		return func(value string) bool {
+			return pattern == value || !matchesNegated(value)
+		}
+	}
+	if !strings.Contains(pattern, "*") {
+		return func(value string) bool {
+			return pattern == value
+		}
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expression := regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+	return expression.MatchString
+}
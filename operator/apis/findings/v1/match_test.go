@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("CompilePattern", func() {
+	It("should match values equal to the pattern", func() {
+		Expect(CompilePattern("Open Port")("Open Port")).To(BeTrue())
+		Expect(CompilePattern("Open Port")("open port")).To(BeFalse())
+	})
+
+	It("should support wildcards and negations", func() {
+		Expect(CompilePattern("tcp://*:443")("tcp://10.0.0.1:443")).To(BeTrue())
+		Expect(CompilePattern("tcp://*:443")("tcp://10.0.0.1:80")).To(BeFalse())
+		Expect(CompilePattern("!ssh")("https")).To(BeTrue())
+		Expect(CompilePattern("!ssh")("ssh")).To(BeFalse())
+	})
+})
+
+var _ = Describe("CompileAttribute", func() {
+	It("should match strings as patterns and numbers by value", func() {
+		Expect(CompileAttribute(intstr.FromString("http*"))("https")).To(BeTrue())
+		Expect(CompileAttribute(intstr.FromInt(443))(float64(443))).To(BeTrue())
+		Expect(CompileAttribute(intstr.FromInt(443))(json.Number("443"))).To(BeTrue())
+		Expect(CompileAttribute(intstr.FromString("443"))(float64(443))).To(BeTrue())
+		Expect(CompileAttribute(intstr.FromInt(80))(float64(443))).To(BeFalse())
+	})
+})
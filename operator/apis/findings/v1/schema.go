@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// JSONSchema generates the JSON schema of an array of findings from the Finding type.
+// The generated schema is used by the Node.js parser-sdk to validate findings, it is regenerated by `task generate`.
+// Fields tagged with `schema:"-"` are only set by the operator and aren't part of the schema.
+func JSONSchema() ([]byte, error) {
+	schema := orderedObject{
+		{"$schema", "http://json-schema.org/draft-04/schema"},
+		{"type", "array"},
+		{"description", "Array of Findings."},
+		{"items", orderedObject{{"$ref", "#/$defs/finding"}}},
+		{"$defs", orderedObject{{"finding", typeSchema(reflect.TypeFor[Finding](), "")}}},
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// orderedObject is a json object which keeps the order of its fields, to generate a readable schema
+type orderedObject []orderedField
+
+type orderedField struct {
+	key   string
+	value any
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func typeSchema(t reflect.Type, description string) orderedObject {
+	var schema orderedObject
+	if description != "" {
+		schema = append(schema, orderedField{"description", description})
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return append(schema, orderedField{"type", "string"}, orderedField{"format", "date-time"})
+	case t == reflect.TypeFor[Severity]():
+		return append(schema, orderedField{"type", "string"}, orderedField{"enum", Severities})
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return append(schema, orderedField{"type", "string"})
+	case reflect.Map:
+		return append(schema, orderedField{"type", "object"})
+	case reflect.Slice:
+		return append(schema, orderedField{"type", "array"}, orderedField{"items", typeSchema(t.Elem(), "")})
+	case reflect.Struct:
+		schema = append(schema, orderedField{"type", "object"})
+		if _, ok := t.FieldByName("AdditionalProperties"); ok {
+			schema = append(schema, orderedField{"additionalProperties", true})
+		}
+		var properties orderedObject
+		var required []string
+		for _, field := range schemaFields(t) {
+			if field.Tag.Get("schema") == "-" {
+				continue
+			}
+			name, optional := jsonFieldName(field)
+			property := typeSchema(field.Type, field.Tag.Get("description"))
+			if format := field.Tag.Get("format"); format != "" {
+				property = append(property, orderedField{"format", format})
+			}
+			if field.Tag.Get("nullable") == "true" || field.Type.Kind() == reflect.Pointer {
+				property = append(property, orderedField{"nullable", true})
+			}
+			properties = append(properties, orderedField{name, property})
+			if !optional {
+				required = append(required, name)
+			}
+		}
+		schema = append(schema, orderedField{"properties", properties})
+		if len(required) > 0 {
+			schema = append(schema, orderedField{"required", required})
+		}
+		return schema
+	}
+	return schema
+}
+
+// schemaFields returns the fields of the struct which are (un)marshalled
+func schemaFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if tag := field.Tag.Get("json"); tag == "" || tag == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func jsonFieldName(field reflect.StructField) (name string, optional bool) {
+	name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name, options == "omitempty"
+}
+
+// findingFieldNames returns the json names of all known fields of a finding
+func findingFieldNames() []string {
+	var names []string
+	for _, field := range schemaFields(reflect.TypeFor[Finding]()) {
+		name, _ := jsonFieldName(field)
+		names = append(names, name)
+	}
+	return names
+}
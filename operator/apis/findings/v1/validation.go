@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// maxValidationErrors limits the number of problems reported for invalid findings
+const maxValidationErrors = 10
+
+var uuidPattern = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ParseAndValidate decodes a findings json file and validates the findings against the finding format.
+// Returns an error describing the problems if the file isn't a valid array of findings.
+func ParseAndValidate(data []byte) ([]Finding, error) {
+	return DecodeAndValidate(bytes.NewReader(data))
+}
+
+// DecodeAndValidate works like ParseAndValidate, but decodes the findings one by one while reading them,
+// so that large findings files don't have to be held in memory as a whole.
+func DecodeAndValidate(reader io.Reader) ([]Finding, error) {
+	decoder := json.NewDecoder(reader)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("findings must be a json array of objects: %w", err)
+	}
+	if token == nil {
+		return nil, errors.New("findings must be a json array of objects, got null")
+	}
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("findings must be a json array of objects, got '%v'", token)
+	}
+
+	findings := []Finding{}
+	var errs []error
+	for i := 0; decoder.More(); i++ {
+		var finding Finding
+		if err := decoder.Decode(&finding); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, fmt.Errorf("findings must be a json array of objects: %w", err)
+			}
+			// the decoder skips values which don't match the finding format, the following findings can still be checked
+			errs = append(errs, fmt.Errorf("finding %d: %w", i, err))
+		} else {
+			for _, err := range finding.validate() {
+				errs = append(errs, fmt.Errorf("finding %d: %w", i, err))
+			}
+			findings = append(findings, finding)
+		}
+		if len(errs) >= maxValidationErrors {
+			return nil, joinValidationErrors(errs)
+		}
+	}
+	if err := joinValidationErrors(errs); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("findings must be a json array of objects: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("findings must be a json array of objects, got unexpected data after the array")
+	}
+	return findings, nil
+}
+
+// Validate checks that the findings comply with the finding format.
+// The severity is matched case-insensitive, as the operator normalizes it when computing the finding stats.
+func Validate(findings []Finding) error {
+	var errs []error
+	for i, finding := range findings {
+		for _, err := range finding.validate() {
+			errs = append(errs, fmt.Errorf("finding %d: %w", i, err))
+		}
+		if len(errs) >= maxValidationErrors {
+			break
+		}
+	}
+	return joinValidationErrors(errs)
+}
+
+// ValidateParsed works like Validate, but also rejects fields which are only set by the operator, e.g. the suppression.
+// Used for the findings written by parsers, which must not suppress their own findings.
+func ValidateParsed(findings []Finding) error {
+	var errs []error
+	for i, finding := range findings {
+		for _, err := range finding.validate() {
+			errs = append(errs, fmt.Errorf("finding %d: %w", i, err))
+		}
+		if finding.Suppression != nil {
+			errs = append(errs, fmt.Errorf("finding %d: field 'suppression' is set by the operator and must not be set by parsers", i))
+		}
+		if len(errs) >= maxValidationErrors {
+			break
+		}
+	}
+	return joinValidationErrors(errs)
+}
+
+func joinValidationErrors(errs []error) error {
+	if len(errs) > maxValidationErrors {
+		errs = append(errs[:maxValidationErrors], errors.New("further problems omitted"))
+	}
+	return errors.Join(errs...)
+}
+
+func (f Finding) validate() []error {
+	var errs []error
+	if !uuidPattern.MatchString(f.ID) {
+		errs = append(errs, fmt.Errorf("field 'id' must be a uuid, got '%s'", f.ID))
+	}
+	if f.ParsedAt.IsZero() {
+		errs = append(errs, errors.New("required field 'parsed_at' is missing"))
+	}
+	if f.Name == "" {
+		errs = append(errs, errors.New("required field 'name' is missing"))
+	}
+	if f.Category == "" {
+		errs = append(errs, errors.New("required field 'category' is missing"))
+	}
+	if !f.Severity.IsValid() {
+		errs = append(errs, fmt.Errorf("field 'severity' must be one of INFORMATIONAL, LOW, MEDIUM or HIGH, got '%s'", f.Severity))
+	}
+	for i, reference := range f.References {
+		if reference.Type == "" || reference.Value == "" {
+			errs = append(errs, fmt.Errorf("field 'references[%d]' must have a 'type' and a 'value'", i))
+		}
+	}
+
+	if f.Scan.CreatedAt.IsZero() {
+		errs = append(errs, errors.New("required field 'scan.created_at' is missing"))
+	}
+	if f.Scan.Name == "" {
+		errs = append(errs, errors.New("required field 'scan.name' is missing"))
+	}
+	if f.Scan.Namespace == "" {
+		errs = append(errs, errors.New("required field 'scan.namespace' is missing"))
+	}
+	if f.Scan.ScanType == "" {
+		errs = append(errs, errors.New("required field 'scan.scan_type' is missing"))
+	}
+	return errs
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseAndValidate", func() {
+	It("should return the findings of a valid findings file", func() {
+		findings, err := ParseAndValidate([]byte("[" + validFinding + "]"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Attributes).To(HaveKeyWithValue("port", float64(22)))
+	})
+
+	It("should accept an empty array", func() {
+		findings, err := ParseAndValidate([]byte("[]"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("should reject files which aren't an array of findings", func() {
+		_, err := ParseAndValidate([]byte("null"))
+		Expect(err).To(MatchError("findings must be a json array of objects, got null"))
+
+		_, err = ParseAndValidate([]byte(`{"name": "Open Port: 22"}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("findings must be a json array of objects"))
+	})
+
+	It("should report all problems of invalid findings", func() {
+		_, err := ParseAndValidate([]byte(`[{"id": "1", "parsed_at": "2026-10-19T08:00:00Z", "category": "Open Port", "severity": "CRITICAL", "references": [{"type": "CVE"}]}]`))
+		Expect(err).To(MatchError(strings.Join([]string{
+			"finding 0: field 'id' must be a uuid, got '1'",
+			"finding 0: required field 'name' is missing",
+			"finding 0: field 'severity' must be one of INFORMATIONAL, LOW, MEDIUM or HIGH, got 'CRITICAL'",
+			"finding 0: field 'references[0]' must have a 'type' and a 'value'",
+			"finding 0: required field 'scan.created_at' is missing",
+			"finding 0: required field 'scan.name' is missing",
+			"finding 0: required field 'scan.namespace' is missing",
+			"finding 0: required field 'scan.scan_type' is missing",
+		}, "\n")))
+	})
+
+	It("should report fields with the wrong type", func() {
+		_, err := ParseAndValidate([]byte(`[{"name": 42}]`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("finding 0: json: cannot unmarshal number"))
+	})
+
+	It("should keep checking the following findings after a field with the wrong type", func() {
+		_, err := ParseAndValidate([]byte(`[{"name": 42}, {"id": "2"}]`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("finding 1: field 'id' must be a uuid, got '2'"))
+	})
+
+	It("should reject truncated files and data after the array", func() {
+		_, err := ParseAndValidate([]byte("[" + validFinding))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("findings must be a json array of objects"))
+
+		_, err = ParseAndValidate([]byte("[" + validFinding + "] []"))
+		Expect(err).To(MatchError("findings must be a json array of objects, got unexpected data after the array"))
+	})
+
+	It("should accept null for optional fields", func() {
+		findings, err := ParseAndValidate([]byte(`[{
+			"id": "e18cdc5e-6b49-4346-b623-28a4e878e154", "name": "Open Port: 22", "category": "Open Port", "severity": "LOW", "parsed_at": "2026-10-19T08:00:00Z",
+			"scan": {"created_at": "2026-10-19T07:55:00Z", "name": "nmap", "namespace": "default", "scan_type": "nmap"},
+			"description": null, "location": null, "identified_at": null, "references": null
+		}]`))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(findings[0].IdentifiedAt).To(BeNil())
+	})
+
+	It("should limit the number of reported problems", func() {
+		findings := make([]string, 20)
+		for i := range findings {
+			findings[i] = fmt.Sprintf(`{"id": "%d"}`, i)
+		}
+		_, err := ParseAndValidate([]byte("[" + strings.Join(findings, ",") + "]"))
+		Expect(err).To(HaveOccurred())
+		lines := strings.Split(err.Error(), "\n")
+		Expect(lines).To(HaveLen(maxValidationErrors + 1))
+		Expect(lines[maxValidationErrors]).To(Equal("further problems omitted"))
+	})
+})
+
+var _ = Describe("ValidateParsed", func() {
+	It("should reject findings suppressed by the parser", func() {
+		findings, err := ParseAndValidate([]byte("[" + validFinding + "]"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ValidateParsed(findings)).To(Succeed())
+
+		findings[0].Suppression = &Suppression{Name: "accepted", Owner: "parser", Justification: "hidden"}
+		Expect(Validate(findings)).To(Succeed())
+		Expect(ValidateParsed(findings)).To(MatchError("finding 0: field 'suppression' is set by the operator and must not be set by parsers"))
+	})
+})
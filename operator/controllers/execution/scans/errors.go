@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+)
+
+// Reasons of ConfigurationErrors, also used as reason of the events emitted for them
+const (
+	ReasonS3ConnectionFailed = "S3ConnectionFailed"
+	ReasonInvalidURLTemplate = "InvalidURLTemplate"
+)
+
+// ConfigurationError is returned when a scan can't be processed because the operator itself is misconfigured.
+// Retrying won't help, so scans hitting a ConfigurationError are marked as Errored and the readiness check of the operator fails until the config is fixed.
+type ConfigurationError struct {
+	Reason string
+	Err    error
+}
+
+func (e *ConfigurationError) Error() string {
+	return fmt.Sprintf("operator misconfigured (%s): %s", e.Reason, e.Err)
+}
+
+func (e *ConfigurationError) Unwrap() error {
+	return e.Err
+}
+
+// handleConfigurationError marks the scan as Errored, emits an event for it and remembers the error for the readiness check.
+func (r *ScanReconciler) handleConfigurationError(ctx context.Context, scan *executionv1.Scan, configErr *ConfigurationError) error {
+	r.Log.Error(configErr, "Scan failed because of an operator misconfiguration", "scan", scan.Name, "namespace", scan.Namespace)
+	r.setConfigurationError(configErr)
+	r.Recorder.Event(scan, "Warning", configErr.Reason, configErr.Error())
+
+	if !scan.ObjectMeta.DeletionTimestamp.IsZero() || scan.Status.State == executionv1.ScanStateDone || scan.Status.State == executionv1.ScanStateErrored {
+		return nil
+	}
+	scan.Status.State = executionv1.ScanStateErrored
+	scan.Status.ErrorDescription = configErr.Error()
+	return r.updateScanStatus(ctx, scan)
+}
+
+// recheckConfiguration clears the ConfigurationError remembered for the readiness check once the url template of the s3 files can be rendered for a scan again.
+// The readiness check thereby recovers as soon as scans can be processed again, not only when the config file changes.
+func (r *ScanReconciler) recheckConfiguration(scan *executionv1.Scan) {
+	if _, err := getPresignedUrlPath(r.getConfig().S3.URLTemplate, *scan, findingsFile); err != nil {
+		return
+	}
+	r.setConfigurationError(nil)
+}
+
+func (r *ScanReconciler) setConfigurationError(err error) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+	r.configurationError = err
+}
+
+// ReadinessCheck fails while the operator is misconfigured, e.g. when the s3 client couldn't be created or the last scan failed with a ConfigurationError.
+func (r *ScanReconciler) ReadinessCheck(_ *http.Request) error {
+	r.configMutex.RLock()
+	defer r.configMutex.RUnlock()
+	if r.s3Error != nil {
+		return r.s3Error
+	}
+	return r.configurationError
+}
+
+// HookFailureError is returned when a hook itself can't be executed, e.g. because its ScanCompletionHook was deleted or results in an invalid job.
+// Unlike transient errors of the kubernetes api or the s3 storage, these count as failures of the hook and are handled by its failure policy.
+type HookFailureError struct {
+	Err error
+}
+
+func (e *HookFailureError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *HookFailureError) Unwrap() error {
+	return e.Err
+}
+
+// asHookFailureError returns the HookFailureError wrapped in err, or nil if there is none.
+func asHookFailureError(err error) *HookFailureError {
+	var hookErr *HookFailureError
+	if errors.As(err, &hookErr) {
+		return hookErr
+	}
+	return nil
+}
+
+// asConfigurationError returns the ConfigurationError wrapped in err, or nil if there is none.
+func asConfigurationError(err error) *ConfigurationError {
+	var configErr *ConfigurationError
+	if errors.As(err, &configErr) {
+		return configErr
+	}
+	return nil
+}
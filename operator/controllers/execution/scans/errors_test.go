@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ConfigurationErrors", func() {
+	Context("executeUrlTemplate", func() {
+		scan := executionv1.Scan{ObjectMeta: metav1.ObjectMeta{UID: "8b0a9c5e"}}
+
+		It("should render the template", func() {
+			url, err := executeUrlTemplate("scan-{{ .Scan.UID }}/{{ .Filename }}", scan, "findings.json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url).To(Equal("scan-8b0a9c5e/findings.json"))
+		})
+
+		It("should return a ConfigurationError for templates which can't be parsed", func() {
+			_, err := executeUrlTemplate("scan-{{ .Scan.UID", scan, "findings.json")
+			Expect(asConfigurationError(err)).NotTo(BeNil())
+			Expect(asConfigurationError(err).Reason).To(Equal(ReasonInvalidURLTemplate))
+		})
+
+		It("should return a ConfigurationError for templates which can't be executed", func() {
+			_, err := executeUrlTemplate("{{ .Scan.DoesNotExist }}/{{ .Filename }}", scan, "findings.json")
+			Expect(asConfigurationError(err)).NotTo(BeNil())
+		})
+	})
+
+	Context("handleConfigurationError", func() {
+		var (
+			r        *ScanReconciler
+			recorder *record.FakeRecorder
+			scan     *executionv1.Scan
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+			scan = &executionv1.Scan{
+				ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default"},
+				Status:     executionv1.ScanStatus{State: executionv1.ScanStateScanCompleted},
+			}
+			recorder = record.NewFakeRecorder(10)
+			r = &ScanReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(scan).WithStatusSubresource(scan).Build(),
+				Log:      logr.Discard(),
+				Recorder: recorder,
+				Config:   config.Default(),
+			}
+		})
+
+		It("should mark the scan as errored, emit an event and fail the readiness check", func() {
+			configErr := &ConfigurationError{Reason: ReasonInvalidURLTemplate, Err: errors.New("bad template")}
+			Expect(r.ReadinessCheck(nil)).To(Succeed())
+
+			Expect(r.handleConfigurationError(context.Background(), scan, configErr)).To(Succeed())
+
+			var updated executionv1.Scan
+			Expect(r.Get(context.Background(), types.NamespacedName{Name: "nmap", Namespace: "default"}, &updated)).To(Succeed())
+			Expect(updated.Status.State).To(Equal(executionv1.ScanStateErrored))
+			Expect(updated.Status.ErrorDescription).To(ContainSubstring("bad template"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning InvalidURLTemplate")))
+			Expect(r.ReadinessCheck(nil)).NotTo(Succeed())
+
+			r.UpdateConfig(config.Default())
+			Expect(r.ReadinessCheck(nil)).To(Succeed())
+		})
+
+		It("should recover the readiness check once scans can be processed again", func() {
+			configErr := &ConfigurationError{Reason: ReasonInvalidURLTemplate, Err: errors.New("bad template")}
+			Expect(r.handleConfigurationError(context.Background(), scan, configErr)).To(Succeed())
+			Expect(r.ReadinessCheck(nil)).NotTo(Succeed())
+
+			r.Config.S3.URLTemplate = "{{ .Scan.DoesNotExist }}/{{ .Filename }}"
+			r.recheckConfiguration(scan)
+			Expect(r.ReadinessCheck(nil)).NotTo(Succeed())
+
+			r.Config.S3.URLTemplate = config.Default().S3.URLTemplate
+			r.recheckConfiguration(scan)
+			Expect(r.ReadinessCheck(nil)).To(Succeed())
+		})
+
+		It("should remove the finalizer of deleted scans whose files can't be cleaned up", func() {
+			now := metav1.Now()
+			deleted := &executionv1.Scan{
+				ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "default", DeletionTimestamp: &now, Finalizers: []string{s3StorageFinalizer}},
+				Status:     executionv1.ScanStatus{State: executionv1.ScanStateDone},
+			}
+			r.Client = fake.NewClientBuilder().WithScheme(r.Client.Scheme()).WithObjects(deleted).Build()
+			r.s3Error = &ConfigurationError{Reason: ReasonS3ConnectionFailed, Err: errors.New("no s3")}
+
+			Expect(r.handleFinalizer(deleted)).To(Succeed())
+
+			err := r.Get(context.Background(), types.NamespacedName{Name: "deleted", Namespace: "default"}, &executionv1.Scan{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning S3ConnectionFailed")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Warning FileCleanupSkipped")))
+		})
+
+		It("should not change the state of finished scans", func() {
+			scan.Status.State = executionv1.ScanStateDone
+			configErr := &ConfigurationError{Reason: ReasonS3ConnectionFailed, Err: errors.New("no s3")}
+
+			Expect(r.handleConfigurationError(context.Background(), scan, configErr)).To(Succeed())
+			Expect(scan.Status.State).To(Equal(executionv1.ScanStateDone))
+		})
+	})
+})
@@ -0,0 +1,229 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	batch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Scan Events", func() {
+	var (
+		r        *ScanReconciler
+		recorder *record.FakeRecorder
+		scan     *executionv1.Scan
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+		Expect(batch.AddToScheme(scheme)).To(Succeed())
+		var ttl int32 = 30
+		scan = &executionv1.Scan{
+			ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default"},
+			Spec:       executionv1.ScanSpec{TTLSecondsAfterFinished: &ttl},
+			Status:     executionv1.ScanStatus{State: executionv1.ScanStateHookProcessing},
+		}
+		isController := true
+		failedJob := &batch.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cleanup-nmap-abc",
+				Namespace: "default",
+				Labels: map[string]string{
+					"securecodebox.io/job-type":  "read-only-hook",
+					"securecodebox.io/hook-name": "cleanup",
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: apiGVStr, Kind: "Scan", Name: scan.Name, Controller: &isController},
+				},
+			},
+			Status: batch.JobStatus{
+				Failed:     1,
+				Conditions: []batch.JobCondition{{Reason: "BackoffLimitExceeded"}},
+			},
+		}
+
+		recorder = record.NewFakeRecorder(10)
+		r = &ScanReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(scan, failedJob).
+				WithStatusSubresource(scan).
+				WithIndex(&batch.Job{}, ownerKey, func(obj client.Object) []string {
+					owner := metav1.GetControllerOf(obj)
+					if owner == nil {
+						return nil
+					}
+					return []string{owner.Name}
+				}).
+				Build(),
+			Log:      logr.Discard(),
+			Recorder: recorder,
+			Config:   config.Default(),
+		}
+	})
+
+	It("should emit an event when all hooks are done", func() {
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{
+			{{HookName: "cleanup", JobName: "cleanup-nmap-abc", State: executionv1.Completed}},
+		}
+
+		Expect(r.executeHooks(scan)).To(Succeed())
+		Expect(scan.Status.State).To(Equal(executionv1.ScanStateDone))
+		Expect(recorder.Events).To(Receive(Equal("Normal Done All hooks completed, Scan is done")))
+	})
+
+	It("should emit a warning event with the failed hook and its job", func() {
+		status := &executionv1.HookStatus{HookName: "cleanup", JobName: "cleanup-nmap-abc", State: executionv1.InProgress}
+		Expect(r.processInProgressHook(scan, status, "read-only-hook")).To(Succeed())
+		Expect(status.State).To(Equal(executionv1.Failed))
+		Expect(recorder.Events).To(Receive(Equal("Warning HookJobFailed Hook cleanup failed in job cleanup-nmap-abc. Check the logs of the hook for more information.")))
+	})
+
+	It("should mark the scan as done with the failed hooks which were ignored", func() {
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{
+			{{HookName: "defectdojo", JobName: "defectdojo-nmap-abc", State: executionv1.Completed}},
+			{{HookName: "slack", JobName: "slack-nmap-abc", State: executionv1.Failed, FailurePolicy: &executionv1.HookFailurePolicy{Type: executionv1.HookFailurePolicyIgnore}}},
+		}
+
+		Expect(r.executeHooks(scan)).To(Succeed())
+		Expect(scan.Status.State).To(Equal(executionv1.ScanStateDone))
+		Expect(scan.Status.FailedHooks).To(Equal([]string{"slack"}))
+		Expect(recorder.Events).To(Receive(Equal("Warning DoneWithFailedHooks Scan is done, but the following hooks failed and were ignored: slack")))
+	})
+
+	It("should not use up the retries of a hook for transient errors", func() {
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*batch.JobList); ok {
+					return errors.New("connection refused")
+				}
+				return c.List(ctx, list, opts...)
+			},
+		})
+		Expect(r.Create(context.Background(), &executionv1.ScanCompletionHook{
+			ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "default"},
+			Spec:       executionv1.ScanCompletionHookSpec{Type: executionv1.ReadOnly},
+		})).To(Succeed())
+		status := &executionv1.HookStatus{HookName: "slack", State: executionv1.Pending, Type: executionv1.ReadOnly, FailurePolicy: &executionv1.HookFailurePolicy{Type: executionv1.HookFailurePolicyRetry}}
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{{status}}
+
+		Expect(r.executeHooks(scan)).To(MatchError("connection refused"))
+		Expect(status.State).To(Equal(executionv1.Pending))
+		Expect(status.Retries).To(BeZero())
+		Expect(scan.Status.State).To(Equal(executionv1.ScanStateHookProcessing))
+	})
+
+	It("should apply the failure policy if the hook can't be executed", func() {
+		status := &executionv1.HookStatus{HookName: "deleted", State: executionv1.Pending, Type: executionv1.ReadOnly, FailurePolicy: &executionv1.HookFailurePolicy{Type: executionv1.HookFailurePolicyIgnore}}
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{{status}}
+
+		Expect(r.executeHooks(scan)).To(Succeed())
+		Expect(status.State).To(Equal(executionv1.Cancelled))
+		Expect(scan.Status.State).To(Equal(executionv1.ScanStateHookProcessing))
+	})
+
+	It("should skip hooks whose when condition doesn't match the scan", func() {
+		scan.Status.State = executionv1.ScanStateParseCompleted
+		scan.Status.Findings = executionv1.FindingStats{Count: 0}
+		Expect(r.Create(context.Background(), &executionv1.ScanCompletionHook{
+			ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "default"},
+			Spec: executionv1.ScanCompletionHookSpec{
+				Type: executionv1.ReadOnly,
+				When: &executionv1.HookCondition{Findings: []string{"findings.count > 0"}},
+			},
+		})).To(Succeed())
+
+		Expect(r.setHookStatus(scan)).To(Succeed())
+		Expect(scan.Status.OrderedHookStatuses).To(HaveLen(1))
+		Expect(scan.Status.OrderedHookStatuses[0][0].State).To(Equal(executionv1.Skipped))
+		Expect(recorder.Events).To(Receive(Equal("Normal HookSkipped Skipped hook slack as the scan doesn't match its when condition")))
+	})
+
+	It("should reset the requested hooks and add new hooks when hooks are rerun", func() {
+		scan.Status.State = executionv1.ScanStateDone
+		scan.Status.FailedHooks = []string{"slack"}
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{
+			{{HookName: "defectdojo", JobName: "defectdojo-nmap-abc", State: executionv1.Completed, Type: executionv1.ReadOnly}},
+			{{HookName: "slack", JobName: "slack-nmap-abc", State: executionv1.Failed, Type: executionv1.ReadOnly, Retries: 2}},
+		}
+		Expect(r.Status().Update(context.Background(), scan)).To(Succeed())
+		scan.ObjectMeta.Annotations = map[string]string{RerunHooksAnnotation: "slack, persistence-elastic"}
+		Expect(r.Create(context.Background(), &executionv1.ScanCompletionHook{
+			ObjectMeta: metav1.ObjectMeta{Name: "persistence-elastic", Namespace: "default"},
+			Spec:       executionv1.ScanCompletionHookSpec{Type: executionv1.ReadOnly},
+		})).To(Succeed())
+
+		Expect(shouldRerunHooks(scan)).To(BeTrue())
+		Expect(r.rerunHooks(scan)).To(Succeed())
+
+		Expect(scan.ObjectMeta.Annotations).NotTo(HaveKey(RerunHooksAnnotation))
+		Expect(scan.Status.State).To(Equal(executionv1.ScanStateHookProcessing))
+		Expect(scan.Status.FailedHooks).To(BeEmpty())
+		Expect(scan.Status.OrderedHookStatuses).To(HaveLen(1))
+		Expect(scan.Status.OrderedHookStatuses[0]).To(ConsistOf(
+			&executionv1.HookStatus{HookName: "defectdojo", JobName: "defectdojo-nmap-abc", State: executionv1.Completed, Type: executionv1.ReadOnly},
+			&executionv1.HookStatus{HookName: "slack", State: executionv1.Pending, Type: executionv1.ReadOnly, Reruns: 1},
+			&executionv1.HookStatus{HookName: "persistence-elastic", State: executionv1.Pending, Type: executionv1.ReadOnly, Reruns: 1},
+		))
+		Expect(recorder.Events).To(Receive(Equal("Normal RerunHooks Rerunning hooks slack, persistence-elastic")))
+	})
+
+	It("should keep the rerun request if removing the annotation fails and not rerun the hooks twice", func() {
+		failUpdates := true
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if failUpdates {
+					return errors.New("conflict")
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		})
+		scan.Status.State = executionv1.ScanStateDone
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{
+			{{HookName: "slack", JobName: "slack-nmap-abc", State: executionv1.Completed, Type: executionv1.ReadOnly}},
+		}
+		Expect(r.Status().Update(context.Background(), scan)).To(Succeed())
+		scan.ObjectMeta.Annotations = map[string]string{RerunHooksAnnotation: "slack"}
+
+		Expect(r.rerunHooks(scan)).NotTo(Succeed())
+
+		var persisted executionv1.Scan
+		Expect(r.Get(context.Background(), client.ObjectKeyFromObject(scan), &persisted)).To(Succeed())
+		Expect(persisted.Status.State).To(Equal(executionv1.ScanStateHookProcessing))
+		Expect(persisted.Status.RerunHooksRequest).To(Equal("slack"))
+		Expect(persisted.Status.OrderedHookStatuses[0][0].Reruns).To(Equal(int32(1)))
+
+		persisted.ObjectMeta.Annotations = map[string]string{RerunHooksAnnotation: "slack"}
+		Expect(shouldRerunHooks(&persisted)).To(BeFalse())
+		Expect(rerunHooksStarted(&persisted)).To(BeTrue())
+		failUpdates = false
+		Expect(r.removeRerunHooksAnnotation(&persisted)).To(Succeed())
+		Expect(persisted.ObjectMeta.Annotations).NotTo(HaveKey(RerunHooksAnnotation))
+		Expect(persisted.Status.OrderedHookStatuses[0][0].Reruns).To(Equal(int32(1)))
+	})
+
+	It("should emit an event when the scan gets deleted after its ttl", func() {
+		Expect(r.deleteScan(scan)).To(Succeed())
+		Expect(recorder.Events).To(Receive(Equal("Normal Deleted Deleted Scan as ttlSecondsAfterFinished of 30s elapsed")))
+	})
+})
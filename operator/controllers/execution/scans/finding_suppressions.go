@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+)
+
+// +kubebuilder:rbac:groups=execution.securecodebox.io,resources=findingsuppressions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=execution.securecodebox.io,resources=findingsuppressions/status,verbs=get;update;patch
+
+// applyFindingSuppressions marks the findings matched by the FindingSuppressions in the namespace of the scan as suppressed.
+// If findings were suppressed, the marked findings are written to the findings.suppressed.json file, which becomes the current findings of the scan, and the status of the FindingSuppressions is updated.
+// The findings.json file of the parser is kept for audit.
+func (r *ScanReconciler) applyFindingSuppressions(ctx context.Context, scan *executionv1.Scan, findings []findingsv1.Finding) error {
+	var suppressions executionv1.FindingSuppressionList
+	if err := r.List(ctx, &suppressions, client.InNamespace(scan.Namespace)); err != nil {
+		return fmt.Errorf("failed to list the FindingSuppressions: %w", err)
+	}
+
+	suppressed := suppressFindings(suppressions.Items, scan, findings, time.Now())
+	scan.Status.SuppressedFindings = countSuppressedFindings(findings)
+	if len(scan.Status.SuppressedFindings) == 0 {
+		return nil
+	}
+
+	if err := r.writeSuppressedFindings(scan, findings); err != nil {
+		return fmt.Errorf("failed to write the suppressed findings to %s: %w", suppressedFindingsFile, err)
+	}
+	if err := r.setCurrentFindingsFile(scan, suppressedFindingsFile); err != nil {
+		return err
+	}
+
+	for _, stats := range scan.Status.SuppressedFindings {
+		count, ok := suppressed[stats.Name]
+		if !ok {
+			continue
+		}
+		r.Recorder.Eventf(scan, "Normal", "FindingsSuppressed", "Suppressed %d findings using FindingSuppression %s", count, stats.Name)
+		if err := r.updateFindingSuppressionStatus(ctx, scan, stats.Name, count); err != nil {
+			// the status is informational, the findings are suppressed regardless
+			r.Log.Error(err, "Failed to update the status of the FindingSuppression", "findingSuppression", stats.Name, "namespace", scan.Namespace)
+		}
+	}
+	return nil
+}
+
+// writeSuppressedFindings adds the suppressions of the findings to the findings of the parser and uploads them as findings.suppressed.json.
+// The findings of the parser are written again as they were decoded, so that fields which aren't part of the finding format and large integers are kept unchanged.
+func (r *ScanReconciler) writeSuppressedFindings(scan *executionv1.Scan, findings []findingsv1.Finding) error {
+	rawFindings, err := r.getRawFindings(scan, findingsFile)
+	if err != nil {
+		return err
+	}
+	if len(rawFindings) != len(findings) {
+		return fmt.Errorf("%s changed while the suppressions were applied, expected %d findings but got %d", findingsFile, len(findings), len(rawFindings))
+	}
+	for i, finding := range findings {
+		if finding.Suppression != nil {
+			rawFindings[i]["suppression"] = finding.Suppression
+		}
+	}
+
+	data, err := json.Marshal(rawFindings)
+	if err != nil {
+		return err
+	}
+	return r.putScanFile(scan, suppressedFindingsFile, data)
+}
+
+// suppressFindings sets the suppression of the findings matched by the FindingSuppressions which apply to the scan. Findings which are already suppressed are kept as they are.
+// Returns the number of newly suppressed findings by the name of the FindingSuppression.
+func suppressFindings(suppressions []executionv1.FindingSuppression, scan *executionv1.Scan, findings []findingsv1.Finding, now time.Time) map[string]uint64 {
+	suppressed := map[string]uint64{}
+	for _, suppression := range suppressions {
+		if suppression.IsExpired(now) || !suppressionAppliesToScan(suppression, scan) {
+			continue
+		}
+		matchesFinding := suppression.Spec.Matches.Compile()
+		for i := range findings {
+			if findings[i].Suppression != nil || !matchesFinding(findings[i]) {
+				continue
+			}
+			findings[i].Suppression = &findingsv1.Suppression{
+				Name:          suppression.Name,
+				Owner:         suppression.Spec.Owner,
+				Justification: suppression.Spec.Justification,
+				SuppressedAt:  now.UTC(),
+			}
+			if suppression.Spec.ExpiresAt != nil {
+				expiresAt := suppression.Spec.ExpiresAt.UTC()
+				findings[i].Suppression.ExpiresAt = &expiresAt
+			}
+			suppressed[suppression.Name]++
+		}
+	}
+	return suppressed
+}
+
+// suppressionAppliesToScan checks if the scanSelector of the FindingSuppression matches the labels of the scan
+func suppressionAppliesToScan(suppression executionv1.FindingSuppression, scan *executionv1.Scan) bool {
+	if suppression.Spec.ScanSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(suppression.Spec.ScanSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(scan.ObjectMeta.Labels))
+}
+
+// countSuppressedFindings counts the suppressed findings by the name of the FindingSuppression
+func countSuppressedFindings(findings []findingsv1.Finding) []executionv1.SuppressedFindingsStats {
+	counts := map[string]uint64{}
+	for _, finding := range findings {
+		if finding.Suppression != nil {
+			counts[finding.Suppression.Name]++
+		}
+	}
+
+	var stats []executionv1.SuppressedFindingsStats
+	for name, count := range counts {
+		stats = append(stats, executionv1.SuppressedFindingsStats{Name: name, Count: count})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// unsuppressedFindings returns the findings which aren't suppressed
+func unsuppressedFindings(findings []findingsv1.Finding) []findingsv1.Finding {
+	var result []findingsv1.Finding
+	for _, finding := range findings {
+		if finding.Suppression == nil {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// updateFindingSuppressionStatus records the suppressed findings of the scan in the status of the FindingSuppression.
+// The suppressions are applied again if updating the status of the scan fails afterwards, the findings of a scan which is already the lastScan of the FindingSuppression aren't counted again.
+func (r *ScanReconciler) updateFindingSuppressionStatus(ctx context.Context, scan *executionv1.Scan, name string, count uint64) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var suppression executionv1.FindingSuppression
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: scan.Namespace}, &suppression); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if suppression.Status.LastScan == scan.Name {
+			return nil
+		}
+		now := metav1.Now()
+		suppression.Status.SuppressedFindings += count
+		suppression.Status.LastScan = scan.Name
+		suppression.Status.LastAppliedTime = &now
+		return r.Status().Update(ctx, &suppression)
+	})
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("FindingSuppressions", func() {
+	var (
+		scan     *executionv1.Scan
+		findings []findingsv1.Finding
+		now      time.Time
+	)
+
+	newSuppression := func(name string, rules ...executionv1.FindingSuppressionRule) executionv1.FindingSuppression {
+		return executionv1.FindingSuppression{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: executionv1.FindingSuppressionSpec{
+				Matches:       executionv1.FindingSuppressionMatches{AnyOf: rules},
+				Owner:         "team-security",
+				Justification: "Accepted risk",
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2026, time.March, 20, 12, 0, 0, 0, time.UTC)
+		scan = &executionv1.Scan{ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default", Labels: map[string]string{"team": "platform"}}}
+		findings = []findingsv1.Finding{
+			{Name: "Open Port 22", Category: "Open Port", Location: "tcp://10.0.0.1:22", Severity: findingsv1.SeverityLow, Attributes: map[string]any{"port": float64(22)}},
+			{Name: "Open Port 443", Category: "Open Port", Location: "tcp://10.0.0.1:443", Severity: findingsv1.SeverityLow, Attributes: map[string]any{"port": float64(443)}},
+			{Name: "Outdated TLS", Category: "TLS", Location: "10.0.0.2:443", Severity: findingsv1.SeverityHigh},
+		}
+	})
+
+	It("should mark the findings matched by any rule with an audit trail", func() {
+		suppression := newSuppression("ssh-and-tls",
+			executionv1.FindingSuppressionRule{Category: "Open Port", Attributes: map[string]intstr.IntOrString{"port": intstr.FromInt(22)}},
+			executionv1.FindingSuppressionRule{Name: "Outdated *", Location: "10.0.0.*", Severity: "HIGH"},
+		)
+		expiresAt := metav1.NewTime(now.Add(24 * time.Hour))
+		suppression.Spec.ExpiresAt = &expiresAt
+
+		Expect(suppressFindings([]executionv1.FindingSuppression{suppression}, scan, findings, now)).To(Equal(map[string]uint64{"ssh-and-tls": 2}))
+
+		Expect(findings[0].Suppression).To(Equal(&findingsv1.Suppression{
+			Name:          "ssh-and-tls",
+			Owner:         "team-security",
+			Justification: "Accepted risk",
+			ExpiresAt:     &expiresAt.Time,
+			SuppressedAt:  now,
+		}))
+		Expect(findings[1].Suppression).To(BeNil())
+		Expect(findings[2].Suppression).NotTo(BeNil())
+		Expect(countSuppressedFindings(findings)).To(Equal([]executionv1.SuppressedFindingsStats{{Name: "ssh-and-tls", Count: 2}}))
+	})
+
+	It("should not apply expired suppressions", func() {
+		suppression := newSuppression("open-ports", executionv1.FindingSuppressionRule{Category: "Open Port"})
+		expiresAt := metav1.NewTime(now.Add(-time.Hour))
+		suppression.Spec.ExpiresAt = &expiresAt
+
+		Expect(suppressFindings([]executionv1.FindingSuppression{suppression}, scan, findings, now)).To(BeEmpty())
+		Expect(countSuppressedFindings(findings)).To(BeEmpty())
+	})
+
+	It("should only apply suppressions whose scanSelector matches the scan", func() {
+		other := newSuppression("other-team", executionv1.FindingSuppressionRule{Category: "Open Port"})
+		other.Spec.ScanSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
+		own := newSuppression("own-team", executionv1.FindingSuppressionRule{Category: "TLS"})
+		own.Spec.ScanSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}}
+
+		Expect(suppressFindings([]executionv1.FindingSuppression{other, own}, scan, findings, now)).To(Equal(map[string]uint64{"own-team": 1}))
+	})
+
+	It("should keep existing suppressions of findings", func() {
+		first := newSuppression("first", executionv1.FindingSuppressionRule{Category: "Open Port"})
+		second := newSuppression("second", executionv1.FindingSuppressionRule{Name: "Open Port *"})
+
+		Expect(suppressFindings([]executionv1.FindingSuppression{first, second}, scan, findings, now)).To(Equal(map[string]uint64{"first": 2}))
+		// applying the suppressions again doesn't suppress the findings twice
+		Expect(suppressFindings([]executionv1.FindingSuppression{first, second}, scan, findings, now)).To(BeEmpty())
+		Expect(countSuppressedFindings(findings)).To(Equal([]executionv1.SuppressedFindingsStats{{Name: "first", Count: 2}}))
+	})
+})
@@ -0,0 +1,311 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// findingsFile is the name of the findings written by the parser. ReadAndWrite hooks write new versions of it, the original is kept for audit.
+const findingsFile = "findings.json"
+
+// suppressedFindingsFile is the name of the version of the findings written by the operator if findings of the parser are suppressed by FindingSuppressions
+const suppressedFindingsFile = "findings.suppressed.json"
+
+// versionedFileName returns the name of the version of a file written by a ReadAndWrite hook, e.g. "findings.<hook>.json".
+func versionedFileName(filename string, hookName string) string {
+	ext := path.Ext(filename)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(filename, ext), hookName, ext)
+}
+
+// currentFindingsFile returns the name of the latest valid version of the findings of the scan.
+func currentFindingsFile(scan *executionv1.Scan) string {
+	if scan.Status.CurrentFindingsFile != "" {
+		return scan.Status.CurrentFindingsFile
+	}
+	return parsedFindingsFile(scan)
+}
+
+// parsedFindingsFile returns the name of the findings written by the parser, with the FindingSuppressions applied if findings were suppressed.
+// ReadAndWrite hooks start with this version.
+func parsedFindingsFile(scan *executionv1.Scan) string {
+	if len(scan.Status.SuppressedFindings) > 0 {
+		return suppressedFindingsFile
+	}
+	return findingsFile
+}
+
+// currentRawResultFile returns the name of the latest version of the raw results of the scan.
+func currentRawResultFile(scan *executionv1.Scan) string {
+	if scan.Status.CurrentRawResultFile != "" {
+		return scan.Status.CurrentRawResultFile
+	}
+	return scan.Status.RawResultFile
+}
+
+// computeFindingStats counts the findings by severity and category. The operator computes the stats itself instead of trusting the counts reported by parsers or hooks.
+// Suppressed findings aren't counted, they are listed in the suppressedFindings of the scan status instead.
+func computeFindingStats(findings []findingsv1.Finding) executionv1.FindingStats {
+	findings = unsuppressedFindings(findings)
+	stats := executionv1.FindingStats{
+		Count:             uint64(len(findings)),
+		FindingCategories: map[string]uint64{},
+	}
+	for _, finding := range findings {
+		switch finding.Severity.Normalize() {
+		case findingsv1.SeverityInformational:
+			stats.FindingSeverities.Informational++
+		case findingsv1.SeverityLow:
+			stats.FindingSeverities.Low++
+		case findingsv1.SeverityMedium:
+			stats.FindingSeverities.Medium++
+		case findingsv1.SeverityHigh:
+			stats.FindingSeverities.High++
+		}
+		stats.FindingCategories[finding.Category]++
+	}
+	return stats
+}
+
+// getFindings downloads a version of the findings of the scan from the s3 storage and validates them against the finding format.
+// The findings are decoded while they are downloaded, so that the file doesn't have to be held in memory as a whole.
+// Returns nil findings if the file doesn't exist, and an invalidFindings error if the findings are invalid or larger than the maximum findings size of the operator config.
+func (r *ScanReconciler) getFindings(scan *executionv1.Scan, filename string) (findings []findingsv1.Finding, invalidFindings error, err error) {
+	cfg := r.getConfig()
+	if err := r.checkS3Connection(); err != nil {
+		return nil, nil, err
+	}
+	objectPath, err := getPresignedUrlPath(cfg.S3.URLTemplate, *scan, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := r.MinioClient.GetObject(context.Background(), cfg.S3.Bucket, objectPath, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if info.Size > cfg.Findings.MaxSize.Value() {
+		return nil, fmt.Errorf("%s has a size of %s, which exceeds the maximum findings size of %s (findings.maxSize in the operator config)", filename, resource.NewQuantity(info.Size, resource.BinarySI), cfg.Findings.MaxSize.String()), nil
+	}
+
+	reader := &downloadReader{reader: object}
+	findings, invalidFindings = findingsv1.DecodeAndValidate(reader)
+	if reader.err != nil {
+		// failed downloads must not be mistaken for invalid findings
+		return nil, nil, reader.err
+	}
+	return findings, invalidFindings, nil
+}
+
+// getRawFindings downloads a version of the findings of the scan without decoding them into the finding format.
+// Numbers are decoded as json.Number, so that the findings can be written again without losing the precision of large integers.
+func (r *ScanReconciler) getRawFindings(scan *executionv1.Scan, filename string) ([]map[string]any, error) {
+	cfg := r.getConfig()
+	if err := r.checkS3Connection(); err != nil {
+		return nil, err
+	}
+	objectPath, err := getPresignedUrlPath(cfg.S3.URLTemplate, *scan, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := r.MinioClient.GetObject(context.Background(), cfg.S3.Bucket, objectPath, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	var findings []map[string]any
+	decoder := json.NewDecoder(object)
+	decoder.UseNumber()
+	if err := decoder.Decode(&findings); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
+// downloadReader records errors of the underlying download, to tell them apart from decoding errors.
+type downloadReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (d *downloadReader) Read(p []byte) (int, error) {
+	n, err := d.reader.Read(p)
+	if err != nil && err != io.EOF {
+		d.err = err
+	}
+	return n, err
+}
+
+// scanFileExists checks if a file of the scan exists in the s3 storage.
+func (r *ScanReconciler) scanFileExists(scan *executionv1.Scan, filename string) (bool, error) {
+	s3Config := r.getConfig().S3
+	if err := r.checkS3Connection(); err != nil {
+		return false, err
+	}
+	objectPath, err := getPresignedUrlPath(s3Config.URLTemplate, *scan, filename)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := r.MinioClient.StatObject(context.Background(), s3Config.Bucket, objectPath, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// processReadAndWriteHookOutput picks up the versions of the findings and raw results written by a completed ReadAndWrite hook.
+// New findings are validated and the finding stats of the scan are recomputed from them. Hooks which didn't write a new version keep the previous one.
+// Returns an error describing the problems if the hook wrote invalid findings.
+func (r *ScanReconciler) processReadAndWriteHookOutput(scan *executionv1.Scan, status *executionv1.HookStatus) (invalidFindings error, err error) {
+	rawResultVersion := versionedFileName(scan.Status.RawResultFile, status.HookName)
+	rawResultWritten, err := r.scanFileExists(scan, rawResultVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	findingsVersion := versionedFileName(findingsFile, status.HookName)
+	findings, invalidFindings, err := r.getFindings(scan, findingsVersion)
+	if err != nil {
+		return nil, err
+	}
+	if invalidFindings != nil {
+		return fmt.Errorf("hook wrote invalid findings to %s: %w", findingsVersion, invalidFindings), nil
+	}
+
+	if findings != nil {
+		scan.Status.Findings = computeFindingStats(findings)
+		status.FindingsFile = findingsVersion
+		if err := r.setCurrentFindingsFile(scan, findingsVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	if rawResultWritten {
+		status.RawResultFile = rawResultVersion
+		if err := r.setCurrentRawResultFile(scan, rawResultVersion); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// setCurrentFindingsFile makes the version the current findings of the scan and points the findings links of the scan to it.
+// An empty version resets it to the findings written by the parser, with the FindingSuppressions applied.
+func (r *ScanReconciler) setCurrentFindingsFile(scan *executionv1.Scan, version string) error {
+	scan.Status.CurrentFindingsFile = version
+
+	findingsDownloadURL, err := r.PresignedGetURL(*scan, currentFindingsFile(scan), 7*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	scan.Status.FindingDownloadLink = findingsDownloadURL
+	findingsHeadURL, err := r.PresignedHeadURL(*scan, currentFindingsFile(scan), r.getConfig().URLExpiration.Scan.Duration)
+	if err != nil {
+		return err
+	}
+	scan.Status.FindingHeadLink = findingsHeadURL
+	return nil
+}
+
+// setCurrentRawResultFile makes the version the current raw results of the scan and points the raw result links of the scan to it.
+// An empty version resets it to the raw results written by the scanner.
+func (r *ScanReconciler) setCurrentRawResultFile(scan *executionv1.Scan, version string) error {
+	scan.Status.CurrentRawResultFile = version
+
+	rawResultDownloadURL, err := r.PresignedGetURL(*scan, currentRawResultFile(scan), 7*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	scan.Status.RawResultDownloadLink = rawResultDownloadURL
+	rawResultHeadURL, err := r.PresignedHeadURL(*scan, currentRawResultFile(scan), r.getConfig().URLExpiration.Scan.Duration)
+	if err != nil {
+		return err
+	}
+	scan.Status.RawResultHeadLink = rawResultHeadURL
+	return nil
+}
+
+// resetFilesForRerun resets the current findings and raw results to the versions the first rerun ReadAndWrite hook originally started with.
+// Otherwise the rerun hooks would read the versions they wrote themselves in their previous run and apply their changes twice.
+// The finding stats of the scan are recomputed from the reset findings.
+func (r *ScanReconciler) resetFilesForRerun(scan *executionv1.Scan, rerunHooks []string) error {
+	findingsVersion, rawResultVersion := "", ""
+	if len(scan.Status.SuppressedFindings) > 0 {
+		// the first hooks started with the suppressed version of the findings of the parser
+		findingsVersion = suppressedFindingsFile
+	}
+	rerunsReadAndWriteHooks := false
+	for _, group := range scan.Status.OrderedHookStatuses {
+		for _, status := range group {
+			if status.Type != executionv1.ReadAndWrite {
+				continue
+			}
+			if slices.Contains(rerunHooks, status.HookName) {
+				rerunsReadAndWriteHooks = true
+				status.FindingsFile = ""
+				status.RawResultFile = ""
+			} else if !rerunsReadAndWriteHooks {
+				// versions of hooks running before the first rerun hook stay valid
+				if status.FindingsFile != "" {
+					findingsVersion = status.FindingsFile
+				}
+				if status.RawResultFile != "" {
+					rawResultVersion = status.RawResultFile
+				}
+			}
+		}
+	}
+	if !rerunsReadAndWriteHooks {
+		return nil
+	}
+
+	if err := r.setCurrentRawResultFile(scan, rawResultVersion); err != nil {
+		return err
+	}
+	if findingsVersion == scan.Status.CurrentFindingsFile {
+		return nil
+	}
+
+	scan.Status.CurrentFindingsFile = findingsVersion
+	findings, invalidFindings, err := r.getFindings(scan, currentFindingsFile(scan))
+	if err != nil {
+		return err
+	}
+	if invalidFindings != nil {
+		return fmt.Errorf("can't reset the findings for the rerun as %s is invalid: %w", currentFindingsFile(scan), invalidFindings)
+	}
+	if findings == nil {
+		return fmt.Errorf("can't reset the findings for the rerun as %s doesn't exist", currentFindingsFile(scan))
+	}
+	scan.Status.Findings = computeFindingStats(findings)
+	return r.setCurrentFindingsFile(scan, findingsVersion)
+}
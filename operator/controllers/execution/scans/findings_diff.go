@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+)
+
+// findingsDiffFile is the name of the file listing the changes of the findings compared to the previous scan of a ScheduledScan
+const findingsDiffFile = "findings-diff.json"
+
+// getScheduledScanOwner returns the reference to the ScheduledScan which created the scan, or nil if the scan wasn't created by a ScheduledScan.
+func getScheduledScanOwner(scan *executionv1.Scan) *metav1.OwnerReference {
+	owner := metav1.GetControllerOf(scan)
+	if owner == nil || owner.APIVersion != apiGVStr || owner.Kind != "ScheduledScan" {
+		return nil
+	}
+	return owner
+}
+
+// getPreviousScan returns the most recent completed scan created by the same ScheduledScan before the given scan, or nil if there is none.
+func getPreviousScan(scan *executionv1.Scan, siblings []executionv1.Scan) *executionv1.Scan {
+	owner := getScheduledScanOwner(scan)
+	if owner == nil {
+		return nil
+	}
+
+	var previous *executionv1.Scan
+	for i, sibling := range siblings {
+		siblingOwner := getScheduledScanOwner(&sibling)
+		if sibling.UID == scan.UID || siblingOwner == nil || siblingOwner.UID != owner.UID {
+			continue
+		}
+		if sibling.Status.State != executionv1.ScanStateDone || !sibling.CreationTimestamp.Before(&scan.CreationTimestamp) {
+			continue
+		}
+		if previous == nil || previous.CreationTimestamp.Before(&sibling.CreationTimestamp) {
+			previous = &siblings[i]
+		}
+	}
+	return previous
+}
+
+// diffFindingsWithPreviousScan compares the findings of a scan created by a ScheduledScan with the findings of the previous scan.
+// The findings written by the parsers are compared, as the diff is computed before the hooks of the scan run. Scans without a previous completed scan don't get a diff.
+func (r *ScanReconciler) diffFindingsWithPreviousScan(ctx context.Context, scan *executionv1.Scan, findings []findingsv1.Finding) error {
+	owner := getScheduledScanOwner(scan)
+	if owner == nil {
+		return nil
+	}
+
+	var siblings executionv1.ScanList
+	if err := r.List(ctx, &siblings, client.InNamespace(scan.Namespace)); err != nil {
+		return fmt.Errorf("failed to list the scans of the ScheduledScan: %w", err)
+	}
+	previousScan := getPreviousScan(scan, siblings.Items)
+	if previousScan == nil {
+		r.Log.V(7).Info("No previous completed scan to compare the findings to", "scan", scan.Name)
+		return nil
+	}
+
+	previousFindings, invalidFindings, err := r.getFindings(previousScan, parsedFindingsFile(previousScan))
+	if err != nil {
+		return fmt.Errorf("failed to download the findings of the previous scan %s: %w", previousScan.Name, err)
+	}
+	if invalidFindings != nil {
+		return fmt.Errorf("previous scan %s has invalid findings: %w", previousScan.Name, invalidFindings)
+	}
+	if previousFindings == nil {
+		r.Log.V(5).Info("Findings of the previous scan don't exist anymore, skipping the diff", "scan", scan.Name, "previousScan", previousScan.Name)
+		return nil
+	}
+
+	// suppressed findings are neither reported as new nor as resolved
+	diff := findingsv1.Diff(unsuppressedFindings(previousFindings), unsuppressedFindings(findings))
+	diff.PreviousScan = previousScan.Name
+	scan.Status.FindingsDiff = &executionv1.FindingsDiffStats{
+		PreviousScan: previousScan.Name,
+		New:          uint64(len(diff.New)),
+		Resolved:     uint64(len(diff.Resolved)),
+		Unchanged:    uint64(len(diff.Unchanged)),
+	}
+	r.Recorder.Eventf(scan, "Normal", "FindingsDiffed", "Compared to scan %s: %d new, %d resolved and %d unchanged findings", previousScan.Name, len(diff.New), len(diff.Resolved), len(diff.Unchanged))
+
+	var scheduledScan executionv1.ScheduledScan
+	if err := r.Get(ctx, types.NamespacedName{Name: owner.Name, Namespace: scan.Namespace}, &scheduledScan); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !scheduledScan.Spec.WriteFindingsDiff {
+		return nil
+	}
+	return r.uploadFindingsDiff(scan, diff)
+}
+
+func (r *ScanReconciler) uploadFindingsDiff(scan *executionv1.Scan, diff findingsv1.FindingsDiff) error {
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	if err := r.putScanFile(scan, findingsDiffFile, data); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", findingsDiffFile, err)
+	}
+
+	downloadURL, err := r.PresignedGetURL(*scan, findingsDiffFile, 7*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	scan.Status.FindingsDiffDownloadLink = downloadURL
+	return nil
+}
+
+// putScanFile uploads a file of the scan to the s3 storage.
+func (r *ScanReconciler) putScanFile(scan *executionv1.Scan, filename string, data []byte) error {
+	s3Config := r.getConfig().S3
+	if err := r.checkS3Connection(); err != nil {
+		return err
+	}
+	objectPath, err := getPresignedUrlPath(s3Config.URLTemplate, *scan, filename)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.MinioClient.PutObject(context.Background(), s3Config.Bucket, objectPath, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: "application/json"})
+	return err
+}
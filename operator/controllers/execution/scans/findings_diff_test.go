@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func scheduledScanChild(name string, owner types.UID, state executionv1.ScanState, createdAt time.Time) executionv1.Scan {
+	controller := true
+	return executionv1.Scan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(createdAt),
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: apiGVStr, Kind: "ScheduledScan", Name: string(owner), UID: owner, Controller: &controller},
+			},
+		},
+		Status: executionv1.ScanStatus{State: state},
+	}
+}
+
+var _ = Describe("getPreviousScan", func() {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	It("should return the latest completed scan of the same ScheduledScan created before the scan", func() {
+		scan := scheduledScanChild("nmap-3", "nmap", executionv1.ScanStateParseCompleted, now)
+		siblings := []executionv1.Scan{
+			scheduledScanChild("nmap-1", "nmap", executionv1.ScanStateDone, now.Add(-2*time.Hour)),
+			scheduledScanChild("nmap-2", "nmap", executionv1.ScanStateDone, now.Add(-1*time.Hour)),
+			scheduledScanChild("nmap-failed", "nmap", executionv1.ScanStateErrored, now.Add(-30*time.Minute)),
+			scheduledScanChild("other-1", "other", executionv1.ScanStateDone, now.Add(-10*time.Minute)),
+			scheduledScanChild("nmap-4", "nmap", executionv1.ScanStateDone, now.Add(time.Hour)),
+			scan,
+		}
+
+		Expect(getPreviousScan(&scan, siblings).Name).To(Equal("nmap-2"))
+	})
+
+	It("should return nil for the first scan of a ScheduledScan", func() {
+		scan := scheduledScanChild("nmap-1", "nmap", executionv1.ScanStateParseCompleted, now)
+		Expect(getPreviousScan(&scan, []executionv1.Scan{scan})).To(BeNil())
+	})
+
+	It("should return nil for scans which weren't created by a ScheduledScan", func() {
+		scan := executionv1.Scan{ObjectMeta: metav1.ObjectMeta{Name: "nmap"}}
+		siblings := []executionv1.Scan{scheduledScanChild("nmap-1", "nmap", executionv1.ScanStateDone, now)}
+		Expect(getPreviousScan(&scan, siblings)).To(BeNil())
+	})
+})
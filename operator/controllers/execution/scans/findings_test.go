@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ReadAndWrite hook findings", func() {
+	Describe("versionedFileName", func() {
+		It("should insert the hook name before the file extension", func() {
+			Expect(versionedFileName("findings.json", "cleanup")).To(Equal("findings.cleanup.json"))
+			Expect(versionedFileName("nmap-results.xml", "cleanup")).To(Equal("nmap-results.cleanup.xml"))
+		})
+
+		It("should append the hook name to files without an extension", func() {
+			Expect(versionedFileName("output", "cleanup")).To(Equal("output.cleanup"))
+		})
+	})
+
+	Describe("currentFindingsFile and currentRawResultFile", func() {
+		It("should default to the files written by the parser and the scanner", func() {
+			scan := &executionv1.Scan{Status: executionv1.ScanStatus{RawResultFile: "nmap-results.xml"}}
+			Expect(currentFindingsFile(scan)).To(Equal("findings.json"))
+			Expect(currentRawResultFile(scan)).To(Equal("nmap-results.xml"))
+
+			scan.Status.CurrentFindingsFile = "findings.cleanup.json"
+			scan.Status.CurrentRawResultFile = "nmap-results.cleanup.xml"
+			Expect(currentFindingsFile(scan)).To(Equal("findings.cleanup.json"))
+			Expect(currentRawResultFile(scan)).To(Equal("nmap-results.cleanup.xml"))
+		})
+	})
+
+	Describe("computeFindingStats", func() {
+		It("should count the findings by severity and category", func() {
+			findings := []findingsv1.Finding{
+				{Severity: findingsv1.SeverityInformational, Category: "Open Port"},
+				{Severity: "high", Category: "Open Port"},
+				{Severity: findingsv1.SeverityMedium, Category: "Header"},
+			}
+			Expect(computeFindingStats(findings)).To(Equal(executionv1.FindingStats{
+				Count: 3,
+				FindingSeverities: executionv1.FindingSeverities{
+					Informational: 1,
+					Medium:        1,
+					High:          1,
+				},
+				FindingCategories: map[string]uint64{
+					"Open Port": 2,
+					"Header":    1,
+				},
+			}))
+		})
+
+		It("should not count suppressed findings", func() {
+			findings := []findingsv1.Finding{
+				{Severity: findingsv1.SeverityHigh, Category: "Open Port"},
+				{Severity: findingsv1.SeverityHigh, Category: "Open Port", Suppression: &findingsv1.Suppression{Name: "accepted-ports"}},
+			}
+			Expect(computeFindingStats(findings)).To(Equal(executionv1.FindingStats{
+				Count:             1,
+				FindingSeverities: executionv1.FindingSeverities{High: 1},
+				FindingCategories: map[string]uint64{"Open Port": 1},
+			}))
+		})
+	})
+})
+
+var _ = Describe("getFindings", func() {
+	var (
+		r      *ScanReconciler
+		scan   *executionv1.Scan
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		var minioClient *minio.Client
+		server, _, minioClient = newFakeS3(map[string][]byte{
+			"scan-6b8c1a32/findings.json":         openPortFindings(findingsv1.SeverityHigh, findingsv1.SeverityLow),
+			"scan-6b8c1a32/findings.invalid.json": []byte(`[{"name": "Open Port: 22"}]`),
+		})
+		cfg := config.Default()
+		cfg.S3.Bucket = "securecodebox"
+		scan = &executionv1.Scan{ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default", UID: "6b8c1a32"}}
+		r = &ScanReconciler{Log: logr.Discard(), Config: cfg, MinioClient: minioClient}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should decode and validate the findings", func() {
+		findings, invalidFindings, err := r.getFindings(scan, "findings.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invalidFindings).NotTo(HaveOccurred())
+		Expect(findings).To(HaveLen(2))
+
+		_, invalidFindings, err = r.getFindings(scan, "findings.invalid.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invalidFindings).To(MatchError(ContainSubstring("finding 0: required field 'category' is missing")))
+	})
+
+	It("should return nil if the findings don't exist", func() {
+		findings, invalidFindings, err := r.getFindings(scan, "findings.cleanup.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invalidFindings).NotTo(HaveOccurred())
+		Expect(findings).To(BeNil())
+	})
+
+	It("should reject findings exceeding the maximum findings size", func() {
+		r.Config.Findings.MaxSize = resource.MustParse("100")
+		findings, invalidFindings, err := r.getFindings(scan, "findings.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(BeNil())
+		Expect(invalidFindings).To(MatchError(MatchRegexp(`^findings.json has a size of \d+, which exceeds the maximum findings size of 100 \(findings.maxSize in the operator config\)$`)))
+	})
+})
+
+// fakeS3 is a minimal s3 server storing the objects of the "securecodebox" bucket in memory
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := strings.TrimPrefix(req.URL.Path, "/securecodebox/")
+	switch req.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(req.Body)
+		if strings.HasPrefix(req.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data = decodeChunkedPayload(data)
+		}
+		s.objects[key] = data
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		data, ok := s.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if req.Method == http.MethodGet {
+				_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>")
+			}
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeChunkedPayload returns the data of an upload using the aws-chunked encoding, as used by minio for uploads over plain http
+func decodeChunkedPayload(payload []byte) []byte {
+	var data []byte
+	for {
+		header, rest, ok := bytes.Cut(payload, []byte("\r\n"))
+		if !ok {
+			return data
+		}
+		sizeHex, _, _ := strings.Cut(string(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 || int(size) > len(rest) {
+			return data
+		}
+		data = append(data, rest[:size]...)
+		payload = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+}
+
+// newFakeS3 starts a fake s3 server with the given objects and returns a minio client connected to it
+func newFakeS3(objects map[string][]byte) (*httptest.Server, *fakeS3, *minio.Client) {
+	storage := &fakeS3{objects: objects}
+	server := httptest.NewServer(storage)
+	serverURL, err := url.Parse(server.URL)
+	Expect(err).NotTo(HaveOccurred())
+	minioClient, err := minio.New(serverURL.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4("access-key", "secret-key", ""),
+		Region: "eu-central-1",
+	})
+	Expect(err).NotTo(HaveOccurred())
+	return server, storage, minioClient
+}
+
+// openPortFindings returns valid findings with the given severities
+func openPortFindings(severities ...findingsv1.Severity) []byte {
+	now := time.Now()
+	findings := []findingsv1.Finding{}
+	for i, severity := range severities {
+		findings = append(findings, findingsv1.Finding{
+			ID:       fmt.Sprintf("6f1f0d1a-3b1c-4c3e-9f2a-%012d", i),
+			ParsedAt: now,
+			Name:     fmt.Sprintf("Open Port: %d", 22+i),
+			Category: "Open Port",
+			Severity: severity,
+			Scan:     findingsv1.ScanSummary{CreatedAt: now, Name: "nmap", Namespace: "default", ScanType: "nmap"},
+		})
+	}
+	data, err := json.Marshal(findings)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
@@ -7,15 +7,18 @@ package scancontrollers
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/labels"
 
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
 	utils "github.com/secureCodeBox/secureCodeBox/operator/utils"
 	batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	resource "k8s.io/apimachinery/pkg/api/resource"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -43,6 +46,9 @@ func (r *ScanReconciler) setHookStatus(scan *executionv1.Scan) error {
 		}
 
 		hookStatuses = utils.MapHooksToHookStatus(scanCompletionHooks.Items)
+		for i, hook := range scanCompletionHooks.Items {
+			r.skipHookIfConditionDoesNotMatch(scan, hookStatuses[i], hook.Spec.When)
+		}
 	} else {
 		var clusterScanCompletionHooks executionv1.ClusterScanCompletionHookList
 		if err := r.List(ctx, &clusterScanCompletionHooks,
@@ -53,6 +59,9 @@ func (r *ScanReconciler) setHookStatus(scan *executionv1.Scan) error {
 		}
 
 		hookStatuses = utils.MapClusterHooksToHookStatus(clusterScanCompletionHooks.Items)
+		for i, hook := range clusterScanCompletionHooks.Items {
+			r.skipHookIfConditionDoesNotMatch(scan, hookStatuses[i], hook.Spec.When)
+		}
 	}
 
 	r.Log.V(7).Info("Found ScanCompletionHooks", "ScanCompletionHooks", len(hookStatuses))
@@ -69,6 +78,21 @@ func (r *ScanReconciler) setHookStatus(scan *executionv1.Scan) error {
 	return nil
 }
 
+// skipHookIfConditionDoesNotMatch marks the hook as skipped if the scan doesn't match the `when` condition of the hook.
+// Hooks with an invalid condition are executed anyway, to not silently drop results because of a typo.
+func (r *ScanReconciler) skipHookIfConditionDoesNotMatch(scan *executionv1.Scan, status *executionv1.HookStatus, condition *executionv1.HookCondition) {
+	matches, err := utils.MatchesHookCondition(condition, scan)
+	if err != nil {
+		r.Log.Error(err, "Invalid when condition of hook, executing it anyway", "hook", status.HookName)
+		r.Recorder.Eventf(scan, "Warning", "InvalidHookCondition", "Invalid when condition of hook %s, executing it anyway: %s", status.HookName, err)
+		return
+	}
+	if !matches {
+		status.State = executionv1.Skipped
+		r.Recorder.Eventf(scan, "Normal", "HookSkipped", "Skipped hook %s as the scan doesn't match its when condition", status.HookName)
+	}
+}
+
 func (r *ScanReconciler) migrateHookStatus(scan *executionv1.Scan) error {
 	ctx := context.Background()
 	var scanCompletionHooks executionv1.ScanCompletionHookList
@@ -144,17 +168,38 @@ func (r *ScanReconciler) executeHooks(scan *executionv1.Scan) error {
 	} else if err != nil {
 		scan.Status.State = executionv1.ScanStateErrored
 		scan.Status.ErrorDescription = "hook execution failed for a unknown hook. Check the scan.status.hookStatus field for more details"
+		r.Recorder.Event(scan, "Warning", "HookFailed", scan.Status.ErrorDescription)
 	} else if currentHooks == nil {
 		// No hooks left to execute
 		scan.Status.State = executionv1.ScanStateDone
+		scan.Status.FailedHooks = utils.FailedHooks(scan.Status.OrderedHookStatuses)
+		if len(scan.Status.FailedHooks) > 0 {
+			r.Recorder.Eventf(scan, "Warning", "DoneWithFailedHooks", "Scan is done, but the following hooks failed and were ignored: %s", strings.Join(scan.Status.FailedHooks, ", "))
+		} else {
+			r.Recorder.Event(scan, "Normal", "Done", "All hooks completed, Scan is done")
+		}
 	} else {
 		for _, hook := range currentHooks {
-			err = r.processHook(scan, hook)
+			hookErr := r.processHook(scan, hook)
 
-			if err != nil {
-				scan.Status.State = executionv1.ScanStateErrored
-				scan.Status.ErrorDescription = fmt.Sprintf("Failed to execute Hook '%s' in job '%s'. Check the logs of the hook for more information.", hook.HookName, hook.JobName)
+			if hookErr == nil {
+				continue
 			}
+			if asConfigurationError(hookErr) == nil && hook.FailurePolicy != nil && hook.FailurePolicy.Type != executionv1.HookFailurePolicyFail {
+				if failure := asHookFailureError(hookErr); failure != nil {
+					// the failure policy of the hook decides how to continue, the error doesn't affect the scan directly
+					r.handleHookFailure(scan, hook, failure.Error())
+				} else {
+					// transient errors don't use up the retries of the hook, the scan is requeued instead
+					r.Log.Error(hookErr, "Failed to process hook, retrying", "hook", hook.HookName)
+					err = hookErr
+				}
+				continue
+			}
+			err = hookErr
+			scan.Status.State = executionv1.ScanStateErrored
+			scan.Status.ErrorDescription = fmt.Sprintf("Failed to execute Hook '%s' in job '%s'. Check the logs of the hook for more information.", hook.HookName, hook.JobName)
+			r.Recorder.Eventf(scan, "Warning", "HookFailed", "Failed to execute Hook '%s' in job '%s': %s", hook.HookName, hook.JobName, hookErr)
 		}
 	}
 
@@ -185,37 +230,24 @@ func (r *ScanReconciler) processHook(scan *executionv1.Scan, nonCompletedHook *e
 }
 
 func (r *ScanReconciler) processPendingHook(scan *executionv1.Scan, status *executionv1.HookStatus, jobType string) error {
-	ctx := context.Background()
-	var err error
+	if status.RetryAfter != nil && time.Now().Before(status.RetryAfter.Time) {
+		// Waiting for the backoff of the retry to pass, the scan gets requeued once it has
+		return nil
+	}
 
-	var hookName string
-	var hookSpec executionv1.ScanCompletionHookSpec
+	hookName, hookSpec, err := r.getHookSpec(scan, status.HookName)
+	if apierrors.IsNotFound(err) {
+		return &HookFailureError{Err: fmt.Errorf("hook %s doesn't exist anymore: %w", status.HookName, err)}
+	} else if err != nil {
+		return err
+	}
 
-	if scan.Spec.ResourceMode == nil || *scan.Spec.ResourceMode == executionv1.NamespaceLocal {
-		var hook executionv1.ScanCompletionHook
-		err = r.Get(ctx, types.NamespacedName{Name: status.HookName, Namespace: scan.Namespace}, &hook)
-		if err != nil {
-			r.Log.Error(err, fmt.Sprintf("Failed to get ScanCompletionHook '%s' configured for scan '%s' which is located in namespace '%s'", status.HookName, scan.Name, scan.Namespace))
-			return err
-		}
-		hookName = hook.Name
-		hookSpec = hook.Spec
-	} else if *scan.Spec.ResourceMode == executionv1.ClusterWide {
-		var clusterHook executionv1.ClusterScanCompletionHook
-		err = r.Get(ctx, types.NamespacedName{Name: status.HookName}, &clusterHook)
-		if err != nil {
-			r.Log.Error(err, fmt.Sprintf("Failed to get ClusterScanCompletionHook '%s' configured for scan '%s' which is located in namespace '%s'", status.HookName, scan.Name, scan.Namespace))
-			return err
-		}
-		hookName = clusterHook.Name
-		hookSpec = clusterHook.Spec
+	if hookSpec.Webhook != nil {
+		return r.processWebhookHook(scan, status, &hookSpec)
 	}
 
 	var jobs *batch.JobList
-	jobs, err = r.getJobsForScan(scan, client.MatchingLabels{
-		"securecodebox.io/job-type":  jobType,
-		"securecodebox.io/hook-name": status.HookName,
-	})
+	jobs, err = r.getJobsForScan(scan, hookJobLabels(status, jobType))
 	if err != nil {
 		return err
 	}
@@ -226,19 +258,15 @@ func (r *ScanReconciler) processPendingHook(scan *executionv1.Scan, status *exec
 		return nil
 	}
 
-	urlExpirationDuration, err := utils.GetUrlExpirationDuration(utils.HookController)
-	if err != nil {
-		r.Log.Error(err, "Failed to parse hook url expiration")
-		panic(err)
-	}
+	urlExpirationDuration := r.getConfig().URLExpiration.Hook.Duration
 
 	var rawFileURL string
-	rawFileURL, err = r.PresignedGetURL(*scan, scan.Status.RawResultFile, urlExpirationDuration)
+	rawFileURL, err = r.PresignedGetURL(*scan, currentRawResultFile(scan), urlExpirationDuration)
 	if err != nil {
 		return err
 	}
 	var findingsFileURL string
-	findingsFileURL, err = r.PresignedGetURL(*scan, "findings.json", urlExpirationDuration)
+	findingsFileURL, err = r.PresignedGetURL(*scan, currentFindingsFile(scan), urlExpirationDuration)
 	if err != nil {
 		return err
 	}
@@ -248,13 +276,14 @@ func (r *ScanReconciler) processPendingHook(scan *executionv1.Scan, status *exec
 		findingsFileURL,
 	}
 	if hookSpec.Type == executionv1.ReadAndWrite {
+		// ReadAndWrite hooks write new versions of the files, the operator validates them once the hook is completed
 		var rawFileUploadURL string
-		rawFileUploadURL, err = r.PresignedPutURL(*scan, scan.Status.RawResultFile, urlExpirationDuration)
+		rawFileUploadURL, err = r.PresignedPutURL(*scan, versionedFileName(scan.Status.RawResultFile, status.HookName), urlExpirationDuration)
 		if err != nil {
 			return err
 		}
 		var findingsUploadURL string
-		findingsUploadURL, err = r.PresignedPutURL(*scan, "findings.json", urlExpirationDuration)
+		findingsUploadURL, err = r.PresignedPutURL(*scan, versionedFileName(findingsFile, status.HookName), urlExpirationDuration)
 		if err != nil {
 			return err
 		}
@@ -267,45 +296,168 @@ func (r *ScanReconciler) processPendingHook(scan *executionv1.Scan, status *exec
 		&hookSpec,
 		scan,
 		args,
+		status,
 	)
 
 	if err == nil {
 		// job was already started, setting status to correct jobName and state to ensure it's not overwritten with wrong values
 		status.JobName = jobName
 		status.State = executionv1.InProgress
+		status.RetryAfter = nil
 		r.Log.Info("Created job for hook", "hook", status)
+		r.Recorder.Eventf(scan, "Normal", "HookJobCreated", "Created job %s for hook %s", jobName, hookName)
 		return nil
 	}
 
 	return err
 }
 
+// getHookSpec returns the name and spec of the ScanCompletionHook or ClusterScanCompletionHook of the hook status, depending on the resourceMode of the scan.
+func (r *ScanReconciler) getHookSpec(scan *executionv1.Scan, hookName string) (string, executionv1.ScanCompletionHookSpec, error) {
+	ctx := context.Background()
+
+	if scan.Spec.ResourceMode == nil || *scan.Spec.ResourceMode == executionv1.NamespaceLocal {
+		var hook executionv1.ScanCompletionHook
+		if err := r.Get(ctx, types.NamespacedName{Name: hookName, Namespace: scan.Namespace}, &hook); err != nil {
+			r.Log.Error(err, fmt.Sprintf("Failed to get ScanCompletionHook '%s' configured for scan '%s' which is located in namespace '%s'", hookName, scan.Name, scan.Namespace))
+			return "", executionv1.ScanCompletionHookSpec{}, err
+		}
+		return hook.Name, hook.Spec, nil
+	} else if *scan.Spec.ResourceMode == executionv1.ClusterWide {
+		var clusterHook executionv1.ClusterScanCompletionHook
+		if err := r.Get(ctx, types.NamespacedName{Name: hookName}, &clusterHook); err != nil {
+			r.Log.Error(err, fmt.Sprintf("Failed to get ClusterScanCompletionHook '%s' configured for scan '%s' which is located in namespace '%s'", hookName, scan.Name, scan.Namespace))
+			return "", executionv1.ScanCompletionHookSpec{}, err
+		}
+		return clusterHook.Name, clusterHook.Spec, nil
+	}
+	return "", executionv1.ScanCompletionHookSpec{}, nil
+}
+
 func (r *ScanReconciler) processInProgressHook(scan *executionv1.Scan, status *executionv1.HookStatus, jobType string) error {
-	jobStatus, err := r.checkIfJobIsCompleted(scan, client.MatchingLabels{
-		"securecodebox.io/job-type":  jobType,
-		"securecodebox.io/hook-name": status.HookName,
-	})
+	if status.JobName == "" {
+		// hooks without a job are either webhook hooks or hooks of migrated scans
+		if _, hookSpec, err := r.getHookSpec(scan, status.HookName); err == nil && hookSpec.Webhook != nil {
+			return r.processInProgressWebhookHook(scan, status, &hookSpec)
+		}
+	}
+
+	jobStatus, err := r.checkIfJobIsCompleted(scan, hookJobLabels(status, jobType))
 	if err != nil {
 		r.Log.Error(err, "Failed to check job status for Hook")
 		return err
 	}
 	switch jobStatus {
 	case completed:
+		if status.Type == executionv1.ReadAndWrite {
+			invalidFindings, err := r.processReadAndWriteHookOutput(scan, status)
+			if err != nil {
+				r.Log.Error(err, "Failed to process the output of ReadAndWrite hook", "hook", status.HookName)
+				return err
+			}
+			if invalidFindings != nil {
+				r.Recorder.Eventf(scan, "Warning", "InvalidHookFindings", "Hook %s wrote invalid findings, keeping the previous findings: %s", status.HookName, invalidFindings)
+				r.handleHookFailure(scan, status, invalidFindings.Error())
+				return nil
+			}
+		}
 		// Job is completed => set current Hook to completed
 		status.State = executionv1.Completed
+		r.Recorder.Eventf(scan, "Normal", "HookCompleted", "Hook %s completed in job %s", status.HookName, status.JobName)
 	case incomplete:
 		// Still waiting for job to finish
 	case failed:
-		if status.State == executionv1.Pending {
-			status.State = executionv1.Cancelled
-		} else {
-			status.State = executionv1.Failed
-		}
+		r.handleHookFailure(scan, status, fmt.Sprintf("job %s failed", status.JobName))
 	}
 	return nil
 }
 
-func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHookSpec, scan *executionv1.Scan, cliArgs []string, serviceAccountName string) *batch.Job {
+// handleHookFailure applies the failure policy of the hook after its job failed or the hook couldn't be executed, see HookFailureError.
+// Hooks with the "Retry" policy are reset to pending until they are out of retries, all other hooks are marked as failed.
+func (r *ScanReconciler) handleHookFailure(scan *executionv1.Scan, status *executionv1.HookStatus, cause string) {
+	maxRetries, backoff := hookRetryPolicy(status.FailurePolicy)
+	if status.Retries < maxRetries {
+		status.Retries++
+		status.State = executionv1.Pending
+		status.JobName = ""
+		status.RetryAfter = &metav1.Time{Time: time.Now().Add(backoff)}
+		r.Recorder.Eventf(scan, "Warning", "HookRetry", "Hook %s failed (%s), retrying in %s (retry %d of %d)", status.HookName, cause, backoff, status.Retries, maxRetries)
+		return
+	}
+
+	if status.State == executionv1.Pending {
+		r.markHookFailed(scan, status, executionv1.Cancelled, "HookJobFailed", cause)
+	} else {
+		r.markHookFailed(scan, status, executionv1.Failed, "HookJobFailed", cause)
+	}
+}
+
+// markHookFailed sets the final state of a failed hook and emits an event with the given reason for it.
+func (r *ScanReconciler) markHookFailed(scan *executionv1.Scan, status *executionv1.HookStatus, state executionv1.HookState, reason string, cause string) {
+	status.State = state
+	status.RetryAfter = nil
+	if utils.IsHookFailureIgnored(status) {
+		r.Recorder.Eventf(scan, "Warning", reason, "Hook %s failed (%s), continuing with the next hooks as its failure policy is Ignore. Check the logs of the hook for more information.", status.HookName, cause)
+	} else if status.JobName != "" {
+		r.Recorder.Eventf(scan, "Warning", reason, "Hook %s failed in job %s. Check the logs of the hook for more information.", status.HookName, status.JobName)
+	} else {
+		r.Recorder.Eventf(scan, "Warning", reason, "Hook %s failed (%s).", status.HookName, cause)
+	}
+}
+
+// hookRetryPolicy returns the max number of retries and the backoff between them for the failure policy of a hook.
+func hookRetryPolicy(policy *executionv1.HookFailurePolicy) (int32, time.Duration) {
+	if policy == nil || policy.Type != executionv1.HookFailurePolicyRetry {
+		return 0, 0
+	}
+
+	maxRetries := int32(3)
+	backoff := 30 * time.Second
+	if policy.Retry != nil {
+		if policy.Retry.Max > 0 {
+			maxRetries = policy.Retry.Max
+		}
+		if policy.Retry.Backoff != nil {
+			backoff = policy.Retry.Backoff.Duration
+		}
+	}
+	return maxRetries, backoff
+}
+
+// nextHookRetry returns the time until the next hook retry is due, or false if no hook is waiting for a retry.
+func nextHookRetry(scan *executionv1.Scan) (time.Duration, bool) {
+	var next *metav1.Time
+	for _, group := range scan.Status.OrderedHookStatuses {
+		for _, status := range group {
+			if status.State == executionv1.Pending && status.RetryAfter != nil && (next == nil || status.RetryAfter.Before(next)) {
+				next = status.RetryAfter
+			}
+		}
+	}
+	if next == nil {
+		return 0, false
+	}
+	return time.Until(next.Time), true
+}
+
+// hookJobLabels returns the labels identifying the job of the current attempt of a hook.
+// Jobs of retries and reruns are labeled with their attempt / run, so that the jobs of previous attempts are ignored.
+func hookJobLabels(status *executionv1.HookStatus, jobType string) client.MatchingLabels {
+	labels := client.MatchingLabels{
+		"securecodebox.io/job-type":  jobType,
+		"securecodebox.io/hook-name": status.HookName,
+	}
+	if status.Retries > 0 {
+		labels["securecodebox.io/hook-attempt"] = strconv.Itoa(int(status.Retries))
+	}
+	if status.Reruns > 0 {
+		labels["securecodebox.io/hook-run"] = strconv.Itoa(int(status.Reruns))
+	}
+	return labels
+}
+
+func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHookSpec, scan *executionv1.Scan, cliArgs []string, serviceAccountName string, cfg config.OperatorConfig) *batch.Job {
+	jobDefaults := cfg.JobDefaults.Hook
 	standardEnvVars := []corev1.EnvVar{
 		{
 			Name: "NAMESPACE",
@@ -333,19 +485,7 @@ func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHoo
 	}
 	labels["securecodebox.io/hook-name"] = hookName
 
-	var backOffLimit int32 = 3
-	truePointer := true
-	falsePointer := false
-	resources := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("200m"),
-			corev1.ResourceMemory: resource.MustParse("100Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("400m"),
-			corev1.ResourceMemory: resource.MustParse("200Mi"),
-		},
-	}
+	resources := *jobDefaults.Resources.DeepCopy()
 	if len(hookSpec.Resources.Requests) != 0 || len(hookSpec.Resources.Limits) != 0 {
 		resources = hookSpec.Resources
 	}
@@ -359,7 +499,8 @@ func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHoo
 		},
 		Spec: batch.JobSpec{
 			TTLSecondsAfterFinished: hookSpec.TTLSecondsAfterFinished,
-			BackoffLimit:            &backOffLimit,
+			BackoffLimit:            jobDefaults.BackoffLimit,
+			ActiveDeadlineSeconds:   jobDefaults.ActiveDeadlineSeconds,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
@@ -367,7 +508,7 @@ func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHoo
 					},
 					Annotations: map[string]string{
 						"auto-discovery.securecodebox.io/ignore": "true",
-						"sidecar.istio.io/inject":                allowIstioSidecarInjectionInJobs,
+						"sidecar.istio.io/inject":                strconv.FormatBool(cfg.AllowIstioSidecarInjectionInJobs),
 					},
 				},
 				Spec: corev1.PodSpec{
@@ -382,15 +523,7 @@ func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHoo
 							Env:             append(standardEnvVars, hookSpec.Env...),
 							ImagePullPolicy: hookSpec.ImagePullPolicy,
 							Resources:       resources,
-							SecurityContext: &corev1.SecurityContext{
-								RunAsNonRoot:             &truePointer,
-								AllowPrivilegeEscalation: &falsePointer,
-								ReadOnlyRootFilesystem:   &truePointer,
-								Privileged:               &falsePointer,
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{"ALL"},
-								},
-							},
+							SecurityContext: jobDefaults.SecurityContext.DeepCopy(),
 						},
 					},
 				},
@@ -398,7 +531,7 @@ func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHoo
 		},
 	}
 
-	injectCustomCACertsIfConfigured(job)
+	injectCustomCACertsIfConfigured(job, cfg.CustomCACertificate)
 
 	// Merge VolumeMounts from HookTemplate
 	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(
@@ -411,27 +544,16 @@ func generateJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHoo
 		hookSpec.Volumes...,
 	)
 
-	// Set affinity from Scan, if one is set. Otherwise keep value from template
-	if scan.Spec.Affinity != nil {
-		job.Spec.Template.Spec.Affinity = scan.Spec.Affinity
-	} else {
-		job.Spec.Template.Spec.Affinity = hookSpec.Affinity
-	}
-
-	// Merge NodeSelectors from Hook & Scan into Hook Job
-	job.Spec.Template.Spec.NodeSelector = utils.MergeStringMaps(job.Spec.Template.Spec.NodeSelector, hookSpec.NodeSelector, scan.Spec.NodeSelector)
-
-	// Replace tolerations from template with those from the scan, if specified.
-	// Otherwise, stick to those from the template
-	if scan.Spec.Tolerations != nil {
-		job.Spec.Template.Spec.Tolerations = scan.Spec.Tolerations
-	} else {
-		job.Spec.Template.Spec.Tolerations = hookSpec.Tolerations
-	}
+	// Set nodeSelector, affinity, tolerations and resources from Hook, Scan and the jobPlacement of the Scan
+	applyJobPlacement(job, scan, hookPhase, executionv1.JobPlacementSpec{
+		NodeSelector: hookSpec.NodeSelector,
+		Affinity:     hookSpec.Affinity,
+		Tolerations:  hookSpec.Tolerations,
+	})
 	return job
 }
 
-func (r *ScanReconciler) createJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHookSpec, scan *executionv1.Scan, cliArgs []string) (string, error) {
+func (r *ScanReconciler) createJobForHook(hookName string, hookSpec *executionv1.ScanCompletionHookSpec, scan *executionv1.Scan, cliArgs []string, status *executionv1.HookStatus) (string, error) {
 	ctx := context.Background()
 
 	serviceAccountName := "scan-completion-hook"
@@ -440,28 +562,15 @@ func (r *ScanReconciler) createJobForHook(hookName string, hookSpec *executionv1
 		serviceAccountName = *hookSpec.ServiceAccountName
 	} else {
 		// Check and create a serviceAccount for the hook in its namespace, if it doesn't already exist.
-		rules := []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{"execution.securecodebox.io"},
-				Resources: []string{"scans"},
-				Verbs:     []string{"get"},
-			},
-			{
-				APIGroups: []string{"execution.securecodebox.io"},
-				Resources: []string{"scans/status"},
-				Verbs:     []string{"get", "patch"},
-			},
+		if err := r.ensureServiceAccountExists(ctx, scan.Namespace, hookServiceAccount); err != nil {
+			return "", err
 		}
-
-		r.ensureServiceAccountExists(
-			scan.Namespace,
-			serviceAccountName,
-			"ScanCompletionHooks need to access the current scan to view where its results are stored",
-			rules,
-		)
 	}
 
-	job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+	job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, r.getConfig())
+	for key, value := range hookJobLabels(status, job.ObjectMeta.Labels["securecodebox.io/job-type"]) {
+		job.ObjectMeta.Labels[key] = value
+	}
 
 	if err := ctrl.SetControllerReference(scan, job, r.Scheme); err != nil {
 		r.Log.Error(err, "Unable to set controllerReference on job", "job", job)
@@ -471,6 +580,10 @@ func (r *ScanReconciler) createJobForHook(hookName string, hookSpec *executionv1
 	r.Log.Info("Creating hook job", "job", job.Name, "scanCompletionHook", hookName, "scan", scan.Name, "namespace", scan.Namespace)
 
 	if err := r.Create(ctx, job); err != nil {
+		if apierrors.IsInvalid(err) {
+			// retrying won't help unless the hook gets fixed
+			return "", &HookFailureError{Err: fmt.Errorf("job of hook %s is invalid: %w", hookName, err)}
+		}
 		return "", err
 	}
 	return job.Name, nil
@@ -6,14 +6,17 @@ package scancontrollers
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 var _ = Describe("generateJobForHook", func() {
@@ -23,6 +26,7 @@ var _ = Describe("generateJobForHook", func() {
 		scan               *executionv1.Scan
 		cliArgs            []string
 		serviceAccountName string
+		cfg                config.OperatorConfig
 	)
 
 	BeforeEach(func() {
@@ -39,10 +43,11 @@ var _ = Describe("generateJobForHook", func() {
 		}
 		cliArgs = []string{"arg1", "arg2"}
 		serviceAccountName = "test-sa"
+		cfg = config.Default()
 	})
 
 	It("should generate a job with correct basic properties", func() {
-		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
 
 		Expect(job.ObjectMeta.GenerateName).To(HavePrefix(fmt.Sprintf("%s-%s", hookName, scan.Name)))
 		Expect(job.ObjectMeta.Namespace).To(Equal(scan.Namespace))
@@ -52,19 +57,19 @@ var _ = Describe("generateJobForHook", func() {
 	})
 
 	It("should set correct labels based on hook type", func() {
-		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
 
 		Expect(job.ObjectMeta.Labels["securecodebox.io/job-type"]).To(Equal("read-and-write-hook"))
 		Expect(job.ObjectMeta.Labels["securecodebox.io/hook-name"]).To(Equal(hookName))
 
 		hookSpec.Type = executionv1.ReadOnly
-		job = generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+		job = generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
 
 		Expect(job.ObjectMeta.Labels["securecodebox.io/job-type"]).To(Equal("read-only-hook"))
 	})
 
 	It("should set default resource requirements if not specified", func() {
-		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
 
 		Expect(job.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]).To(Equal(resource.MustParse("200m")))
 		Expect(job.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("100Mi")))
@@ -84,14 +89,47 @@ var _ = Describe("generateJobForHook", func() {
 			},
 		}
 
-		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
 
 		Expect(job.Spec.Template.Spec.Containers[0].Resources).To(Equal(hookSpec.Resources))
 	})
 
+	It("should apply the job defaults configured for the operator", func() {
+		var backoffLimit int32 = 5
+		var activeDeadlineSeconds int64 = 600
+		cfg.JobDefaults.Hook.Resources = corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("50m"),
+			},
+		}
+		cfg.JobDefaults.Hook.BackoffLimit = &backoffLimit
+		cfg.JobDefaults.Hook.ActiveDeadlineSeconds = &activeDeadlineSeconds
+
+		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
+
+		Expect(job.Spec.Template.Spec.Containers[0].Resources).To(Equal(cfg.JobDefaults.Hook.Resources))
+		Expect(job.Spec.BackoffLimit).To(Equal(&backoffLimit))
+		Expect(job.Spec.ActiveDeadlineSeconds).To(Equal(&activeDeadlineSeconds))
+		Expect(job.Spec.Template.Spec.Containers[0].SecurityContext).To(Equal(cfg.JobDefaults.Hook.SecurityContext))
+	})
+
+	It("should mount the custom ca certificates if configured", func() {
+		cfg.CustomCACertificate.ExistingCertificate = "my-ca"
+
+		job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
+
+		Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Name", "ca-certificate")))
+		Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      "ca-certificate",
+			ReadOnly:  true,
+			MountPath: "/etc/ssl/certs/public.crt",
+			SubPath:   "public.crt",
+		}))
+	})
+
 	Context("Environment Variables", func() {
 		It("should include standard environment variables", func() {
-			job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+			job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
 
 			envVars := job.Spec.Template.Spec.Containers[0].Env
 			Expect(envVars).To(ContainElement(corev1.EnvVar{
@@ -113,7 +151,7 @@ var _ = Describe("generateJobForHook", func() {
 				{Name: "TEST_ENV", Value: "test-value"},
 			}
 
-			job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName)
+			job := generateJobForHook(hookName, hookSpec, scan, cliArgs, serviceAccountName, cfg)
 
 			envVars := job.Spec.Template.Spec.Containers[0].Env
 			Expect(envVars).To(Equal(
@@ -135,3 +173,84 @@ var _ = Describe("generateJobForHook", func() {
 		})
 	})
 })
+
+var _ = Describe("hook failure policy", func() {
+	var (
+		r      *ScanReconciler
+		scan   *executionv1.Scan
+		status *executionv1.HookStatus
+	)
+
+	BeforeEach(func() {
+		r = &ScanReconciler{Recorder: record.NewFakeRecorder(10)}
+		scan = &executionv1.Scan{ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default"}}
+		status = &executionv1.HookStatus{HookName: "slack", JobName: "slack-nmap-abc", State: executionv1.InProgress, Type: executionv1.ReadOnly}
+	})
+
+	It("should mark the hook as failed without a failure policy", func() {
+		r.handleHookFailure(scan, status, "job slack-nmap-abc failed")
+
+		Expect(status.State).To(Equal(executionv1.Failed))
+		Expect(status.Retries).To(BeZero())
+	})
+
+	It("should mark the hook as failed with the Ignore failure policy", func() {
+		status.FailurePolicy = &executionv1.HookFailurePolicy{Type: executionv1.HookFailurePolicyIgnore}
+		r.handleHookFailure(scan, status, "job slack-nmap-abc failed")
+
+		Expect(status.State).To(Equal(executionv1.Failed))
+	})
+
+	It("should reset the hook to pending until it is out of retries", func() {
+		status.FailurePolicy = &executionv1.HookFailurePolicy{
+			Type:  executionv1.HookFailurePolicyRetry,
+			Retry: &executionv1.HookRetryPolicy{Max: 2, Backoff: &metav1.Duration{Duration: time.Minute}},
+		}
+
+		r.handleHookFailure(scan, status, "job slack-nmap-abc failed")
+		Expect(status.State).To(Equal(executionv1.Pending))
+		Expect(status.Retries).To(BeEquivalentTo(1))
+		Expect(status.JobName).To(BeEmpty())
+		Expect(status.RetryAfter.Time).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+
+		status.State = executionv1.InProgress
+		r.handleHookFailure(scan, status, "job slack-nmap-def failed")
+		Expect(status.State).To(Equal(executionv1.Pending))
+		Expect(status.Retries).To(BeEquivalentTo(2))
+
+		status.State = executionv1.InProgress
+		r.handleHookFailure(scan, status, "job slack-nmap-ghi failed")
+		Expect(status.State).To(Equal(executionv1.Failed))
+		Expect(status.Retries).To(BeEquivalentTo(2))
+	})
+
+	It("should use the defaults for retry policies without explicit settings", func() {
+		maxRetries, backoff := hookRetryPolicy(&executionv1.HookFailurePolicy{Type: executionv1.HookFailurePolicyRetry})
+		Expect(maxRetries).To(BeEquivalentTo(3))
+		Expect(backoff).To(Equal(30 * time.Second))
+	})
+
+	It("should only match the jobs of the current attempt of a hook", func() {
+		Expect(hookJobLabels(status, "read-only-hook")).To(HaveLen(2))
+
+		status.Retries = 2
+		Expect(hookJobLabels(status, "read-only-hook")).To(HaveKeyWithValue("securecodebox.io/hook-attempt", "2"))
+
+		status.Reruns = 1
+		Expect(hookJobLabels(status, "read-only-hook")).To(HaveKeyWithValue("securecodebox.io/hook-run", "1"))
+	})
+
+	It("should return the time until the next retry of a pending hook", func() {
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{
+			{{HookName: "defectdojo", State: executionv1.Completed}},
+			{
+				{HookName: "slack", State: executionv1.Pending, RetryAfter: &metav1.Time{Time: time.Now().Add(time.Minute)}},
+				{HookName: "teams", State: executionv1.Pending, RetryAfter: &metav1.Time{Time: time.Now().Add(10 * time.Second)}},
+			},
+		}
+
+		retryIn, ok := nextHookRetry(scan)
+		Expect(ok).To(BeTrue())
+		Expect(retryIn).To(BeNumerically("~", 10*time.Second, time.Second))
+	})
+})
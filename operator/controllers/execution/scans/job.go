@@ -7,9 +7,9 @@ package scancontrollers
 import (
 	"context"
 	"fmt"
-	"os"
 
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
 	batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -75,9 +75,9 @@ func (r *ScanReconciler) checkIfJobIsCompleted(scan *executionv1.Scan, labels cl
 
 // injectCustomCACertsIfConfigured injects CA Certificates to /etc/ssl/certs/
 // currently only supports jobs with a single container
-func injectCustomCACertsIfConfigured(job *batch.Job) {
-	customCACertificate, isConfigured := os.LookupEnv("CUSTOM_CA_CERTIFICATE_EXISTING_CERTIFICATE")
-	if !isConfigured {
+func injectCustomCACertsIfConfigured(job *batch.Job, caConfig config.CustomCACertificateConfig) {
+	customCACertificate := caConfig.ExistingCertificate
+	if customCACertificate == "" {
 		return
 	}
 
@@ -92,10 +92,7 @@ func injectCustomCACertsIfConfigured(job *batch.Job) {
 		},
 	})
 
-	certificateName, hasCertificateName := os.LookupEnv("CUSTOM_CA_CERTIFICATE_NAME")
-	if !hasCertificateName {
-		panic("Missing CUSTOM_CA_CERTIFICATE_NAME config parameter. Do you have `customCACertificate.certificate` configured you helm values?")
-	}
+	certificateName := caConfig.Certificate
 	mountPath := fmt.Sprintf("/etc/ssl/certs/%s", certificateName)
 
 	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	util "github.com/secureCodeBox/secureCodeBox/operator/utils"
+	batch "k8s.io/api/batch/v1"
+)
+
+type jobPhase string
+
+const (
+	parserPhase jobPhase = "parser"
+	hookPhase   jobPhase = "hook"
+)
+
+// getPhasePlacement returns the placement override configured in the scan for the given phase, or nil if there is none.
+func getPhasePlacement(scan *executionv1.Scan, phase jobPhase) *executionv1.JobPlacementOverride {
+	if scan.Spec.JobPlacement == nil {
+		return nil
+	}
+	switch phase {
+	case parserPhase:
+		return scan.Spec.JobPlacement.Parser
+	case hookPhase:
+		return scan.Spec.JobPlacement.Hooks
+	}
+	return nil
+}
+
+// applyJobPlacement configures nodeSelector, affinity, tolerations and resources of a parser or hook job.
+// Values are taken from (highest priority first): the jobPlacement of the scan for the given phase, the general jobPlacement of the scan,
+// the nodeSelector, affinity and tolerations of the scan and finally the defaults from the ParseDefinition / ScanCompletionHook.
+// NodeSelectors are merged, all other fields are replaced by the highest priority value set.
+func applyJobPlacement(job *batch.Job, scan *executionv1.Scan, phase jobPhase, defaults executionv1.JobPlacementSpec) {
+	placements := []executionv1.JobPlacementSpec{
+		defaults,
+		{
+			NodeSelector: scan.Spec.NodeSelector,
+			Affinity:     scan.Spec.Affinity,
+			Tolerations:  scan.Spec.Tolerations,
+		},
+	}
+	if scan.Spec.JobPlacement != nil {
+		placements = append(placements, scan.Spec.JobPlacement.JobPlacementSpec)
+	}
+	phasePlacement := getPhasePlacement(scan, phase)
+	if phasePlacement != nil {
+		placements = append(placements, phasePlacement.JobPlacementSpec)
+	}
+
+	podSpec := &job.Spec.Template.Spec
+	for _, placement := range placements {
+		podSpec.NodeSelector = util.MergeStringMaps(podSpec.NodeSelector, placement.NodeSelector)
+		if placement.Affinity != nil {
+			podSpec.Affinity = placement.Affinity
+		}
+		if placement.Tolerations != nil {
+			podSpec.Tolerations = placement.Tolerations
+		}
+	}
+
+	if phasePlacement != nil && phasePlacement.Resources != nil {
+		podSpec.Containers[0].Resources = *phasePlacement.Resources
+	}
+}
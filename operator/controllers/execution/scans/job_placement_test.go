@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	batch "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("applyJobPlacement", func() {
+	var (
+		job      *batch.Job
+		scan     *executionv1.Scan
+		defaults executionv1.JobPlacementSpec
+	)
+
+	affinityForZone := func(zone string) *corev1.Affinity {
+		return &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{zone}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	tolerationFor := func(key string) []corev1.Toleration {
+		return []corev1.Toleration{{Key: key, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+	}
+
+	BeforeEach(func() {
+		job = &batch.Job{
+			Spec: batch.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "parser"}},
+					},
+				},
+			},
+		}
+		scan = &executionv1.Scan{}
+		defaults = executionv1.JobPlacementSpec{
+			NodeSelector: map[string]string{"definition": "true"},
+			Affinity:     affinityForZone("definition"),
+			Tolerations:  tolerationFor("definition"),
+		}
+	})
+
+	It("should use the defaults if the scan doesn't configure anything", func() {
+		applyJobPlacement(job, scan, parserPhase, defaults)
+
+		Expect(job.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"definition": "true"}))
+		Expect(job.Spec.Template.Spec.Affinity).To(Equal(affinityForZone("definition")))
+		Expect(job.Spec.Template.Spec.Tolerations).To(Equal(tolerationFor("definition")))
+	})
+
+	It("should prefer the placement of the scan over the defaults", func() {
+		scan.Spec.NodeSelector = map[string]string{"scan": "true"}
+		scan.Spec.Affinity = affinityForZone("scan")
+		scan.Spec.Tolerations = tolerationFor("scan")
+
+		applyJobPlacement(job, scan, hookPhase, defaults)
+
+		Expect(job.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"definition": "true", "scan": "true"}))
+		Expect(job.Spec.Template.Spec.Affinity).To(Equal(affinityForZone("scan")))
+		Expect(job.Spec.Template.Spec.Tolerations).To(Equal(tolerationFor("scan")))
+	})
+
+	It("should prefer the jobPlacement over the placement of the scan", func() {
+		scan.Spec.Affinity = affinityForZone("scan")
+		scan.Spec.Tolerations = tolerationFor("scan")
+		scan.Spec.JobPlacement = &executionv1.JobPlacement{
+			JobPlacementSpec: executionv1.JobPlacementSpec{
+				NodeSelector: map[string]string{"pool": "scanning"},
+				Tolerations:  tolerationFor("dedicated"),
+			},
+		}
+
+		applyJobPlacement(job, scan, parserPhase, defaults)
+
+		Expect(job.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"definition": "true", "pool": "scanning"}))
+		Expect(job.Spec.Template.Spec.Affinity).To(Equal(affinityForZone("scan")))
+		Expect(job.Spec.Template.Spec.Tolerations).To(Equal(tolerationFor("dedicated")))
+	})
+
+	It("should only apply the phase override to the matching phase", func() {
+		parserResources := corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+		}
+		scan.Spec.JobPlacement = &executionv1.JobPlacement{
+			JobPlacementSpec: executionv1.JobPlacementSpec{
+				Tolerations: tolerationFor("dedicated"),
+			},
+			Parser: &executionv1.JobPlacementOverride{
+				JobPlacementSpec: executionv1.JobPlacementSpec{
+					Tolerations: tolerationFor("parser"),
+				},
+				Resources: &parserResources,
+			},
+		}
+
+		applyJobPlacement(job, scan, parserPhase, defaults)
+		Expect(job.Spec.Template.Spec.Tolerations).To(Equal(tolerationFor("parser")))
+		Expect(job.Spec.Template.Spec.Containers[0].Resources).To(Equal(parserResources))
+
+		hookJob := &batch.Job{
+			Spec: batch.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "hook"}},
+					},
+				},
+			},
+		}
+		applyJobPlacement(hookJob, scan, hookPhase, defaults)
+		Expect(hookJob.Spec.Template.Spec.Tolerations).To(Equal(tolerationFor("dedicated")))
+		Expect(hookJob.Spec.Template.Spec.Containers[0].Resources).To(Equal(corev1.ResourceRequirements{}))
+	})
+})
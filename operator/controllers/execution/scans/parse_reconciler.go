@@ -7,13 +7,13 @@ package scancontrollers
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
 	util "github.com/secureCodeBox/secureCodeBox/operator/utils"
 	batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	resource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -45,6 +45,7 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 
 			scan.Status.State = executionv1.ScanStateErrored
 			scan.Status.ErrorDescription = fmt.Sprintf("No ParseDefinition for ResultType '%s' found in Scans Namespace.", parseType)
+			r.Recorder.Event(scan, "Warning", "ParseDefinitionNotFound", scan.Status.ErrorDescription)
 			if err := r.updateScanStatus(ctx, scan); err != nil {
 				r.Log.Error(err, "unable to update Scan status")
 				return err
@@ -61,6 +62,7 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 
 			scan.Status.State = executionv1.ScanStateErrored
 			scan.Status.ErrorDescription = fmt.Sprintf("No ClusterParseDefinition for ResultType '%s' found.", parseType)
+			r.Recorder.Event(scan, "Warning", "ClusterParseDefinitionNotFound", scan.Status.ErrorDescription)
 			if err := r.updateScanStatus(ctx, scan); err != nil {
 				r.Log.Error(err, "unable to update Scan status")
 				return err
@@ -72,11 +74,8 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 		parseDefinitionSpec = clusterParseDefinition.Spec
 	}
 
-	urlExpirationDuration, err := util.GetUrlExpirationDuration(util.ParserController)
-	if err != nil {
-		r.Log.Error(err, "Failed to parse parser url expiration")
-		panic(err)
-	}
+	cfg := r.getConfig()
+	urlExpirationDuration := cfg.URLExpiration.Parser.Duration
 
 	findingsUploadURL, err := r.PresignedPutURL(*scan, "findings.json", urlExpirationDuration)
 	if err != nil {
@@ -88,29 +87,9 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 		return err
 	}
 
-	rules := []rbacv1.PolicyRule{
-		{
-			APIGroups: []string{"execution.securecodebox.io"},
-			Resources: []string{"scans"},
-			Verbs:     []string{"get"},
-		},
-		{
-			APIGroups: []string{"execution.securecodebox.io"},
-			Resources: []string{"scans/status"},
-			Verbs:     []string{"get", "patch"},
-		},
-		{
-			APIGroups: []string{"execution.securecodebox.io"},
-			Resources: []string{"parsedefinitions"},
-			Verbs:     []string{"get"},
-		},
+	if err := r.ensureServiceAccountExists(ctx, scan.Namespace, parserServiceAccount); err != nil {
+		return err
 	}
-	r.ensureServiceAccountExists(
-		scan.Namespace,
-		"parser",
-		"Parser need to access the status of Scans to update how many findings have been identified",
-		rules,
-	)
 
 	labels := scan.ObjectMeta.DeepCopy().Labels
 	if labels == nil {
@@ -118,20 +97,9 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 	}
 	labels["securecodebox.io/job-type"] = "parser"
 	automountServiceAccountToken := true
-	var backOffLimit int32 = 3
-	truePointer := true
-	falsePointer := false
+	jobDefaults := cfg.JobDefaults.Parser
 
-	resources := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("200m"),
-			corev1.ResourceMemory: resource.MustParse("100Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("400m"),
-			corev1.ResourceMemory: resource.MustParse("200Mi"),
-		},
-	}
+	resources := *jobDefaults.Resources.DeepCopy()
 	if len(parseDefinitionSpec.Resources.Requests) != 0 || len(parseDefinitionSpec.Resources.Limits) != 0 {
 		resources = parseDefinitionSpec.Resources
 	}
@@ -145,7 +113,8 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 		},
 		Spec: batch.JobSpec{
 			TTLSecondsAfterFinished: parseDefinitionSpec.TTLSecondsAfterFinished,
-			BackoffLimit:            &backOffLimit,
+			BackoffLimit:            jobDefaults.BackoffLimit,
+			ActiveDeadlineSeconds:   jobDefaults.ActiveDeadlineSeconds,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
@@ -153,7 +122,7 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 					},
 					Annotations: map[string]string{
 						"auto-discovery.securecodebox.io/ignore": "true",
-						"sidecar.istio.io/inject":                allowIstioSidecarInjectionInJobs,
+						"sidecar.istio.io/inject":                strconv.FormatBool(cfg.AllowIstioSidecarInjectionInJobs),
 					},
 				},
 				Spec: corev1.PodSpec{
@@ -184,15 +153,7 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 							},
 							ImagePullPolicy: parseDefinitionSpec.ImagePullPolicy,
 							Resources:       resources,
-							SecurityContext: &corev1.SecurityContext{
-								RunAsNonRoot:             &truePointer,
-								AllowPrivilegeEscalation: &falsePointer,
-								ReadOnlyRootFilesystem:   &truePointer,
-								Privileged:               &falsePointer,
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{"ALL"},
-								},
-							},
+							SecurityContext: jobDefaults.SecurityContext.DeepCopy(),
 						},
 					},
 					AutomountServiceAccountToken: &automountServiceAccountToken,
@@ -218,25 +179,15 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 		parseDefinitionSpec.Volumes...,
 	)
 
-	// Merge NodeSelectors from ParseDefinition & Scan into Parse Job
-	job.Spec.Template.Spec.NodeSelector = util.MergeStringMaps(job.Spec.Template.Spec.NodeSelector, parseDefinitionSpec.NodeSelector, scan.Spec.NodeSelector)
-
-	// Set affinity based on scan, if defined, or parseDefinition if not overridden by scan
-	if scan.Spec.Affinity != nil {
-		job.Spec.Template.Spec.Affinity = scan.Spec.Affinity
-	} else {
-		job.Spec.Template.Spec.Affinity = parseDefinitionSpec.Affinity
-	}
-
-	// Set tolerations, either from parseDefinition or from scan
-	if scan.Spec.Tolerations != nil {
-		job.Spec.Template.Spec.Tolerations = scan.Spec.Tolerations
-	} else {
-		job.Spec.Template.Spec.Tolerations = parseDefinitionSpec.Tolerations
-	}
+	// Set nodeSelector, affinity, tolerations and resources from ParseDefinition, Scan and the jobPlacement of the Scan
+	applyJobPlacement(job, scan, parserPhase, executionv1.JobPlacementSpec{
+		NodeSelector: parseDefinitionSpec.NodeSelector,
+		Affinity:     parseDefinitionSpec.Affinity,
+		Tolerations:  parseDefinitionSpec.Tolerations,
+	})
 
 	r.Log.V(8).Info("Configuring customCACerts for Parser")
-	injectCustomCACertsIfConfigured(job)
+	injectCustomCACertsIfConfigured(job, cfg.CustomCACertificate)
 
 	if err := ctrl.SetControllerReference(scan, job, r.Scheme); err != nil {
 		return err
@@ -246,8 +197,10 @@ func (r *ScanReconciler) startParser(scan *executionv1.Scan) error {
 
 	if err := r.Create(ctx, job); err != nil {
 		log.Error(err, "unable to create Job for Parser", "job", job)
+		r.Recorder.Eventf(scan, "Warning", "JobCreationFailed", "Failed to create parser job: %s", err)
 		return err
 	}
+	r.Recorder.Eventf(scan, "Normal", "ParserJobCreated", "Created parser job %s using ParseDefinition %s", job.Name, parseType)
 
 	scan.Status.State = executionv1.ScanStateParsing
 	if err := r.updateScanStatus(ctx, scan); err != nil {
@@ -270,6 +223,40 @@ func (r *ScanReconciler) checkIfParsingIsCompleted(scan *executionv1.Scan) error
 	switch status {
 	case completed:
 		r.Log.V(7).Info("Parsing is completed")
+		findings, invalidFindings, err := r.getFindings(scan, findingsFile)
+		if err != nil {
+			r.Log.Error(err, "Failed to download the findings written by the parser")
+			return err
+		}
+		if findings == nil && invalidFindings == nil {
+			invalidFindings = fmt.Errorf("%s doesn't exist", findingsFile)
+		}
+		if invalidFindings == nil {
+			// parsers must not suppress findings themselves, only FindingSuppressions do
+			invalidFindings = findingsv1.ValidateParsed(findings)
+		}
+		if invalidFindings != nil {
+			scan.Status.State = executionv1.ScanStateErrored
+			scan.Status.ErrorDescription = fmt.Sprintf("Parser wrote invalid findings: %s", invalidFindings)
+			r.Recorder.Event(scan, "Warning", "InvalidFindings", scan.Status.ErrorDescription)
+			if err := r.updateScanStatus(ctx, scan); err != nil {
+				r.Log.Error(err, "unable to update Scan status")
+				return err
+			}
+			return nil
+		}
+		if err := r.applyFindingSuppressions(ctx, scan, findings); err != nil {
+			r.Log.Error(err, "Failed to apply the FindingSuppressions", "scan", scan.Name)
+			return err
+		}
+		scan.Status.Findings = computeFindingStats(findings)
+		if err := r.diffFindingsWithPreviousScan(ctx, scan, findings); err != nil {
+			// the diff is informational, a failure to compute it shouldn't fail the scan
+			r.Log.Error(err, "Failed to compare the findings with the previous scan", "scan", scan.Name)
+			r.Recorder.Event(scan, "Warning", "FindingsDiffFailed", fmt.Sprintf("Failed to compare the findings with the previous scan: %s", err))
+		}
+
+		r.Recorder.Eventf(scan, "Normal", "ParsingCompleted", "Parser job completed successfully, identified %d findings", scan.Status.Findings.Count)
 		scan.Status.State = executionv1.ScanStateParseCompleted
 		if err := r.updateScanStatus(ctx, scan); err != nil {
 			r.Log.Error(err, "unable to update Scan status")
@@ -278,6 +265,7 @@ func (r *ScanReconciler) checkIfParsingIsCompleted(scan *executionv1.Scan) error
 	case failed:
 		scan.Status.State = executionv1.ScanStateErrored
 		scan.Status.ErrorDescription = "Failed to run the Parser. This is likely a Bug, we would like to know about. Please open up a Issue on GitHub."
+		r.Recorder.Event(scan, "Warning", "ParserJobFailed", scan.Status.ErrorDescription)
 		if err := r.updateScanStatus(ctx, scan); err != nil {
 			r.Log.Error(err, "unable to update Scan status")
 			return err
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// validatePodOverrides checks that the overrides are a json object which only sets allowed top level pod spec fields.
+func validatePodOverrides(overrides *runtime.RawExtension, allowedFields []string) error {
+	if overrides == nil || len(overrides.Raw) == 0 {
+		return nil
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(overrides.Raw, &patch); err != nil {
+		return fmt.Errorf("podOverrides must be an object: %w", err)
+	}
+
+	var forbiddenFields []string
+	for field := range patch {
+		if !containsString(allowedFields, field) {
+			forbiddenFields = append(forbiddenFields, field)
+		}
+	}
+	if len(forbiddenFields) > 0 {
+		sort.Strings(forbiddenFields)
+		return fmt.Errorf("podOverrides contains fields which are not allowed to be overridden: %s. Allowed fields are: %s", strings.Join(forbiddenFields, ", "), strings.Join(allowedFields, ", "))
+	}
+	return nil
+}
+
+// applyPodOverrides applies the overrides as a strategic merge patch onto the given pod spec.
+func applyPodOverrides(podSpec *corev1.PodSpec, overrides *runtime.RawExtension, allowedFields []string) error {
+	if overrides == nil || len(overrides.Raw) == 0 {
+		return nil
+	}
+	if err := validatePodOverrides(overrides, allowedFields); err != nil {
+		return err
+	}
+
+	original, err := json.Marshal(podSpec)
+	if err != nil {
+		return err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, overrides.Raw, corev1.PodSpec{})
+	if err != nil {
+		return fmt.Errorf("failed to apply podOverrides: %w", err)
+	}
+
+	var patchedPodSpec corev1.PodSpec
+	if err := json.Unmarshal(patched, &patchedPodSpec); err != nil {
+		return fmt.Errorf("failed to apply podOverrides: %w", err)
+	}
+	*podSpec = patchedPodSpec
+	return nil
+}
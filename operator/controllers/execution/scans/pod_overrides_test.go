@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("PodOverrides", func() {
+	defaultPodOverridesAllowedFields := config.Default().PodOverrides.AllowedFields
+
+	Context("validatePodOverrides", func() {
+		It("should accept missing overrides", func() {
+			Expect(validatePodOverrides(nil, defaultPodOverridesAllowedFields)).To(Succeed())
+		})
+		It("should accept allowed fields", func() {
+			overrides := &runtime.RawExtension{Raw: []byte(`{"priorityClassName":"high","imagePullSecrets":[{"name":"internal-registry"}]}`)}
+			Expect(validatePodOverrides(overrides, defaultPodOverridesAllowedFields)).To(Succeed())
+		})
+		It("should reject the service account and security context unless an admin allowed them", func() {
+			overrides := &runtime.RawExtension{Raw: []byte(`{"serviceAccountName":"admin","securityContext":{"runAsUser":0}}`)}
+			err := validatePodOverrides(overrides, defaultPodOverridesAllowedFields)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("securityContext, serviceAccountName"))
+
+			Expect(validatePodOverrides(overrides, []string{"securityContext", "serviceAccountName"})).To(Succeed())
+		})
+		It("should reject fields which are not on the allow list", func() {
+			overrides := &runtime.RawExtension{Raw: []byte(`{"hostNetwork":true,"priorityClassName":"high"}`)}
+			err := validatePodOverrides(overrides, defaultPodOverridesAllowedFields)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("hostNetwork"))
+			Expect(err.Error()).NotTo(ContainSubstring("high"))
+		})
+		It("should reject overrides which aren't an object", func() {
+			overrides := &runtime.RawExtension{Raw: []byte(`["priorityClassName"]`)}
+			Expect(validatePodOverrides(overrides, defaultPodOverridesAllowedFields)).NotTo(Succeed())
+		})
+	})
+
+	Context("applyPodOverrides", func() {
+		var podSpec corev1.PodSpec
+
+		BeforeEach(func() {
+			podSpec = corev1.PodSpec{
+				ServiceAccountName: "lurker",
+				ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "from-scantype"}},
+				Containers: []corev1.Container{
+					{Name: "nmap", Image: "securecodebox/scanner-nmap"},
+					{Name: "lurker", Image: "securecodebox/lurker"},
+				},
+			}
+		})
+
+		It("should not change the pod spec without overrides", func() {
+			expected := podSpec.DeepCopy()
+			Expect(applyPodOverrides(&podSpec, nil, defaultPodOverridesAllowedFields)).To(Succeed())
+			Expect(podSpec).To(Equal(*expected))
+		})
+
+		It("should override scalar fields and merge lists by their merge key", func() {
+			overrides := &runtime.RawExtension{Raw: []byte(`{
+				"serviceAccountName": "custom",
+				"priorityClassName": "scans",
+				"imagePullSecrets": [{"name": "from-scan"}],
+				"securityContext": {"runAsUser": 1000}
+			}`)}
+			Expect(applyPodOverrides(&podSpec, overrides, []string{"serviceAccountName", "priorityClassName", "imagePullSecrets", "securityContext"})).To(Succeed())
+
+			var runAsUser int64 = 1000
+			Expect(podSpec.ServiceAccountName).To(Equal("custom"))
+			Expect(podSpec.PriorityClassName).To(Equal("scans"))
+			Expect(podSpec.ImagePullSecrets).To(ConsistOf(
+				corev1.LocalObjectReference{Name: "from-scan"},
+				corev1.LocalObjectReference{Name: "from-scantype"},
+			))
+			Expect(podSpec.SecurityContext.RunAsUser).To(Equal(&runAsUser))
+			Expect(podSpec.Containers).To(HaveLen(2))
+		})
+
+		It("should refuse to apply fields which are not allowed", func() {
+			overrides := &runtime.RawExtension{Raw: []byte(`{"containers": [{"name": "nmap", "image": "evil"}]}`)}
+			Expect(applyPodOverrides(&podSpec, overrides, defaultPodOverridesAllowedFields)).NotTo(Succeed())
+			Expect(podSpec.Containers[0].Image).To(Equal("securecodebox/scanner-nmap"))
+		})
+	})
+})
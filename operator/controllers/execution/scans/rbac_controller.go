@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+)
+
+// RBACReconciler manages the ServiceAccounts, Roles and RoleBindings of the lurker, parser and hook jobs.
+// It creates them in every namespace with ScanTypes or Scans, repairs changes to them and removes them once the namespace has neither left.
+// Reconcile requests are keyed by the namespace, the name of the request is the name of the namespace.
+type RBACReconciler struct {
+	client.Client
+	// APIReader reads the existing ServiceAccounts, Roles and RoleBindings without the managed-by label, which aren't cached (see RBACCacheByObject)
+	APIReader client.Reader
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+}
+
+// Reconcile ensures the job ServiceAccounts of a namespace exist if the namespace is used for scans and removes them otherwise
+func (r *RBACReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	namespace := req.Name
+	log := r.Log.WithValues("namespace", namespace)
+
+	inUse, err := r.namespaceInUse(ctx, namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !inUse {
+		log.V(7).Info("Namespace has no ScanTypes and Scans, removing the ServiceAccounts of the jobs")
+		return ctrl.Result{}, r.deleteJobServiceAccounts(ctx, namespace)
+	}
+
+	for _, account := range jobServiceAccounts {
+		repaired, adopted, err := ensureJobServiceAccount(ctx, r.Client, r.APIReader, r.Scheme, namespace, account)
+		if err != nil {
+			log.Error(err, "Failed to reconcile the ServiceAccount of the job", "serviceAccountName", account.Name)
+			return ctrl.Result{}, err
+		}
+		for _, obj := range adopted {
+			kind := objectKind(obj)
+			log.Info("Adopted an existing RBAC object without the managed-by label", "kind", kind, "name", obj.GetName())
+			r.Recorder.Eventf(obj, "Normal", "Adopted", "Adopted the existing %s %s, it is managed by the secureCodeBox operator from now on", kind, obj.GetName())
+		}
+		for _, obj := range repaired {
+			kind := objectKind(obj)
+			log.Info("Repaired changes to a managed RBAC object", "kind", kind, "name", obj.GetName())
+			r.Recorder.Eventf(obj, "Warning", "DriftRepaired", "Reverted changes to the %s %s managed by the secureCodeBox operator", kind, obj.GetName())
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// namespaceInUse checks if the namespace has ScanTypes or Scans. Scans using ClusterScanTypes keep the ServiceAccounts of their jobs around as well.
+func (r *RBACReconciler) namespaceInUse(ctx context.Context, namespace string) (bool, error) {
+	var scanTypes executionv1.ScanTypeList
+	if err := r.List(ctx, &scanTypes, client.InNamespace(namespace), client.Limit(1)); err != nil {
+		return false, err
+	}
+	if len(scanTypes.Items) > 0 {
+		return true, nil
+	}
+
+	var scans executionv1.ScanList
+	if err := r.List(ctx, &scans, client.InNamespace(namespace), client.Limit(1)); err != nil {
+		return false, err
+	}
+	return len(scans.Items) > 0, nil
+}
+
+// deleteJobServiceAccounts deletes the ServiceAccounts, Roles and RoleBindings of the jobs in the namespace. Objects without the managed-by label aren't touched.
+func (r *RBACReconciler) deleteJobServiceAccounts(ctx context.Context, namespace string) error {
+	for _, account := range jobServiceAccounts {
+		for _, obj := range []client.Object{&rbacv1.RoleBinding{}, &rbacv1.Role{}, &corev1.ServiceAccount{}} {
+			if err := r.Get(ctx, types.NamespacedName{Name: account.Name, Namespace: namespace}, obj); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if !isManaged(obj) {
+				continue
+			}
+			r.Log.Info("Deleting RBAC object of a namespace without ScanTypes and Scans", "kind", objectKind(obj), "name", obj.GetName(), "namespace", namespace)
+			if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isManaged checks if the object is one of the job ServiceAccounts, Roles or RoleBindings managed by the operator
+func isManaged(obj client.Object) bool {
+	if obj.GetLabels()[managedByLabel] != managedByValue {
+		return false
+	}
+	for _, account := range jobServiceAccounts {
+		if obj.GetName() == account.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func objectKind(obj client.Object) string {
+	switch obj.(type) {
+	case *corev1.ServiceAccount:
+		return "ServiceAccount"
+	case *rbacv1.Role:
+		return "Role"
+	case *rbacv1.RoleBinding:
+		return "RoleBinding"
+	}
+	return ""
+}
+
+// RBACCacheByObject restricts the cache of ServiceAccounts, Roles and RoleBindings to the ones managed by the operator, instead of caching those of the whole cluster.
+// Used in the cache options of the manager.
+func RBACCacheByObject() map[client.Object]cache.ByObject {
+	managed := labels.SelectorFromSet(labels.Set{managedByLabel: managedByValue})
+	return map[client.Object]cache.ByObject{
+		&corev1.ServiceAccount{}: {Label: managed},
+		&rbacv1.Role{}:           {Label: managed},
+		&rbacv1.RoleBinding{}:    {Label: managed},
+	}
+}
+
+// namespaceRequest maps an object to the reconcile request of its namespace
+func namespaceRequest(ctx context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+// SetupWithManager sets up the controller and initializes every thing it needs
+func (r *RBACReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	managed := builder.WithPredicates(predicate.NewPredicateFuncs(isManaged))
+	// only the creation and deletion of scans changes whether the namespace is in use
+	createdOrDeleted := builder.WithPredicates(predicate.Funcs{
+		UpdateFunc: func(event.UpdateEvent) bool { return false },
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("rbac").
+		Watches(&executionv1.ScanType{}, handler.EnqueueRequestsFromMapFunc(namespaceRequest), createdOrDeleted).
+		Watches(&executionv1.Scan{}, handler.EnqueueRequestsFromMapFunc(namespaceRequest), createdOrDeleted).
+		Watches(&corev1.ServiceAccount{}, handler.EnqueueRequestsFromMapFunc(namespaceRequest), managed).
+		Watches(&rbacv1.Role{}, handler.EnqueueRequestsFromMapFunc(namespaceRequest), managed).
+		Watches(&rbacv1.RoleBinding{}, handler.EnqueueRequestsFromMapFunc(namespaceRequest), managed).
+		Complete(r)
+}
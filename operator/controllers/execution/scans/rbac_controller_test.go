@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("RBACReconciler", func() {
+	var (
+		r        *RBACReconciler
+		recorder *record.FakeRecorder
+		ctx      context.Context
+	)
+
+	setup := func(objects ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(rbacv1.AddToScheme(scheme)).To(Succeed())
+		recorder = record.NewFakeRecorder(10)
+		r = &RBACReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+			Log:      logr.Discard(),
+			Scheme:   scheme,
+			Recorder: recorder,
+		}
+	}
+
+	reconcileNamespace := func() {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	get := func(name string, obj client.Object) error {
+		return r.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, obj)
+	}
+
+	scanType := &executionv1.ScanType{ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default"}}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should create the ServiceAccounts, Roles and RoleBindings of the jobs in namespaces with ScanTypes", func() {
+		setup(scanType.DeepCopy())
+		reconcileNamespace()
+
+		for _, name := range []string{"lurker", "parser", "scan-completion-hook"} {
+			var serviceAccount corev1.ServiceAccount
+			Expect(get(name, &serviceAccount)).To(Succeed())
+			Expect(serviceAccount.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "securecodebox"))
+
+			var role rbacv1.Role
+			Expect(get(name, &role)).To(Succeed())
+			Expect(role.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "securecodebox"))
+			Expect(metav1.GetControllerOf(&role).Name).To(Equal(name))
+
+			var roleBinding rbacv1.RoleBinding
+			Expect(get(name, &roleBinding)).To(Succeed())
+			Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{{Kind: "ServiceAccount", Name: name, Namespace: "default"}}))
+			Expect(roleBinding.RoleRef.Name).To(Equal(name))
+			Expect(metav1.GetControllerOf(&roleBinding).Kind).To(Equal("ServiceAccount"))
+		}
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should still allow parsers and hooks built with older SDKs to patch the scan status", func() {
+		setup(scanType.DeepCopy())
+		reconcileNamespace()
+
+		for _, name := range []string{"parser", "scan-completion-hook"} {
+			var role rbacv1.Role
+			Expect(get(name, &role)).To(Succeed())
+			Expect(role.Rules).To(ContainElement(rbacv1.PolicyRule{APIGroups: []string{"execution.securecodebox.io"}, Resources: []string{"scans/status"}, Verbs: []string{"get", "patch"}}))
+		}
+	})
+
+	It("should repair changed Roles and RoleBindings", func() {
+		setup(scanType.DeepCopy())
+		reconcileNamespace()
+
+		var role rbacv1.Role
+		Expect(get("lurker", &role)).To(Succeed())
+		role.Rules = append(role.Rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}})
+		Expect(r.Update(ctx, &role)).To(Succeed())
+
+		var roleBinding rbacv1.RoleBinding
+		Expect(get("parser", &roleBinding)).To(Succeed())
+		roleBinding.Subjects = append(roleBinding.Subjects, rbacv1.Subject{Kind: "ServiceAccount", Name: "default", Namespace: "default"})
+		Expect(r.Update(ctx, &roleBinding)).To(Succeed())
+
+		reconcileNamespace()
+
+		Expect(get("lurker", &role)).To(Succeed())
+		Expect(role.Rules).To(Equal(lurkerServiceAccount.Rules))
+		Expect(get("parser", &roleBinding)).To(Succeed())
+		Expect(roleBinding.Subjects).To(HaveLen(1))
+		Expect(recorder.Events).To(Receive(Equal("Warning DriftRepaired Reverted changes to the Role lurker managed by the secureCodeBox operator")))
+		Expect(recorder.Events).To(Receive(Equal("Warning DriftRepaired Reverted changes to the RoleBinding parser managed by the secureCodeBox operator")))
+	})
+
+	It("should adopt existing objects without the managed-by label, which aren't cached", func() {
+		setup(scanType.DeepCopy(),
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "lurker", Namespace: "default"}},
+			&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "lurker", Namespace: "default"}, Rules: lurkerServiceAccount.Rules},
+		)
+		// the cache of the manager only contains the objects with the managed-by label
+		r.APIReader = r.Client
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := c.Get(ctx, key, obj, opts...); err != nil {
+					return err
+				}
+				if obj.GetLabels()["app.kubernetes.io/managed-by"] != "securecodebox" {
+					return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+				}
+				return nil
+			},
+		})
+		reconcileNamespace()
+
+		var serviceAccount corev1.ServiceAccount
+		Expect(get("lurker", &serviceAccount)).To(Succeed())
+		var role rbacv1.Role
+		Expect(get("lurker", &role)).To(Succeed())
+		Expect(metav1.GetControllerOf(&role).Name).To(Equal("lurker"))
+		Expect(recorder.Events).To(Receive(Equal("Normal Adopted Adopted the existing ServiceAccount lurker, it is managed by the secureCodeBox operator from now on")))
+		Expect(recorder.Events).To(Receive(Equal("Normal Adopted Adopted the existing Role lurker, it is managed by the secureCodeBox operator from now on")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should recreate RoleBindings referencing another role", func() {
+		setup(scanType.DeepCopy(), &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "lurker", Namespace: "default"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "lurker"}},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin", APIGroup: "rbac.authorization.k8s.io"},
+		})
+		reconcileNamespace()
+
+		var roleBinding rbacv1.RoleBinding
+		Expect(get("lurker", &roleBinding)).To(Succeed())
+		Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{Kind: "Role", Name: "lurker", APIGroup: "rbac.authorization.k8s.io"}))
+		Expect(roleBinding.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "securecodebox"))
+	})
+
+	It("should remove the managed objects once the namespace has no ScanTypes and Scans left", func() {
+		unmanaged := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "scan-completion-hook", Namespace: "default"}}
+		setup(
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "lurker", Namespace: "default", Labels: map[string]string{"app.kubernetes.io/managed-by": "securecodebox"}}},
+			&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "lurker", Namespace: "default", Labels: map[string]string{"app.kubernetes.io/managed-by": "securecodebox"}}},
+			unmanaged,
+		)
+		reconcileNamespace()
+
+		Expect(apierrors.IsNotFound(get("lurker", &corev1.ServiceAccount{}))).To(BeTrue())
+		Expect(apierrors.IsNotFound(get("lurker", &rbacv1.Role{}))).To(BeTrue())
+		Expect(get("scan-completion-hook", &corev1.ServiceAccount{})).To(Succeed())
+	})
+
+	It("should keep the objects while the namespace has Scans using ClusterScanTypes", func() {
+		setup(&executionv1.Scan{ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default"}})
+		reconcileNamespace()
+
+		Expect(get("lurker", &corev1.ServiceAccount{})).To(Succeed())
+	})
+
+	It("should not create the objects for scans if managing them is disabled", func() {
+		setup()
+		cfg := config.Default()
+		cfg.ManageJobRBAC = false
+		scanReconciler := &ScanReconciler{Client: r.Client, Log: logr.Discard(), Config: cfg}
+
+		Expect(scanReconciler.ensureServiceAccountExists(ctx, "default", lurkerServiceAccount)).To(Succeed())
+		Expect(apierrors.IsNotFound(get("lurker", &corev1.ServiceAccount{}))).To(BeTrue())
+
+		scanReconciler.Config.ManageJobRBAC = true
+		Expect(scanReconciler.ensureServiceAccountExists(ctx, "default", lurkerServiceAccount)).To(Succeed())
+		Expect(get("lurker", &corev1.ServiceAccount{})).To(Succeed())
+	})
+})
@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	utils "github.com/secureCodeBox/secureCodeBox/operator/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RerunHooksAnnotation can be set on finished scans to run hooks again.
+// The value is a comma separated list of hook names, or "*" to rerun all hooks which weren't skipped.
+// Hooks which weren't part of the scan yet (e.g. newly installed persistence hooks) are added to the scan.
+const RerunHooksAnnotation = "securecodebox.io/rerun-hooks"
+
+// shouldRerunHooks checks if the scan is finished and requested a rerun of its hooks.
+// Scans which are still running keep the annotation until they are finished.
+func shouldRerunHooks(scan *executionv1.Scan) bool {
+	if _, ok := scan.ObjectMeta.Annotations[RerunHooksAnnotation]; !ok {
+		return false
+	}
+	return scan.Status.State == executionv1.ScanStateDone || scan.Status.State == executionv1.ScanStateErrored
+}
+
+// rerunHooksStarted checks if the rerun requested by the RerunHooksAnnotation was already started, but the annotation couldn't be removed afterwards.
+func rerunHooksStarted(scan *executionv1.Scan) bool {
+	requestedHooks, ok := scan.ObjectMeta.Annotations[RerunHooksAnnotation]
+	return ok && scan.Status.State == executionv1.ScanStateHookProcessing && scan.Status.RerunHooksRequest == requestedHooks
+}
+
+// rerunHooks resets the hooks selected by the RerunHooksAnnotation to pending and moves the scan back to HookProcessing.
+// The annotation is only removed once the reset hooks are persisted, so that the request isn't lost if updating the status fails.
+func (r *ScanReconciler) rerunHooks(scan *executionv1.Scan) error {
+	ctx := context.Background()
+	requestedHooks := scan.ObjectMeta.Annotations[RerunHooksAnnotation]
+
+	if scan.Status.State == executionv1.ScanStateErrored && len(scan.Status.OrderedHookStatuses) == 0 {
+		r.Recorder.Event(scan, "Warning", "RerunHooksFailed", "Hooks can't be rerun as the scan failed before its hooks were started")
+		return r.removeRerunHooksAnnotation(scan)
+	}
+
+	hookStatuses, err := r.resetHookStatusesForRerun(scan, requestedHooks)
+	if err != nil {
+		return err
+	}
+	if len(hookStatuses) == 0 {
+		r.Recorder.Eventf(scan, "Warning", "RerunHooksFailed", "None of the requested hooks '%s' could be found", requestedHooks)
+		return r.removeRerunHooksAnnotation(scan)
+	}
+
+	var allHookStatuses []*executionv1.HookStatus
+	for _, group := range scan.Status.OrderedHookStatuses {
+		allHookStatuses = append(allHookStatuses, group...)
+	}
+	scan.Status.OrderedHookStatuses = utils.FromUnorderedList(allHookStatuses)
+	if err := r.resetFilesForRerun(scan, hookStatuses); err != nil {
+		r.Log.Error(err, "Unable to reset the findings for the rerun of the hooks", "scan", scan.Name)
+		return err
+	}
+	scan.Status.State = executionv1.ScanStateHookProcessing
+	scan.Status.ErrorDescription = ""
+	scan.Status.FailedHooks = nil
+	scan.Status.FinishedAt = nil
+	scan.Status.RerunHooksRequest = requestedHooks
+
+	if err := r.updateScanStatus(ctx, scan); err != nil {
+		return err
+	}
+	r.Recorder.Eventf(scan, "Normal", "RerunHooks", "Rerunning hooks %s", strings.Join(hookStatuses, ", "))
+	return r.removeRerunHooksAnnotation(scan)
+}
+
+// removeRerunHooksAnnotation removes the RerunHooksAnnotation once its request was handled
+func (r *ScanReconciler) removeRerunHooksAnnotation(scan *executionv1.Scan) error {
+	delete(scan.ObjectMeta.Annotations, RerunHooksAnnotation)
+	if err := r.Update(context.Background(), scan); err != nil {
+		r.Log.Error(err, "Unable to remove rerun-hooks annotation from scan")
+		return err
+	}
+	return nil
+}
+
+// resetHookStatusesForRerun resets the requested hooks to pending and adds hooks which aren't part of the scan yet.
+// Returns the names of all hooks which will be rerun.
+func (r *ScanReconciler) resetHookStatusesForRerun(scan *executionv1.Scan, requestedHooks string) ([]string, error) {
+	existingHooks := map[string]*executionv1.HookStatus{}
+	for _, group := range scan.Status.OrderedHookStatuses {
+		for _, status := range group {
+			existingHooks[status.HookName] = status
+		}
+	}
+
+	var hookNames []string
+	if strings.TrimSpace(requestedHooks) == "*" {
+		for _, group := range scan.Status.OrderedHookStatuses {
+			for _, status := range group {
+				if status.State != executionv1.Skipped {
+					hookNames = append(hookNames, status.HookName)
+				}
+			}
+		}
+	} else {
+		hookNames = utils.SplitCommaSeparatedList(requestedHooks)
+	}
+
+	var rerunHooks []string
+	for _, hookName := range hookNames {
+		status, ok := existingHooks[hookName]
+		if !ok {
+			newStatus, err := r.getHookStatusForRerun(scan, hookName)
+			if err != nil {
+				return nil, err
+			}
+			if newStatus == nil {
+				r.Recorder.Eventf(scan, "Warning", "RerunHooksFailed", "Hook %s can't be rerun as it doesn't exist", hookName)
+				continue
+			}
+			// The new hook gets sorted into the existing hook groups by its priority
+			scan.Status.OrderedHookStatuses = append(scan.Status.OrderedHookStatuses, []*executionv1.HookStatus{newStatus})
+			existingHooks[hookName] = newStatus
+			status = newStatus
+		}
+
+		status.State = executionv1.Pending
+		status.JobName = ""
+		status.Retries = 0
+		status.RetryAfter = nil
+		status.Reruns++
+		rerunHooks = append(rerunHooks, hookName)
+	}
+	return rerunHooks, nil
+}
+
+// getHookStatusForRerun returns a pending hook status for a hook which wasn't part of the scan yet, or nil if the hook doesn't exist.
+func (r *ScanReconciler) getHookStatusForRerun(scan *executionv1.Scan, hookName string) (*executionv1.HookStatus, error) {
+	ctx := context.Background()
+	var hookStatuses []*executionv1.HookStatus
+
+	if scan.Spec.ResourceMode == nil || *scan.Spec.ResourceMode == executionv1.NamespaceLocal {
+		var hook executionv1.ScanCompletionHook
+		if err := r.Get(ctx, types.NamespacedName{Name: hookName, Namespace: scan.Namespace}, &hook); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get ScanCompletionHook '%s': %w", hookName, err)
+		}
+		hookStatuses = utils.MapHooksToHookStatus([]executionv1.ScanCompletionHook{hook})
+	} else {
+		var clusterHook executionv1.ClusterScanCompletionHook
+		if err := r.Get(ctx, types.NamespacedName{Name: hookName}, &clusterHook); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get ClusterScanCompletionHook '%s': %w", hookName, err)
+		}
+		hookStatuses = utils.MapClusterHooksToHookStatus([]executionv1.ClusterScanCompletionHook{clusterHook})
+	}
+	return hookStatuses[0], nil
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	"context"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Rerun of ReadAndWrite hooks", func() {
+	var (
+		r      *ScanReconciler
+		scan   *executionv1.Scan
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+
+		var minioClient *minio.Client
+		server, _, minioClient = newFakeS3(map[string][]byte{
+			"scan-6b8c1a32/findings.json":           openPortFindings(findingsv1.SeverityInformational, findingsv1.SeverityInformational),
+			"scan-6b8c1a32/findings.enrich.json":    openPortFindings(findingsv1.SeverityHigh, findingsv1.SeverityInformational),
+			"scan-6b8c1a32/findings.cleanup.json":   openPortFindings(findingsv1.SeverityHigh),
+			"scan-6b8c1a32/nmap-results.enrich.xml": []byte(`<nmaprun/>`),
+		})
+
+		cfg := config.Default()
+		cfg.S3.Bucket = "securecodebox"
+		scan = &executionv1.Scan{
+			ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default", UID: "6b8c1a32"},
+			Status: executionv1.ScanStatus{
+				State:                executionv1.ScanStateDone,
+				RawResultFile:        "nmap-results.xml",
+				CurrentFindingsFile:  "findings.cleanup.json",
+				CurrentRawResultFile: "nmap-results.enrich.xml",
+				Findings:             executionv1.FindingStats{Count: 1},
+				OrderedHookStatuses: [][]*executionv1.HookStatus{
+					{{HookName: "enrich", Type: executionv1.ReadAndWrite, State: executionv1.Completed, FindingsFile: "findings.enrich.json", RawResultFile: "nmap-results.enrich.xml"}},
+					{{HookName: "cleanup", Type: executionv1.ReadAndWrite, State: executionv1.Completed, FindingsFile: "findings.cleanup.json"}},
+					{{HookName: "slack", Type: executionv1.ReadOnly, State: executionv1.Completed}},
+				},
+			},
+		}
+		r = &ScanReconciler{
+			Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(scan).WithStatusSubresource(scan).Build(),
+			Log:         logr.Discard(),
+			Recorder:    record.NewFakeRecorder(10),
+			Config:      cfg,
+			MinioClient: minioClient,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should reset the findings to the version written before the rerun hook", func() {
+		scan.ObjectMeta.Annotations = map[string]string{RerunHooksAnnotation: "cleanup"}
+		Expect(r.rerunHooks(scan)).To(Succeed())
+
+		Expect(scan.Status.CurrentFindingsFile).To(Equal("findings.enrich.json"))
+		Expect(scan.Status.CurrentRawResultFile).To(Equal("nmap-results.enrich.xml"))
+		Expect(scan.Status.Findings.Count).To(BeEquivalentTo(2))
+		Expect(scan.Status.Findings.FindingSeverities.High).To(BeEquivalentTo(1))
+		Expect(scan.Status.FindingDownloadLink).To(ContainSubstring("/scan-6b8c1a32/findings.enrich.json?"))
+		Expect(scan.Status.OrderedHookStatuses[1][0].FindingsFile).To(BeEmpty())
+		Expect(scan.Status.OrderedHookStatuses[1][0].State).To(Equal(executionv1.Pending))
+	})
+
+	It("should reset the findings and raw results written by the parser and scanner if the first hook is rerun", func() {
+		scan.ObjectMeta.Annotations = map[string]string{RerunHooksAnnotation: "*"}
+		Expect(r.rerunHooks(scan)).To(Succeed())
+
+		Expect(currentFindingsFile(scan)).To(Equal("findings.json"))
+		Expect(currentRawResultFile(scan)).To(Equal("nmap-results.xml"))
+		Expect(scan.Status.Findings.Count).To(BeEquivalentTo(2))
+		Expect(scan.Status.Findings.FindingSeverities.High).To(BeEquivalentTo(0))
+		Expect(scan.Status.RawResultDownloadLink).To(ContainSubstring("/scan-6b8c1a32/nmap-results.xml?"))
+		Expect(scan.Status.OrderedHookStatuses[0][0].FindingsFile).To(BeEmpty())
+		Expect(scan.Status.OrderedHookStatuses[0][0].RawResultFile).To(BeEmpty())
+	})
+
+	It("should keep the current findings if only ReadOnly hooks are rerun", func() {
+		scan.ObjectMeta.Annotations = map[string]string{RerunHooksAnnotation: "slack"}
+		Expect(r.rerunHooks(scan)).To(Succeed())
+
+		Expect(scan.Status.CurrentFindingsFile).To(Equal("findings.cleanup.json"))
+		Expect(scan.Status.Findings.Count).To(BeEquivalentTo(1))
+
+		var persisted executionv1.Scan
+		Expect(r.Get(context.Background(), client.ObjectKeyFromObject(scan), &persisted)).To(Succeed())
+		Expect(persisted.Status.CurrentFindingsFile).To(Equal("findings.cleanup.json"))
+	})
+})
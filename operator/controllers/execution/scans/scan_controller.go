@@ -8,9 +8,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
-	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -20,20 +22,42 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
 )
 
 // ScanReconciler reconciles a Scan object
 type ScanReconciler struct {
 	client.Client
+	// APIReader reads the existing job ServiceAccounts, Roles and RoleBindings without the managed-by label, which aren't cached
+	APIReader   client.Reader
 	Log         logr.Logger
 	Scheme      *runtime.Scheme
-	MinioClient minio.Client
+	MinioClient *minio.Client
+	Recorder    record.EventRecorder
+	Config      config.OperatorConfig
+	// HTTPClient is used to send the requests of webhook hooks, defaults to a client which doesn't follow redirects
+	HTTPClient *http.Client
+
+	// webhookDeliveries are the *webhookDelivery of the webhook requests sent in the background, by scan uid and hook name
+	webhookDeliveries sync.Map
+	// webhookEvents triggers the reconciliation of a scan once a webhook request sent in the background finished
+	webhookEvents chan event.GenericEvent
+
+	configMutex sync.RWMutex
+	// s3Error is set if the s3 client couldn't be created on startup
+	s3Error error
+	// configurationError is the last ConfigurationError a scan failed with, reset on config changes and once scans can be processed again
+	configurationError error
 }
 
 var (
@@ -48,19 +72,26 @@ var s3StorageFinalizer = "s3.storage.securecodebox.io/scan-files"
 // Legacy finalizer name for backward compatibility during migration
 var s3StorageFinalizerLegacy = "s3.storage.securecodebox.io"
 
+// ArchiveFilesAnnotation can be set on scans to move their files to the archive prefix of the s3 storage when the scan gets deleted, instead of deleting them.
+// It is set by the ScheduledScan controller for scans deleted by a historyRetention with archive enabled.
+const ArchiveFilesAnnotation = "securecodebox.io/archive-files"
+
 // +kubebuilder:rbac:groups=execution.securecodebox.io,resources=scans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=execution.securecodebox.io,resources=scheduledscans,verbs=get
 // +kubebuilder:rbac:groups=execution.securecodebox.io,resources=scans/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=execution.securecodebox.io,resources=scantypes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=execution.securecodebox.io,resources=parsedefinitions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=execution.securecodebox.io,resources=scancompletionhooks,verbs=get;list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 // Permissions needed to create service accounts for lurker, parser and scanCompletionHooks
 
 // Pod permission are required to grant these permission to service accounts
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get
-// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;watch;list;create
-// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;watch;list;create;update
-// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;watch;list;create
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;watch;list;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;watch;list;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;watch;list;create;update;patch;delete
 
 // Reconcile compares the scan object against the state of the cluster and updates both if needed
 func (r *ScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -82,6 +113,7 @@ func (r *ScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 
 	log.V(5).Info("Scan Found", "Type", scan.Spec.ScanType, "State", scan.Status.State)
+	r.recheckConfiguration(&scan)
 
 	// Check if the scan is suspended. If so, skip reconciliation unless the scan is in a terminal state
 	// where TTL-based cleanup should still work.
@@ -107,6 +139,16 @@ func (r *ScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		}
 	}
 
+	if shouldRerunHooks(&scan) {
+		if err := r.rerunHooks(&scan); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if rerunHooksStarted(&scan) {
+		if err := r.removeRerunHooksAnnotation(&scan); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	var err error
 	switch scan.Status.State {
 	case executionv1.ScanStateInit:
@@ -137,6 +179,10 @@ func (r *ScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		err = r.migrateHookStatus(&scan)
 	}
 
+	if configErr := asConfigurationError(err); configErr != nil {
+		err = r.handleConfigurationError(ctx, &scan, configErr)
+	}
+
 	if scan.Spec.TTLSecondsAfterFinished != nil && (scan.Status.State == executionv1.ScanStateDone || scan.Status.State == executionv1.ScanStateErrored) {
 		return ctrl.Result{
 			Requeue:      true,
@@ -146,6 +192,16 @@ func (r *ScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if scan.Status.State == executionv1.ScanStateHookProcessing {
+		retryIn, ok := nextHookRetry(&scan)
+		if r.hasWebhookDeliveries(&scan) && (!ok || retryIn > webhookRequeueInterval) {
+			// in case the event for the finished webhook request was dropped
+			retryIn, ok = webhookRequeueInterval, true
+		}
+		if ok {
+			return ctrl.Result{RequeueAfter: max(retryIn, time.Second)}, nil
+		}
+	}
 
 	return ctrl.Result{}, nil
 }
@@ -160,9 +216,16 @@ func (r *ScanReconciler) handleFinalizer(scan *executionv1.Scan) error {
 
 	// Check if we have the s3 storage finalizer
 	if containsString(scan.ObjectMeta.Finalizers, s3StorageFinalizer) {
-		if err := r.cleanupS3Files(scan); err != nil {
+		cleanedUp, err := r.cleanupS3FilesOfDeletedScan(scan)
+		if err != nil {
+			r.Recorder.Eventf(scan, "Warning", "FileCleanupFailed", "Failed to delete the files of the Scan from the s3 storage: %s", err)
 			return err
 		}
+		if cleanedUp && shouldArchiveFiles(scan) {
+			r.Recorder.Eventf(scan, "Normal", "FilesArchived", "Moved the files of the Scan to the archive prefix %q of the s3 storage", r.getConfig().S3.ArchivePrefix)
+		} else if cleanedUp {
+			r.Recorder.Event(scan, "Normal", "FilesDeleted", "Deleted the files of the Scan from the s3 storage")
+		}
 
 		// Remove the s3 storage finalizer
 		scan.ObjectMeta.Finalizers = removeString(scan.ObjectMeta.Finalizers, s3StorageFinalizer)
@@ -183,7 +246,7 @@ func (r *ScanReconciler) migrateFinalizer(scan *executionv1.Scan) error {
 	r.Log.Info("Migrating legacy finalizer", "scan", scan.Name, "namespace", scan.Namespace, "legacy", s3StorageFinalizerLegacy, "current", s3StorageFinalizer)
 
 	// Clean up S3 files using legacy finalizer logic
-	if err := r.cleanupS3Files(scan); err != nil {
+	if _, err := r.cleanupS3FilesOfDeletedScan(scan); err != nil {
 		return err
 	}
 
@@ -196,35 +259,110 @@ func (r *ScanReconciler) migrateFinalizer(scan *executionv1.Scan) error {
 	return nil
 }
 
-// cleanupS3Files removes scan-related files from S3 storage
+// cleanupS3FilesOfDeletedScan removes the files of a deleted scan from S3 storage.
+// If the files can't be removed because the operator is misconfigured, retrying won't help until the config is fixed.
+// In this case the cleanup is skipped with a warning event so that the deletion of the scan isn't blocked, and false is returned.
+func (r *ScanReconciler) cleanupS3FilesOfDeletedScan(scan *executionv1.Scan) (bool, error) {
+	err := r.cleanupS3Files(scan)
+	configErr := asConfigurationError(err)
+	if configErr == nil {
+		return err == nil, err
+	}
+	if err := r.handleConfigurationError(context.Background(), scan, configErr); err != nil {
+		return false, err
+	}
+	r.Recorder.Eventf(scan, "Warning", "FileCleanupSkipped", "Skipped deleting the files of the Scan from the s3 storage as the operator is misconfigured, the files have to be removed manually: %s", configErr)
+	return false, nil
+}
+
+// cleanupS3Files removes scan-related files from S3 storage.
+// Files of scans with the ArchiveFilesAnnotation are copied to the archive prefix before they are removed.
 func (r *ScanReconciler) cleanupS3Files(scan *executionv1.Scan) error {
-	bucketName := os.Getenv("S3_BUCKET")
 	r.Log.V(3).Info("Deleting External Files from FileStorage", "ScanUID", scan.UID)
 
-	// Clean up raw results file
-	rawResultUrl := getPresignedUrlPath(*scan, scan.Status.RawResultFile)
-	err := r.MinioClient.RemoveObject(context.Background(), bucketName, rawResultUrl, minio.RemoveObjectOptions{})
-	if err != nil && err.Error() != errNotFound {
+	if err := r.checkS3Connection(); err != nil {
 		return err
 	}
 
-	// Clean up findings.json file
-	findingsJsonUrl := getPresignedUrlPath(*scan, "findings.json")
-	err = r.MinioClient.RemoveObject(context.Background(), bucketName, findingsJsonUrl, minio.RemoveObjectOptions{})
+	// raw results file and findings.json file
+	files := []string{scan.Status.RawResultFile, findingsFile}
+
+	// versions of the files written by ReadAndWrite hooks, the suppressed findings and the findings diff
+	if scan.Status.FindingsDiffDownloadLink != "" {
+		files = append(files, findingsDiffFile)
+	}
+	if len(scan.Status.SuppressedFindings) > 0 {
+		files = append(files, suppressedFindingsFile)
+	}
+	for _, hookGroup := range scan.Status.OrderedHookStatuses {
+		for _, hookStatus := range hookGroup {
+			if hookStatus.Type == executionv1.ReadAndWrite {
+				files = append(files, versionedFileName(scan.Status.RawResultFile, hookStatus.HookName), versionedFileName(findingsFile, hookStatus.HookName))
+			}
+		}
+	}
+
+	archive := shouldArchiveFiles(scan)
+	for _, filename := range files {
+		if err := r.removeS3File(scan, filename, archive); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeS3File removes a file of the scan from S3 storage, copying it to the archive prefix first if requested. Missing files are ignored
+func (r *ScanReconciler) removeS3File(scan *executionv1.Scan, filename string, archive bool) error {
+	s3Config := r.getConfig().S3
+
+	fileUrl, err := getPresignedUrlPath(s3Config.URLTemplate, *scan, filename)
+	if err != nil {
+		return err
+	}
+	if archive {
+		_, err = r.MinioClient.CopyObject(
+			context.Background(),
+			minio.CopyDestOptions{Bucket: s3Config.Bucket, Object: archivedFilePath(s3Config.ArchivePrefix, fileUrl)},
+			minio.CopySrcOptions{Bucket: s3Config.Bucket, Object: fileUrl},
+		)
+		if err != nil {
+			if err.Error() == errNotFound {
+				return nil
+			}
+			return err
+		}
+	}
+	err = r.MinioClient.RemoveObject(context.Background(), s3Config.Bucket, fileUrl, minio.RemoveObjectOptions{})
 	if err != nil && err.Error() != errNotFound {
 		return err
 	}
-
 	return nil
 }
 
+// shouldArchiveFiles checks if the files of the scan should be moved to the archive prefix instead of being deleted
+func shouldArchiveFiles(scan *executionv1.Scan) bool {
+	return scan.ObjectMeta.Annotations[ArchiveFilesAnnotation] == "true"
+}
+
+// archivedFilePath returns the path of an archived file, which is the original path below the archive prefix
+func archivedFilePath(archivePrefix string, filePath string) string {
+	return strings.TrimSuffix(archivePrefix, "/") + "/" + strings.TrimPrefix(filePath, "/")
+}
+
 // PresignedGetURL returns a presigned URL from the s3 (or compatible) serice.
 func (r *ScanReconciler) PresignedGetURL(scan executionv1.Scan, filename string, duration time.Duration) (string, error) {
-	bucketName := os.Getenv("S3_BUCKET")
+	s3Config := r.getConfig().S3
 
-	fileUrl := getPresignedUrlPath(scan, filename)
+	if err := r.checkS3Connection(); err != nil {
+		return "", err
+	}
+	fileUrl, err := getPresignedUrlPath(s3Config.URLTemplate, scan, filename)
+	if err != nil {
+		return "", err
+	}
 	reqParams := make(url.Values)
-	rawResultDownloadURL, err := r.MinioClient.PresignedGetObject(context.Background(), bucketName, fileUrl, duration, reqParams)
+	rawResultDownloadURL, err := r.MinioClient.PresignedGetObject(context.Background(), s3Config.Bucket, fileUrl, duration, reqParams)
 	if err != nil {
 		r.Log.Error(err, "Could not get presigned url from s3 or compatible storage provider")
 		return "", err
@@ -234,10 +372,16 @@ func (r *ScanReconciler) PresignedGetURL(scan executionv1.Scan, filename string,
 
 // PresignedPutURL returns a presigned URL from the s3 (or compatible) serice.
 func (r *ScanReconciler) PresignedPutURL(scan executionv1.Scan, filename string, duration time.Duration) (string, error) {
-	bucketName := os.Getenv("S3_BUCKET")
-	fileUrl := getPresignedUrlPath(scan, filename)
+	s3Config := r.getConfig().S3
+	if err := r.checkS3Connection(); err != nil {
+		return "", err
+	}
+	fileUrl, err := getPresignedUrlPath(s3Config.URLTemplate, scan, filename)
+	if err != nil {
+		return "", err
+	}
 
-	rawResultDownloadURL, err := r.MinioClient.PresignedPutObject(context.Background(), bucketName, fileUrl, duration)
+	rawResultDownloadURL, err := r.MinioClient.PresignedPutObject(context.Background(), s3Config.Bucket, fileUrl, duration)
 	if err != nil {
 		r.Log.Error(err, "Could not get presigned url from s3 or compatible storage provider")
 		return "", err
@@ -247,10 +391,16 @@ func (r *ScanReconciler) PresignedPutURL(scan executionv1.Scan, filename string,
 
 // PresignedHeadURL returns a presigned URL from the s3 (or compatible) serice.
 func (r *ScanReconciler) PresignedHeadURL(scan executionv1.Scan, filename string, duration time.Duration) (string, error) {
-	bucketName := os.Getenv("S3_BUCKET")
-	fileUrl := getPresignedUrlPath(scan, filename)
+	s3Config := r.getConfig().S3
+	if err := r.checkS3Connection(); err != nil {
+		return "", err
+	}
+	fileUrl, err := getPresignedUrlPath(s3Config.URLTemplate, scan, filename)
+	if err != nil {
+		return "", err
+	}
 
-	rawResultHeadURL, err := r.MinioClient.PresignedHeadObject(context.Background(), bucketName, fileUrl, duration, nil)
+	rawResultHeadURL, err := r.MinioClient.PresignedHeadObject(context.Background(), s3Config.Bucket, fileUrl, duration, nil)
 	if err != nil {
 		r.Log.Error(err, "Could not get presigned url from s3 or compatible storage provider")
 		return "", err
@@ -258,32 +408,19 @@ func (r *ScanReconciler) PresignedHeadURL(scan executionv1.Scan, filename string
 	return rawResultHeadURL.String(), nil
 }
 
-func (r *ScanReconciler) initS3Connection() *minio.Client {
-	endpoint := os.Getenv("S3_ENDPOINT")
-	if os.Getenv("S3_PORT") != "" {
-		endpoint = fmt.Sprintf("%s:%s", endpoint, os.Getenv("S3_PORT"))
-	}
-	// Only deactivate useSSL when explicitly set to false
-	useSSL := true
-	if os.Getenv("S3_USE_SSL") == "false" {
-		useSSL = false
+func (r *ScanReconciler) initS3Connection() (*minio.Client, error) {
+	s3Config := r.getConfig().S3
+	endpoint := s3Config.Endpoint
+	if s3Config.Port != 0 {
+		endpoint = fmt.Sprintf("%s:%d", endpoint, s3Config.Port)
 	}
 
 	var creds *credentials.Credentials
 
 	// todo(v6): remove support for authType = "aws-irsa" and only support "aws-iam": https://github.com/secureCodeBox/secureCodeBox/issues/3327
-	if authType, ok := os.LookupEnv("S3_AUTH_TYPE"); ok && (strings.ToLower(authType) == "aws-irsa" || strings.ToLower(authType) == "aws-iam") {
-		stsEndpoint := ""
-		// todo(v6): remove support for S3_AWS_STS_ENDPOINT env var and only support S3_AWS_IRSA_STS_ENDPOINT: https://github.com/secureCodeBox/secureCodeBox/issues/3327
-		if configuredStsEndpoint, ok := os.LookupEnv("S3_AWS_IRSA_STS_ENDPOINT"); ok {
-			stsEndpoint = configuredStsEndpoint
-		}
-		if configuredStsEndpoint, ok := os.LookupEnv("S3_AWS_STS_ENDPOINT"); ok {
-			stsEndpoint = configuredStsEndpoint
-		}
-
-		r.Log.Info("Using AWS IAM ServiceAccount Binding for S3 Authentication (IRSA or EKS Pod Identity)", "sts", stsEndpoint)
-		creds = credentials.NewIAM(stsEndpoint)
+	if s3Config.AuthType == "aws-irsa" || s3Config.AuthType == "aws-iam" {
+		r.Log.Info("Using AWS IAM ServiceAccount Binding for S3 Authentication (IRSA or EKS Pod Identity)", "sts", s3Config.AwsStsEndpoint)
+		creds = credentials.NewIAM(s3Config.AwsStsEndpoint)
 	} else {
 		creds = credentials.NewEnvMinio()
 	}
@@ -291,14 +428,45 @@ func (r *ScanReconciler) initS3Connection() *minio.Client {
 	// Initialize minio client object.
 	minioClient, err := minio.New(endpoint, &minio.Options{
 		Creds:  creds,
-		Secure: useSSL,
+		Secure: s3Config.UseSSL,
 	})
 	if err != nil {
-		r.Log.Error(err, "Could not create minio client to communicate with s3 or compatible storage provider")
-		panic(err)
+		return nil, &ConfigurationError{Reason: ReasonS3ConnectionFailed, Err: err}
 	}
 
-	return minioClient
+	return minioClient, nil
+}
+
+// checkS3Connection returns the error encountered when creating the s3 client on startup, if any.
+func (r *ScanReconciler) checkS3Connection() error {
+	r.configMutex.RLock()
+	defer r.configMutex.RUnlock()
+	return r.s3Error
+}
+
+// getConfig returns the current operator config. The config can be replaced at runtime via UpdateConfig.
+func (r *ScanReconciler) getConfig() config.OperatorConfig {
+	r.configMutex.RLock()
+	defer r.configMutex.RUnlock()
+	return r.Config
+}
+
+// UpdateConfig replaces the operator config used for newly started jobs.
+// Changes to the s3 config are ignored as the s3 client is only created once on startup, changes to manageJobRBAC as the RBACReconciler is only started on startup.
+func (r *ScanReconciler) UpdateConfig(cfg config.OperatorConfig) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+
+	if !reflect.DeepEqual(cfg.S3, r.Config.S3) {
+		r.Log.Info("Changes to the s3 config require a restart of the operator to take effect")
+		cfg.S3 = r.Config.S3
+	}
+	if cfg.ManageJobRBAC != r.Config.ManageJobRBAC {
+		r.Log.Info("Changes to manageJobRBAC require a restart of the operator to take effect")
+		cfg.ManageJobRBAC = r.Config.ManageJobRBAC
+	}
+	r.Config = cfg
+	r.configurationError = nil
 }
 
 func updateScanStateMetrics(scan executionv1.Scan) {
@@ -339,9 +507,14 @@ func (r *ScanReconciler) updateScanStatus(ctx context.Context, scan *executionv1
 
 // SetupWithManager sets up the controller and initializes every thing it needs
 func (r *ScanReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	r.MinioClient = *r.initS3Connection()
-
-	// Todo: Better config management
+	minioClient, err := r.initS3Connection()
+	if err != nil {
+		// keep the operator running, scans will fail with the error and the readiness check reports it
+		r.Log.Error(err, "Could not create minio client to communicate with s3 or compatible storage provider")
+		r.s3Error = err
+	} else {
+		r.MinioClient = minioClient
+	}
 
 	ctx := context.Background()
 	if err := mgr.GetFieldIndexer().IndexField(ctx, &batch.Job{}, ownerKey, func(rawObj client.Object) []string {
@@ -362,9 +535,11 @@ func (r *ScanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	r.webhookEvents = make(chan event.GenericEvent, webhookEventsBufferSize)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&executionv1.Scan{}).
 		Owns(&batch.Job{}).
+		WatchesRawSource(source.Channel(r.webhookEvents, &handler.EnqueueRequestForObject{})).
 		Complete(r)
 }
 
@@ -388,16 +563,11 @@ func containsString(slice []string, s string) bool {
 	return false
 }
 
-func getPresignedUrlPath(scan executionv1.Scan, filename string) string {
-	urlTemplate, ok := os.LookupEnv("S3_URL_TEMPLATE")
-	if !ok {
-		// use default when environment variable is not set
-		urlTemplate = "scan-{{ .Scan.UID }}/{{ .Filename }}"
-	}
+func getPresignedUrlPath(urlTemplate string, scan executionv1.Scan, filename string) (string, error) {
 	return executeUrlTemplate(urlTemplate, scan, filename)
 }
 
-func executeUrlTemplate(urlTemplate string, scan executionv1.Scan, filename string) string {
+func executeUrlTemplate(urlTemplate string, scan executionv1.Scan, filename string) (string, error) {
 	type Template struct {
 		Scan     executionv1.Scan
 		Filename string
@@ -405,19 +575,16 @@ func executeUrlTemplate(urlTemplate string, scan executionv1.Scan, filename stri
 
 	tmpl, err := template.New(urlTemplate).Parse(urlTemplate)
 	if err != nil {
-		panic(err)
-	} else {
-		var rawOutput bytes.Buffer
-		templateArgs := Template{
-			Scan:     scan,
-			Filename: filename,
-		}
+		return "", &ConfigurationError{Reason: ReasonInvalidURLTemplate, Err: err}
+	}
 
-		err = tmpl.Execute(&rawOutput, templateArgs)
-		if err != nil {
-			panic(err)
-		}
-		output := rawOutput.String()
-		return output
+	var rawOutput bytes.Buffer
+	templateArgs := Template{
+		Scan:     scan,
+		Filename: filename,
+	}
+	if err = tmpl.Execute(&rawOutput, templateArgs); err != nil {
+		return "", &ConfigurationError{Reason: ReasonInvalidURLTemplate, Err: err}
 	}
+	return rawOutput.String(), nil
 }
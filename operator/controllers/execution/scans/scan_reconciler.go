@@ -8,15 +8,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
 	util "github.com/secureCodeBox/secureCodeBox/operator/utils"
 	batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -74,6 +73,7 @@ func (r *ScanReconciler) startScan(scan *executionv1.Scan) error {
 
 			scan.Status.State = executionv1.ScanStateErrored
 			scan.Status.ErrorDescription = fmt.Sprintf("Configured ScanType '%s' not found in '%s' namespace. You'll likely need to deploy the ScanType.", scan.Spec.ScanType, scan.Namespace)
+			r.Recorder.Event(scan, "Warning", "ScanTypeNotFound", scan.Status.ErrorDescription)
 			if err := r.updateScanStatus(ctx, scan); err != nil {
 				r.Log.Error(err, "unable to update Scan status")
 				return err
@@ -92,6 +92,7 @@ func (r *ScanReconciler) startScan(scan *executionv1.Scan) error {
 
 			scan.Status.State = executionv1.ScanStateErrored
 			scan.Status.ErrorDescription = fmt.Sprintf("Configured ClusterScanType '%s' not found in global ClusterScanTypes. You'll likely need to deploy the ScanType.", scan.Spec.ScanType)
+			r.Recorder.Event(scan, "Warning", "ClusterScanTypeNotFound", scan.Status.ErrorDescription)
 			if err := r.updateScanStatus(ctx, scan); err != nil {
 				r.Log.Error(err, "unable to update Scan status")
 				return err
@@ -103,41 +104,44 @@ func (r *ScanReconciler) startScan(scan *executionv1.Scan) error {
 		scanTypeSpec = clusterScanType.Spec
 	}
 
-	rules := []rbacv1.PolicyRule{
-		{
-			APIGroups: []string{""},
-			Resources: []string{"pods"},
-			Verbs:     []string{"get"},
-		},
+	if err := validatePodOverrides(scan.Spec.PodOverrides, r.getConfig().PodOverrides.AllowedFields); err != nil {
+		log.V(7).Info("Invalid podOverrides", "error", err)
+
+		scan.Status.State = executionv1.ScanStateErrored
+		scan.Status.ErrorDescription = fmt.Sprintf("Invalid podOverrides: %s", err)
+		r.Recorder.Event(scan, "Warning", "InvalidPodOverrides", scan.Status.ErrorDescription)
+		if err := r.updateScanStatus(ctx, scan); err != nil {
+			r.Log.Error(err, "unable to update Scan status")
+			return err
+		}
+
+		return err
+	}
+
+	if err := r.ensureServiceAccountExists(ctx, scan.Namespace, lurkerServiceAccount); err != nil {
+		return err
 	}
-	r.ensureServiceAccountExists(
-		scan.Namespace,
-		"lurker",
-		"Lurker is used to extract results from secureCodeBox Scans. It needs rights to get and watch the status of pods to see when the scans have finished.",
-		rules,
-	)
 
 	job, err := r.constructJobForScan(scan, &scanTypeSpec)
 	if err != nil {
 		log.Error(err, "unable to create job object from ScanType / ClusterScanType")
+		r.Recorder.Eventf(scan, "Warning", "JobCreationFailed", "Failed to construct scan job: %s", err)
 		return err
 	}
 
 	log.Info("Creating scan job", "job", job.Name, "scanType", scan.Spec.ScanType, "scan", scan.Name, "namespace", scan.Namespace)
 	if err := r.Create(ctx, job); err != nil {
 		log.Error(err, "unable to create Job for Scan", "job", job)
+		r.Recorder.Eventf(scan, "Warning", "JobCreationFailed", "Failed to create scan job: %s", err)
 		return err
 	}
+	r.Recorder.Eventf(scan, "Normal", "ScanJobCreated", "Created scan job %s", job.Name)
 
 	scan.Status.State = executionv1.ScanStateScanning
 	scan.Status.RawResultType = scanTypeSpec.ExtractResults.Type
 	scan.Status.RawResultFile = filepath.Base(scanTypeSpec.ExtractResults.Location)
 
-	urlExpirationDuration, err := util.GetUrlExpirationDuration(util.ScanController)
-	if err != nil {
-		r.Log.Error(err, "Failed to parse scan url expiration")
-		panic(err)
-	}
+	urlExpirationDuration := r.getConfig().URLExpiration.Scan.Duration
 
 	// this time is hardcoded as its not used internally by the scb so it should be longer lasting
 	findingsDownloadURL, err := r.PresignedGetURL(*scan, "findings.json", 7*24*time.Hour)
@@ -183,6 +187,7 @@ func (r *ScanReconciler) checkIfScanIsCompleted(scan *executionv1.Scan) error {
 	switch status {
 	case completed:
 		r.Log.V(7).Info("Scan is completed")
+		r.Recorder.Event(scan, "Normal", "ScanCompleted", "Scan job completed successfully")
 		scan.Status.State = executionv1.ScanStateScanCompleted
 		if err := r.updateScanStatus(ctx, scan); err != nil {
 			r.Log.Error(err, "unable to update Scan status")
@@ -191,6 +196,7 @@ func (r *ScanReconciler) checkIfScanIsCompleted(scan *executionv1.Scan) error {
 	case failed:
 		scan.Status.State = executionv1.ScanStateErrored
 		scan.Status.ErrorDescription = "Failed to run the Scan Container, check k8s Job and its logs for more details"
+		r.Recorder.Event(scan, "Warning", "ScanJobFailed", scan.Status.ErrorDescription)
 		if err := r.updateScanStatus(ctx, scan); err != nil {
 			r.Log.Error(err, "unable to update Scan status")
 			return err
@@ -201,13 +207,9 @@ func (r *ScanReconciler) checkIfScanIsCompleted(scan *executionv1.Scan) error {
 }
 
 func (r *ScanReconciler) constructJobForScan(scan *executionv1.Scan, scanTypeSpec *executionv1.ScanTypeSpec) (*batch.Job, error) {
+	cfg := r.getConfig()
 	filename := filepath.Base(scanTypeSpec.ExtractResults.Location)
-	urlExpirationDuration, err := util.GetUrlExpirationDuration(util.ScanController)
-	if err != nil {
-		r.Log.Error(err, "Failed to parse scan url expiration")
-		panic(err)
-	}
-	resultUploadURL, err := r.PresignedPutURL(*scan, filename, urlExpirationDuration)
+	resultUploadURL, err := r.PresignedPutURL(*scan, filename, cfg.URLExpiration.Scan.Duration)
 	if err != nil {
 		r.Log.Error(err, "Could not get presigned url from s3 or compatible storage provider")
 		return nil, err
@@ -248,7 +250,7 @@ func (r *ScanReconciler) constructJobForScan(scan *executionv1.Scan, scanTypeSpe
 
 	podAnnotations["auto-discovery.securecodebox.io/ignore"] = "true"
 	// Ensuring that istio doesn't inject a sidecar proxy.
-	podAnnotations["sidecar.istio.io/inject"] = allowIstioSidecarInjectionInJobs
+	podAnnotations["sidecar.istio.io/inject"] = strconv.FormatBool(cfg.AllowIstioSidecarInjectionInJobs)
 	job.Spec.Template.Annotations = podAnnotations
 
 	if job.Spec.Template.Spec.ServiceAccountName == "" {
@@ -281,54 +283,14 @@ func (r *ScanReconciler) constructJobForScan(scan *executionv1.Scan, scanTypeSpe
 	// Merge NodeSelectors from Scan into Scan job
 	job.Spec.Template.Spec.NodeSelector = util.MergeStringMaps(job.Spec.Template.Spec.NodeSelector, scan.Spec.NodeSelector)
 
-	// Get lurker image config from env
-	lurkerImage := os.Getenv("LURKER_IMAGE")
-	if lurkerImage == "" {
-		lurkerImage = "securecodebox/lurker:latest"
-	}
-	lurkerPullPolicyRaw := os.Getenv("LURKER_PULL_POLICY")
-	var lurkerPullPolicy corev1.PullPolicy
-	switch lurkerPullPolicyRaw {
-	case "Always":
-		lurkerPullPolicy = corev1.PullAlways
-	case "IfNotPresent":
-		lurkerPullPolicy = corev1.PullIfNotPresent
-	case "Never":
-		lurkerPullPolicy = corev1.PullNever
-	case "":
-		lurkerPullPolicy = corev1.PullAlways
-	default:
-		return nil, fmt.Errorf("unknown imagePull Policy for lurker: %s", lurkerPullPolicyRaw)
-	}
-
-	seccompProfileRaw := os.Getenv("LURKER_SECCOMP_PROFILE")
-	var seccompProfile corev1.SeccompProfile
-	switch seccompProfileRaw {
-	case "Localhost":
-		seccompProfile = corev1.SeccompProfile{
-			Type: corev1.SeccompProfileTypeLocalhost}
-	case "RuntimeDefault":
-		seccompProfile = corev1.SeccompProfile{
-			Type: corev1.SeccompProfileTypeRuntimeDefault}
-	case "Unconfined":
-		seccompProfile = corev1.SeccompProfile{
-			Type: corev1.SeccompProfileTypeUnconfined}
-	case "":
-		seccompProfile = corev1.SeccompProfile{
-			Type: corev1.SeccompProfileTypeRuntimeDefault,
-		}
-	default:
-		return nil, fmt.Errorf("unknown seccompProfile for lurker: %s", seccompProfileRaw)
-	}
-
-	r.Log.V(8).Info("Using Lurker Image", "seccompProfile", seccompProfileRaw)
+	r.Log.V(8).Info("Using Lurker Image", "image", cfg.Lurker.Image, "seccompProfile", cfg.Lurker.SeccompProfile)
 	falsePointer := false
 	truePointer := true
 
 	lurkerSidecar := &corev1.Container{
 		Name:            "lurker",
-		Image:           lurkerImage,
-		ImagePullPolicy: lurkerPullPolicy,
+		Image:           cfg.Lurker.Image,
+		ImagePullPolicy: cfg.Lurker.PullPolicy,
 		Args: []string{
 			"--container",
 			job.Spec.Template.Spec.Containers[0].Name,
@@ -373,13 +335,13 @@ func (r *ScanReconciler) constructJobForScan(scan *executionv1.Scan, scanTypeSpe
 				Drop: []corev1.Capability{"ALL"},
 			},
 			SeccompProfile: &corev1.SeccompProfile{
-				Type: seccompProfile.Type,
+				Type: cfg.Lurker.SeccompProfile,
 			},
 		},
 	}
 
-	customCACertificate, isConfigured := os.LookupEnv("CUSTOM_CA_CERTIFICATE_EXISTING_CERTIFICATE")
-	r.Log.V(7).Info("Configuring customCACerts for lurker", "customCACertificate", customCACertificate, "isConfigured", isConfigured)
+	customCACertificate := cfg.CustomCACertificate.ExistingCertificate
+	r.Log.V(7).Info("Configuring customCACerts for lurker", "customCACertificate", customCACertificate)
 	if customCACertificate != "" {
 		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
 			Name: "ca-certificate",
@@ -392,7 +354,7 @@ func (r *ScanReconciler) constructJobForScan(scan *executionv1.Scan, scanTypeSpe
 			},
 		})
 
-		certificateName := os.Getenv("CUSTOM_CA_CERTIFICATE_NAME")
+		certificateName := cfg.CustomCACertificate.Certificate
 		lurkerSidecar.VolumeMounts = append(lurkerSidecar.VolumeMounts, corev1.VolumeMount{
 			Name:      "ca-certificate",
 			ReadOnly:  true,
@@ -453,6 +415,11 @@ func (r *ScanReconciler) constructJobForScan(scan *executionv1.Scan, scanTypeSpe
 	job.Spec.Template.Spec.Containers[0].Command = command
 	job.Spec.Template.Spec.Containers[0].Args = nil
 
+	// Apply podOverrides last, so that they take precedence over everything configured in the ScanType and the Scan
+	if err := applyPodOverrides(&job.Spec.Template.Spec, scan.Spec.PodOverrides, cfg.PodOverrides.AllowedFields); err != nil {
+		return nil, err
+	}
+
 	return job, nil
 }
 
@@ -479,10 +446,12 @@ func (r *ScanReconciler) deleteScan(scan *executionv1.Scan) error {
 			r.Log.Info("Scan was already deleted, nothing to do")
 		} else {
 			r.Log.Error(err, "Unexpected error while trying to delete Scan")
+			r.Recorder.Eventf(scan, "Warning", "DeletionFailed", "Failed to delete Scan after ttlSecondsAfterFinished elapsed: %s", err)
 			return err
 		}
 	} else {
 		r.Log.Info("Scan was deleted successfully", "scan", scan.Name)
+		r.Recorder.Eventf(scan, "Normal", "Deleted", "Deleted Scan as ttlSecondsAfterFinished of %ds elapsed", *scan.Spec.TTLSecondsAfterFinished)
 	}
 	return nil
 }
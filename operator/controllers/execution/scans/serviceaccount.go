@@ -6,107 +6,215 @@ package scancontrollers
 
 import (
 	"context"
-
+	"fmt"
 	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-func (r *ScanReconciler) ensureServiceAccountExists(namespace, serviceAccountName, description string, policyRules []rbacv1.PolicyRule) error {
-	ctx := context.Background()
+const (
+	// managedByLabel marks the ServiceAccounts, Roles and RoleBindings of the jobs which are managed by the operator
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "securecodebox"
+)
 
-	var serviceAccount corev1.ServiceAccount
-	err := r.Get(ctx, types.NamespacedName{Name: serviceAccountName, Namespace: namespace}, &serviceAccount)
-	if apierrors.IsNotFound(err) {
-		r.Log.Info("Creating missing service account", "serviceAccountName", serviceAccountName, "namespace", namespace)
-		serviceAccount = corev1.ServiceAccount{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      serviceAccountName,
-				Namespace: namespace,
-				Annotations: map[string]string{
-					"description": description,
-				},
+// jobServiceAccount is a ServiceAccount used by the jobs started by the operator, together with the rules of its Role
+type jobServiceAccount struct {
+	Name        string
+	Description string
+	Rules       []rbacv1.PolicyRule
+}
+
+var (
+	lurkerServiceAccount = jobServiceAccount{
+		Name:        "lurker",
+		Description: "Lurker is used to extract results from secureCodeBox Scans. It needs rights to get and watch the status of pods to see when the scans have finished.",
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get"},
 			},
-		}
-		err := r.Create(ctx, &serviceAccount)
-		if err != nil {
-			r.Log.Error(err, "Failed to create ServiceAccount", "serviceAccountName", serviceAccountName, "namespace", namespace)
-			return err
-		}
-	} else if err != nil {
-		r.Log.Error(err, "Unexpected error while checking if a ServiceAccount exists", "serviceAccountName", serviceAccountName, "namespace", namespace)
+		},
+	}
+	parserServiceAccount = jobServiceAccount{
+		Name:        "parser",
+		Description: "Parser need to access the Scan and its ParseDefinition to parse the raw results of the scanner",
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"execution.securecodebox.io"},
+				Resources: []string{"scans"},
+				Verbs:     []string{"get"},
+			},
+			// todo(v6): remove once parser images released before the operator computed the finding stats itself are no longer supported, they patch the finding stats and fail without this rule
+			scanStatusPatchRule,
+			{
+				APIGroups: []string{"execution.securecodebox.io"},
+				Resources: []string{"parsedefinitions"},
+				Verbs:     []string{"get"},
+			},
+		},
+	}
+	hookServiceAccount = jobServiceAccount{
+		Name:        "scan-completion-hook",
+		Description: "ScanCompletionHooks need to access the current scan to view where its results are stored",
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"execution.securecodebox.io"},
+				Resources: []string{"scans"},
+				Verbs:     []string{"get"},
+			},
+			// todo(v6): remove once hook images released before the operator computed the finding stats itself are no longer supported, they patch the finding stats and fail without this rule
+			scanStatusPatchRule,
+		},
+	}
+
+	// scanStatusPatchRule is only kept for parsers and hooks built with older versions of the SDKs. The operator computes the finding stats itself and overwrites the patched ones.
+	scanStatusPatchRule = rbacv1.PolicyRule{
+		APIGroups: []string{"execution.securecodebox.io"},
+		Resources: []string{"scans/status"},
+		Verbs:     []string{"get", "patch"},
+	}
+
+	// jobServiceAccounts are all ServiceAccounts managed by the operator in the namespaces scans run in
+	jobServiceAccounts = []jobServiceAccount{lurkerServiceAccount, parserServiceAccount, hookServiceAccount}
+)
+
+// ensureServiceAccountExists creates the ServiceAccount, Role and RoleBinding of a job in the namespace of the scan, unless managing them is disabled in the operator config.
+func (r *ScanReconciler) ensureServiceAccountExists(ctx context.Context, namespace string, account jobServiceAccount) error {
+	if !r.getConfig().ManageJobRBAC {
+		return nil
+	}
+	if _, _, err := ensureJobServiceAccount(ctx, r.Client, r.APIReader, r.Client.Scheme(), namespace, account); err != nil {
+		r.Log.Error(err, "Failed to ensure the ServiceAccount of the job exists", "serviceAccountName", account.Name, "namespace", namespace)
 		return err
 	}
+	return nil
+}
 
-	var role rbacv1.Role
-	err = r.Get(ctx, types.NamespacedName{Name: serviceAccountName, Namespace: namespace}, &role)
-	if apierrors.IsNotFound(err) {
-		r.Log.Info("Creating missing Role", "roleName", serviceAccountName, "namespace", namespace)
-		role = rbacv1.Role{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      serviceAccountName,
-				Namespace: namespace,
-				Annotations: map[string]string{
-					"description": description,
-				},
-			},
-			Rules: policyRules,
+// ensureJobServiceAccount creates the ServiceAccount, Role and RoleBinding of a job, or repairs them if they were changed.
+// The Role and RoleBinding are owned by the ServiceAccount. Existing objects without the managed-by label are adopted,
+// they aren't in the cache of the operator and are read using the apiReader if it's set.
+// Returns the managed objects which had to be repaired and the objects which were adopted.
+func ensureJobServiceAccount(ctx context.Context, c client.Client, apiReader client.Reader, scheme *runtime.Scheme, namespace string, account jobServiceAccount) (repaired []client.Object, adopted []client.Object, err error) {
+	c = cacheFallbackClient{Client: c, apiReader: apiReader}
+	// track records whether an object which had to be updated was managed before
+	track := func(obj client.Object, unmanaged bool, result controllerutil.OperationResult) {
+		switch {
+		case result != controllerutil.OperationResultUpdated:
+		case unmanaged:
+			adopted = append(adopted, obj)
+		default:
+			repaired = append(repaired, obj)
 		}
-		err := r.Create(ctx, &role)
-		if err != nil {
-			r.Log.Error(err, "Failed to create Role", "roleName", serviceAccountName, "namespace", namespace)
-			return err
-		}
-	} else if !reflect.DeepEqual(role.Rules, policyRules) {
-		r.Log.Info("Role already exists but not in the correct state")
-		role.Rules = policyRules
-		err := r.Update(ctx, &role)
-		if err != nil {
-			r.Log.Error(err, "Failed to update Role", "roleName", serviceAccountName, "namespace", namespace)
-			return err
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: account.Name, Namespace: namespace}}
+	var unmanaged bool
+	result, err := controllerutil.CreateOrUpdate(ctx, c, serviceAccount, func() error {
+		unmanaged = isUnmanaged(serviceAccount)
+		setManagedMetadata(&serviceAccount.ObjectMeta, account.Description)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reconcile ServiceAccount %s: %w", account.Name, err)
+	}
+	track(serviceAccount, unmanaged, result)
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: account.Name, Namespace: namespace}}
+	result, err = controllerutil.CreateOrUpdate(ctx, c, role, func() error {
+		unmanaged = isUnmanaged(role)
+		setManagedMetadata(&role.ObjectMeta, account.Description)
+		role.Rules = account.Rules
+		return controllerutil.SetControllerReference(serviceAccount, role, scheme)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reconcile Role %s: %w", account.Name, err)
+	}
+	track(role, unmanaged, result)
+
+	roleRef := rbacv1.RoleRef{
+		Kind:     "Role",
+		Name:     account.Name,
+		APIGroup: "rbac.authorization.k8s.io",
+	}
+	// the roleRef of a RoleBinding can't be changed, RoleBindings referencing another role have to be recreated
+	var existing rbacv1.RoleBinding
+	err = c.Get(ctx, types.NamespacedName{Name: account.Name, Namespace: namespace}, &existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("failed to get RoleBinding %s: %w", account.Name, err)
+	}
+	recreated := false
+	if err == nil && !reflect.DeepEqual(existing.RoleRef, roleRef) {
+		if err := c.Delete(ctx, &existing); client.IgnoreNotFound(err) != nil {
+			return nil, nil, fmt.Errorf("failed to delete RoleBinding %s with a changed roleRef: %w", account.Name, err)
 		}
-	} else if err != nil {
-		r.Log.Error(err, "Unexpected error while checking if a Role exists", "roleName", serviceAccountName, "namespace", namespace)
-		return err
+		recreated = true
+		unmanaged = isUnmanaged(&existing)
 	}
 
-	var roleBinding rbacv1.RoleBinding
-	err = r.Get(ctx, types.NamespacedName{Name: serviceAccountName, Namespace: namespace}, &roleBinding)
-	if apierrors.IsNotFound(err) {
-		r.Log.Info("Creating missing RoleBinding", "roleName", serviceAccountName, "namespace", namespace)
-		roleBinding = rbacv1.RoleBinding{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      serviceAccountName,
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: account.Name, Namespace: namespace}}
+	result, err = controllerutil.CreateOrUpdate(ctx, c, roleBinding, func() error {
+		if !recreated {
+			unmanaged = isUnmanaged(roleBinding)
+		}
+		setManagedMetadata(&roleBinding.ObjectMeta, account.Description)
+		roleBinding.Subjects = []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      account.Name,
 				Namespace: namespace,
-				Annotations: map[string]string{
-					"description": description,
-				},
-			},
-			Subjects: []rbacv1.Subject{
-				{
-					Kind: "ServiceAccount",
-					Name: serviceAccountName,
-				},
-			},
-			RoleRef: rbacv1.RoleRef{
-				Kind:     "Role",
-				Name:     serviceAccountName,
-				APIGroup: "rbac.authorization.k8s.io",
 			},
 		}
-		err := r.Create(ctx, &roleBinding)
-		if err != nil {
-			r.Log.Error(err, "Failed to create RoleBinding", "roleName", serviceAccountName, "namespace", namespace)
-			return err
-		}
-	} else if err != nil {
-		r.Log.Error(err, "Unexpected error while checking if a RoleBinding exists", "roleName", serviceAccountName, "namespace", namespace)
-		return err
+		roleBinding.RoleRef = roleRef
+		return controllerutil.SetControllerReference(serviceAccount, roleBinding, scheme)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reconcile RoleBinding %s: %w", account.Name, err)
+	}
+	if recreated {
+		result = controllerutil.OperationResultUpdated
 	}
+	track(roleBinding, unmanaged, result)
 
-	return nil
+	return repaired, adopted, nil
+}
+
+// isUnmanaged checks if the object exists, but doesn't have the managed-by label of the operator yet
+func isUnmanaged(obj client.Object) bool {
+	return obj.GetResourceVersion() != "" && obj.GetLabels()[managedByLabel] != managedByValue
+}
+
+// cacheFallbackClient reads objects which aren't in the cache from the api server.
+// The cache only contains the ServiceAccounts, Roles and RoleBindings with the managed-by label, existing objects without it are read from the api server to adopt them.
+type cacheFallbackClient struct {
+	client.Client
+	apiReader client.Reader
+}
+
+func (c cacheFallbackClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	err := c.Client.Get(ctx, key, obj, opts...)
+	if apierrors.IsNotFound(err) && c.apiReader != nil {
+		return c.apiReader.Get(ctx, key, obj, opts...)
+	}
+	return err
+}
+
+// setManagedMetadata sets the managed-by label and the description annotation of the objects managed by the operator
+func setManagedMetadata(meta *metav1.ObjectMeta, description string) {
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+	meta.Labels[managedByLabel] = managedByValue
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations["description"] = description
 }
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Suppressed findings", func() {
+	const parsedFindings = `[
+		{"id": "6f1f0d1a-3b1c-4c3e-9f2a-000000000000", "parsed_at": "2026-03-20T12:00:00Z", "name": "Open Port: 22", "category": "Open Port", "severity": "LOW",
+		 "attributes": {"port": 22, "inode": 9007199254740993}, "custom_field": {"keep": "me"},
+		 "scan": {"created_at": "2026-03-20T11:00:00Z", "name": "nmap", "namespace": "default", "scan_type": "nmap"}},
+		{"id": "6f1f0d1a-3b1c-4c3e-9f2a-000000000001", "parsed_at": "2026-03-20T12:00:00Z", "name": "Open Port: 443", "category": "Open Port", "severity": "LOW",
+		 "attributes": {"port": 443},
+		 "scan": {"created_at": "2026-03-20T11:00:00Z", "name": "nmap", "namespace": "default", "scan_type": "nmap"}}
+	]`
+
+	var (
+		r       *ScanReconciler
+		scan    *executionv1.Scan
+		server  *httptest.Server
+		storage *fakeS3
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+
+		var minioClient *minio.Client
+		server, storage, minioClient = newFakeS3(map[string][]byte{
+			"scan-6b8c1a32/findings.json": []byte(parsedFindings),
+		})
+
+		cfg := config.Default()
+		cfg.S3.Bucket = "securecodebox"
+		scan = &executionv1.Scan{
+			ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default", UID: "6b8c1a32"},
+			Status:     executionv1.ScanStatus{State: executionv1.ScanStateParsing, RawResultFile: "nmap-results.xml"},
+		}
+		suppression := &executionv1.FindingSuppression{
+			ObjectMeta: metav1.ObjectMeta{Name: "ssh", Namespace: "default"},
+			Spec: executionv1.FindingSuppressionSpec{
+				Matches: executionv1.FindingSuppressionMatches{AnyOf: []executionv1.FindingSuppressionRule{
+					{Category: "Open *", Attributes: map[string]intstr.IntOrString{"port": intstr.FromInt(22)}},
+				}},
+				Owner:         "team-security",
+				Justification: "Accepted risk",
+			},
+		}
+		r = &ScanReconciler{
+			Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(scan, suppression).WithStatusSubresource(scan).Build(),
+			Log:         logr.Discard(),
+			Recorder:    record.NewFakeRecorder(10),
+			Config:      cfg,
+			MinioClient: minioClient,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should write the suppressed findings to a new version and keep the findings of the parser", func() {
+		findings, invalidFindings, err := r.getFindings(scan, findingsFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invalidFindings).NotTo(HaveOccurred())
+
+		Expect(r.applyFindingSuppressions(context.Background(), scan, findings)).To(Succeed())
+
+		Expect(scan.Status.SuppressedFindings).To(Equal([]executionv1.SuppressedFindingsStats{{Name: "ssh", Count: 1}}))
+		Expect(scan.Status.CurrentFindingsFile).To(Equal("findings.suppressed.json"))
+		Expect(currentFindingsFile(scan)).To(Equal("findings.suppressed.json"))
+		Expect(storage.objects["scan-6b8c1a32/findings.json"]).To(MatchJSON(parsedFindings))
+
+		suppressedData := storage.objects["scan-6b8c1a32/findings.suppressed.json"]
+		Expect(string(suppressedData)).To(ContainSubstring(`"inode":9007199254740993`))
+		Expect(string(suppressedData)).To(ContainSubstring(`"custom_field":{"keep":"me"}`))
+
+		suppressedFindings, err := findingsv1.ParseAndValidate(suppressedData)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(suppressedFindings[0].Suppression).NotTo(BeNil())
+		Expect(suppressedFindings[0].Suppression.Name).To(Equal("ssh"))
+		Expect(suppressedFindings[1].Suppression).To(BeNil())
+	})
+
+	It("should count the suppressed findings of a scan once if the suppressions are applied again", func() {
+		// the fake client can't store the uint64 fields of the FindingSuppression status, the status is kept here instead
+		var status executionv1.FindingSuppressionStatus
+		r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := c.Get(ctx, key, obj, opts...); err != nil {
+					return err
+				}
+				if suppression, ok := obj.(*executionv1.FindingSuppression); ok {
+					suppression.Status = *status.DeepCopy()
+				}
+				return nil
+			},
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				status = *obj.(*executionv1.FindingSuppression).Status.DeepCopy()
+				return nil
+			},
+		})
+		for range 2 {
+			findings, _, err := r.getFindings(scan, findingsFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.applyFindingSuppressions(context.Background(), scan, findings)).To(Succeed())
+		}
+
+		Expect(status.SuppressedFindings).To(Equal(uint64(1)))
+		Expect(status.LastScan).To(Equal("nmap"))
+	})
+
+	It("should not write a new version if no findings are suppressed", func() {
+		findings, _, err := r.getFindings(scan, findingsFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Delete(context.Background(), &executionv1.FindingSuppression{ObjectMeta: metav1.ObjectMeta{Name: "ssh", Namespace: "default"}})).To(Succeed())
+
+		Expect(r.applyFindingSuppressions(context.Background(), scan, findings)).To(Succeed())
+
+		Expect(scan.Status.CurrentFindingsFile).To(BeEmpty())
+		Expect(currentFindingsFile(scan)).To(Equal("findings.json"))
+		Expect(storage.objects).NotTo(HaveKey("scan-6b8c1a32/findings.suppressed.json"))
+	})
+
+	It("should start reruns of ReadAndWrite hooks with the suppressed findings", func() {
+		findings, _, err := r.getFindings(scan, findingsFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.applyFindingSuppressions(context.Background(), scan, findings)).To(Succeed())
+
+		storage.objects["scan-6b8c1a32/findings.cleanup.json"] = openPortFindings(findingsv1.SeverityHigh)
+		scan.Status.CurrentFindingsFile = "findings.cleanup.json"
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{
+			{{HookName: "cleanup", Type: executionv1.ReadAndWrite, State: executionv1.Pending, FindingsFile: "findings.cleanup.json"}},
+		}
+
+		Expect(r.resetFilesForRerun(scan, []string{"cleanup"})).To(Succeed())
+		Expect(scan.Status.CurrentFindingsFile).To(Equal("findings.suppressed.json"))
+		Expect(scan.Status.Findings.Count).To(Equal(uint64(1)))
+	})
+})
+
+var _ = Describe("Compiled FindingSuppression matches", func() {
+	It("should match numbers decoded as json.Number", func() {
+		rule := executionv1.FindingSuppressionRule{Attributes: map[string]intstr.IntOrString{"port": intstr.FromInt(22), "inode": intstr.FromString("9007199254740993")}}
+		matchesFinding := rule.Compile()
+
+		Expect(matchesFinding(findingsv1.Finding{Attributes: map[string]any{"port": json.Number("22"), "inode": json.Number("9007199254740993")}})).To(BeTrue())
+		Expect(matchesFinding(findingsv1.Finding{Attributes: map[string]any{"port": json.Number("22"), "inode": json.Number("9007199254740992")}})).To(BeFalse())
+	})
+})
@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scancontrollers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// webhookSignatureHeader contains the HMAC-SHA256 signature of the payload, if the webhook has a secret configured
+	webhookSignatureHeader = "X-SecureCodeBox-Signature-256"
+	// webhookMaxTimeout limits the timeout of webhook requests, regardless of the timeout configured for the webhook
+	webhookMaxTimeout = 5 * time.Minute
+	// webhookRequeueInterval is the interval scans with webhook requests in progress are reconciled in, in case the event for the finished request was dropped
+	webhookRequeueInterval = 30 * time.Second
+	// webhookEventsBufferSize is the number of finished webhook requests which can be queued up while the controller is busy
+	webhookEventsBufferSize = 100
+)
+
+// webhookHTTPClient is used to send webhooks if the ScanReconciler has no HTTPClient configured.
+// It doesn't follow redirects, so that webhook urls can't redirect the signed requests to other, e.g. cluster internal, endpoints.
+var webhookHTTPClient = &http.Client{
+	Timeout: webhookMaxTimeout,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// WebhookPayload is the body sent to the url of webhook hooks.
+type WebhookPayload struct {
+	// Hook is the name of the ScanCompletionHook which sent the payload
+	Hook string `json:"hook"`
+	// Scan contains the metadata and status of the scan
+	Scan WebhookScan `json:"scan"`
+	// FindingsURL is a presigned url to download the findings of the scan from
+	FindingsURL string `json:"findingsUrl"`
+	// RawResultURL is a presigned url to download the raw result file of the scanner from
+	RawResultURL string `json:"rawResultUrl"`
+}
+
+// WebhookScan is the subset of a Scan sent as part of a WebhookPayload.
+type WebhookScan struct {
+	Name          string                   `json:"name"`
+	Namespace     string                   `json:"namespace"`
+	UID           types.UID                `json:"uid"`
+	Labels        map[string]string        `json:"labels,omitempty"`
+	Annotations   map[string]string        `json:"annotations,omitempty"`
+	ScanType      string                   `json:"scanType"`
+	Parameters    []string                 `json:"parameters"`
+	RawResultType string                   `json:"rawResultType"`
+	RawResultFile string                   `json:"rawResultFile"`
+	Findings      executionv1.FindingStats `json:"findings"`
+}
+
+// webhookDelivery is a webhook request sent in the background. httpStatus and err are set once done is closed.
+type webhookDelivery struct {
+	done       chan struct{}
+	httpStatus int
+	err        error
+}
+
+// processWebhookHook sends the webhook of a pending hook in the background, so that slow endpoints don't block the reconciliation of other scans.
+// The hook is InProgress until the request finished, see processInProgressWebhookHook.
+func (r *ScanReconciler) processWebhookHook(scan *executionv1.Scan, status *executionv1.HookStatus, hookSpec *executionv1.ScanCompletionHookSpec) error {
+	ctx := context.Background()
+	webhook := hookSpec.Webhook
+
+	if hookSpec.Type != executionv1.ReadOnly {
+		return &HookFailureError{Err: fmt.Errorf("webhook hook '%s' must be of type ReadOnly", status.HookName)}
+	}
+
+	body, err := r.generateWebhookPayload(scan, status.HookName)
+	if err != nil {
+		return err
+	}
+	secret, err := r.getWebhookSecret(ctx, scan, webhook.SecretRef)
+	if err != nil {
+		// the secret might be created later on, so this counts as a failed request
+		r.handleWebhookFailure(scan, status, webhook, err)
+		return nil
+	}
+
+	r.startWebhookDelivery(scan, status.HookName, func() (int, error) {
+		return r.sendWebhook(ctx, webhook, body, secret)
+	})
+	status.State = executionv1.InProgress
+	status.RetryAfter = nil
+	return nil
+}
+
+// processInProgressWebhookHook applies the result of the webhook request once it finished.
+// Failed requests are handled by the failure policy of the hook, see handleWebhookFailure.
+// Requests which got lost because the operator was restarted are sent again.
+func (r *ScanReconciler) processInProgressWebhookHook(scan *executionv1.Scan, status *executionv1.HookStatus, hookSpec *executionv1.ScanCompletionHookSpec) error {
+	key := webhookDeliveryKey(scan, status.HookName)
+	value, ok := r.webhookDeliveries.Load(key)
+	if !ok {
+		r.Log.Info("Webhook request of the hook got lost, sending it again", "hook", status.HookName, "scan", scan.Name, "namespace", scan.Namespace)
+		return r.processWebhookHook(scan, status, hookSpec)
+	}
+	delivery := value.(*webhookDelivery)
+	select {
+	case <-delivery.done:
+	default:
+		// still waiting for the request to finish, the scan gets reconciled once it has
+		return nil
+	}
+	r.webhookDeliveries.Delete(key)
+
+	status.HTTPStatus = int32(delivery.httpStatus)
+	if delivery.err != nil {
+		r.handleWebhookFailure(scan, status, hookSpec.Webhook, delivery.err)
+		return nil
+	}
+	status.State = executionv1.Completed
+	r.Recorder.Eventf(scan, "Normal", "WebhookDelivered", "Hook %s delivered webhook to %s (HTTP %d)", status.HookName, hookSpec.Webhook.URL, delivery.httpStatus)
+	return nil
+}
+
+// handleWebhookFailure applies the failure policy of the hook to a failed webhook request, like handleHookFailure does for failed jobs.
+// Hooks with the "Retry" policy are reset to pending until they are out of retries, afterwards the hook is marked as failed.
+func (r *ScanReconciler) handleWebhookFailure(scan *executionv1.Scan, status *executionv1.HookStatus, webhook *executionv1.WebhookHookSpec, err error) {
+	r.Log.Info("Failed to deliver webhook", "hook", status.HookName, "url", webhook.URL, "error", err.Error())
+	maxRetries, backoff := hookRetryPolicy(status.FailurePolicy)
+	if status.Retries < maxRetries {
+		status.Retries++
+		status.State = executionv1.Pending
+		status.RetryAfter = &metav1.Time{Time: time.Now().Add(backoff)}
+		r.Recorder.Eventf(scan, "Warning", "WebhookRetry", "Hook %s failed to deliver webhook (%s), retrying in %s (retry %d of %d)", status.HookName, err, backoff, status.Retries, maxRetries)
+		return
+	}
+
+	r.markHookFailed(scan, status, executionv1.Failed, "WebhookFailed", err.Error())
+}
+
+// startWebhookDelivery runs send in the background and triggers the reconciliation of the scan once it finished.
+// The event is dropped instead of blocking if the controller is busy or stopping, scans with webhook requests in progress are requeued periodically anyway.
+func (r *ScanReconciler) startWebhookDelivery(scan *executionv1.Scan, hookName string, send func() (int, error)) {
+	delivery := &webhookDelivery{done: make(chan struct{})}
+	r.webhookDeliveries.Store(webhookDeliveryKey(scan, hookName), delivery)
+
+	scanRef := &executionv1.Scan{ObjectMeta: metav1.ObjectMeta{Name: scan.Name, Namespace: scan.Namespace}}
+	go func() {
+		delivery.httpStatus, delivery.err = send()
+		close(delivery.done)
+		if r.webhookEvents == nil {
+			return
+		}
+		select {
+		case r.webhookEvents <- event.GenericEvent{Object: scanRef}:
+		default:
+			r.Log.Info("Controller is busy, the scan gets reconciled on its next requeue", "scan", scan.Name, "namespace", scan.Namespace, "hook", hookName)
+		}
+	}()
+}
+
+// hasWebhookDeliveries checks if webhook requests of the scan are in progress.
+func (r *ScanReconciler) hasWebhookDeliveries(scan *executionv1.Scan) bool {
+	for _, group := range scan.Status.OrderedHookStatuses {
+		for _, status := range group {
+			if status.State != executionv1.InProgress {
+				continue
+			}
+			if _, ok := r.webhookDeliveries.Load(webhookDeliveryKey(scan, status.HookName)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func webhookDeliveryKey(scan *executionv1.Scan, hookName string) string {
+	return string(scan.UID) + "/" + hookName
+}
+
+func (r *ScanReconciler) generateWebhookPayload(scan *executionv1.Scan, hookName string) ([]byte, error) {
+	urlExpirationDuration := r.getConfig().URLExpiration.Hook.Duration
+
+	rawResultURL, err := r.PresignedGetURL(*scan, currentRawResultFile(scan), urlExpirationDuration)
+	if err != nil {
+		return nil, err
+	}
+	findingsURL, err := r.PresignedGetURL(*scan, currentFindingsFile(scan), urlExpirationDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(WebhookPayload{
+		Hook: hookName,
+		Scan: WebhookScan{
+			Name:          scan.Name,
+			Namespace:     scan.Namespace,
+			UID:           scan.UID,
+			Labels:        scan.Labels,
+			Annotations:   scan.Annotations,
+			ScanType:      scan.Spec.ScanType,
+			Parameters:    scan.Spec.Parameters,
+			RawResultType: scan.Status.RawResultType,
+			RawResultFile: scan.Status.RawResultFile,
+			Findings:      scan.Status.Findings,
+		},
+		FindingsURL:  findingsURL,
+		RawResultURL: rawResultURL,
+	})
+}
+
+// getWebhookSecret reads the secret used to sign the payload from the namespace of the scan. Returns nil if no secret is configured.
+func (r *ScanReconciler) getWebhookSecret(ctx context.Context, scan *executionv1.Scan, secretRef *corev1.SecretKeySelector) ([]byte, error) {
+	if secretRef == nil {
+		return nil, nil
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: secretRef.Name, Namespace: scan.Namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get webhook secret '%s': %w", secretRef.Name, err)
+	}
+	value, ok := secret.Data[secretRef.Key]
+	if !ok {
+		return nil, fmt.Errorf("webhook secret '%s' has no key '%s'", secretRef.Name, secretRef.Key)
+	}
+	return value, nil
+}
+
+// sendWebhook posts the payload to the url of the webhook and returns the HTTP status code of the response.
+// Responses with a non 2xx status code are returned as error.
+func (r *ScanReconciler) sendWebhook(ctx context.Context, webhook *executionv1.WebhookHookSpec, body []byte, secret []byte) (int, error) {
+	timeout := 10 * time.Second
+	if webhook.Timeout != nil {
+		timeout = min(webhook.Timeout.Duration, webhookMaxTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	for key, value := range webhook.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "secureCodeBox-operator")
+	if secret != nil {
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(body, secret))
+	}
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = webhookHTTPClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// drain the body to allow the connection to be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded with HTTP %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// signWebhookPayload returns the HMAC-SHA256 signature of the body in the format "sha256=<hex>"
+func signWebhookPayload(body []byte, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
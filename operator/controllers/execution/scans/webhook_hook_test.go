@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package scancontrollers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Webhook Hooks", func() {
+	var (
+		r          *ScanReconciler
+		recorder   *record.FakeRecorder
+		scan       *executionv1.Scan
+		status     *executionv1.HookStatus
+		hookSpec   *executionv1.ScanCompletionHookSpec
+		server     *httptest.Server
+		mutex      sync.Mutex
+		responses  []int
+		requests   []*http.Request
+		lastBody   []byte
+		retryCount int32
+	)
+
+	receivedRequests := func() []*http.Request {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests
+	}
+
+	// deliver sends the webhook of the pending hook and waits for the request to finish
+	deliver := func() {
+		Expect(r.processWebhookHook(scan, status, hookSpec)).To(Succeed())
+		Expect(status.State).To(Equal(executionv1.InProgress))
+		Eventually(func() executionv1.HookState {
+			Expect(r.processInProgressWebhookHook(scan, status, hookSpec)).To(Succeed())
+			return status.State
+		}).ShouldNot(Equal(executionv1.InProgress))
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+		responses = []int{http.StatusOK}
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			lastBody, _ = io.ReadAll(req.Body)
+			requests = append(requests, req)
+			w.WriteHeader(responses[0])
+			if len(responses) > 1 {
+				responses = responses[1:]
+			}
+		}))
+
+		minioClient, err := minio.New("s3.example.com", &minio.Options{
+			Creds:  credentials.NewStaticV4("access-key", "secret-key", ""),
+			Secure: true,
+			Region: "eu-central-1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg := config.Default()
+		cfg.S3.Bucket = "securecodebox"
+		scan = &executionv1.Scan{
+			ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default", UID: "6b8c1a32"},
+			Spec:       executionv1.ScanSpec{ScanType: "nmap", Parameters: []string{"scanme.nmap.org"}},
+			Status: executionv1.ScanStatus{
+				State:         executionv1.ScanStateHookProcessing,
+				RawResultType: "nmap-xml",
+				RawResultFile: "nmap-results.xml",
+				Findings:      executionv1.FindingStats{Count: 2},
+			},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-secret", Namespace: "default"},
+			Data:       map[string][]byte{"secret": []byte("s3cr3t")},
+		}
+		recorder = record.NewFakeRecorder(10)
+		r = &ScanReconciler{
+			Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(scan, secret).Build(),
+			Log:         logr.Discard(),
+			Recorder:    recorder,
+			Config:      cfg,
+			MinioClient: minioClient,
+			HTTPClient:  server.Client(),
+		}
+
+		retryCount = 2
+		hookSpec = &executionv1.ScanCompletionHookSpec{
+			Type: executionv1.ReadOnly,
+			Webhook: &executionv1.WebhookHookSpec{
+				URL:       server.URL,
+				SecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-secret"}, Key: "secret"},
+				Headers:   map[string]string{"Authorization": "Bearer token"},
+			},
+		}
+		status = &executionv1.HookStatus{
+			HookName: "notify",
+			State:    executionv1.Pending,
+			Type:     executionv1.ReadOnly,
+			FailurePolicy: &executionv1.HookFailurePolicy{
+				Type:  executionv1.HookFailurePolicyRetry,
+				Retry: &executionv1.HookRetryPolicy{Max: retryCount, Backoff: &metav1.Duration{Duration: 5 * time.Second}},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should post the signed payload and complete the hook", func() {
+		deliver()
+
+		Expect(status.State).To(Equal(executionv1.Completed))
+		Expect(status.HTTPStatus).To(BeEquivalentTo(http.StatusOK))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+		Expect(requests[0].Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(requests[0].Header.Get(webhookSignatureHeader)).To(Equal(signWebhookPayload(lastBody, []byte("s3cr3t"))))
+
+		var payload WebhookPayload
+		Expect(json.Unmarshal(lastBody, &payload)).To(Succeed())
+		Expect(payload.Hook).To(Equal("notify"))
+		Expect(payload.Scan.Name).To(Equal("nmap"))
+		Expect(payload.Scan.Findings.Count).To(BeEquivalentTo(2))
+		Expect(payload.FindingsURL).To(HavePrefix("https://s3.example.com/securecodebox/scan-6b8c1a32/findings.json?"))
+		Expect(payload.RawResultURL).To(HavePrefix("https://s3.example.com/securecodebox/scan-6b8c1a32/nmap-results.xml?"))
+	})
+
+	It("should retry failed requests according to the failure policy and fail the hook once it is out of retries", func() {
+		responses = []int{http.StatusBadGateway}
+
+		deliver()
+		Expect(status.State).To(Equal(executionv1.Pending))
+		Expect(status.HTTPStatus).To(BeEquivalentTo(http.StatusBadGateway))
+		Expect(status.Retries).To(BeEquivalentTo(1))
+		Expect(status.RetryAfter.Time).To(BeTemporally("~", time.Now().Add(5*time.Second), time.Second))
+
+		deliver()
+		Expect(status.Retries).To(BeEquivalentTo(2))
+		Expect(status.RetryAfter.Time).To(BeTemporally("~", time.Now().Add(5*time.Second), time.Second))
+
+		deliver()
+		Expect(status.State).To(Equal(executionv1.Failed))
+		Expect(status.RetryAfter).To(BeNil())
+		Expect(receivedRequests()).To(HaveLen(3))
+		Eventually(recorder.Events).Should(Receive(Equal("Warning WebhookFailed Hook notify failed (webhook responded with HTTP 502).")))
+	})
+
+	It("should fail the hook on the first failed request without a retry policy", func() {
+		responses = []int{http.StatusBadGateway}
+		status.FailurePolicy = nil
+
+		deliver()
+		Expect(status.State).To(Equal(executionv1.Failed))
+		Expect(status.Retries).To(BeZero())
+	})
+
+	It("should not follow redirects", func() {
+		redirected := false
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			redirected = true
+		}))
+		defer target.Close()
+		redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer redirect.Close()
+		r.HTTPClient = nil
+		hookSpec.Webhook.URL = redirect.URL
+		status.FailurePolicy = nil
+
+		deliver()
+		Expect(status.State).To(Equal(executionv1.Failed))
+		Expect(status.HTTPStatus).To(BeEquivalentTo(http.StatusTemporaryRedirect))
+		Expect(redirected).To(BeFalse())
+	})
+
+	It("should count failures to read the secret against the retries of the webhook", func() {
+		hookSpec.Webhook.SecretRef.Name = "missing"
+
+		Expect(r.processWebhookHook(scan, status, hookSpec)).To(Succeed())
+		Expect(status.State).To(Equal(executionv1.Pending))
+		Expect(status.Retries).To(BeEquivalentTo(1))
+		Expect(receivedRequests()).To(BeEmpty())
+
+		status.Retries = retryCount
+		Expect(r.processWebhookHook(scan, status, hookSpec)).To(Succeed())
+		Expect(status.State).To(Equal(executionv1.Failed))
+	})
+
+	It("should send the webhook again if the request got lost by a restart of the operator", func() {
+		Expect(r.Create(context.Background(), &executionv1.ScanCompletionHook{
+			ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "default"},
+			Spec:       *hookSpec,
+		})).To(Succeed())
+		status.State = executionv1.InProgress
+
+		Expect(r.processInProgressHook(scan, status, "read-only-hook")).To(Succeed())
+		Expect(status.State).To(Equal(executionv1.InProgress))
+		Eventually(func() executionv1.HookState {
+			Expect(r.processInProgressHook(scan, status, "read-only-hook")).To(Succeed())
+			return status.State
+		}).Should(Equal(executionv1.Completed))
+		Expect(receivedRequests()).To(HaveLen(1))
+	})
+
+	It("should requeue scans while their webhook requests are in progress", func() {
+		release := make(chan struct{})
+		scan.Status.OrderedHookStatuses = [][]*executionv1.HookStatus{{status}}
+		r.webhookEvents = make(chan event.GenericEvent)
+
+		r.startWebhookDelivery(scan, status.HookName, func() (int, error) {
+			<-release
+			return http.StatusOK, nil
+		})
+		status.State = executionv1.InProgress
+		Expect(r.hasWebhookDeliveries(scan)).To(BeTrue())
+
+		close(release)
+		Eventually(func() executionv1.HookState {
+			Expect(r.processInProgressWebhookHook(scan, status, hookSpec)).To(Succeed())
+			return status.State
+		}).Should(Equal(executionv1.Completed))
+		Expect(r.hasWebhookDeliveries(scan)).To(BeFalse())
+	})
+
+	It("should only support ReadOnly hooks", func() {
+		hookSpec.Type = executionv1.ReadAndWrite
+		Expect(r.processWebhookHook(scan, status, hookSpec)).To(MatchError("webhook hook 'notify' must be of type ReadOnly"))
+		Expect(receivedRequests()).To(BeEmpty())
+	})
+})
@@ -14,15 +14,19 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	scancontroller "github.com/secureCodeBox/secureCodeBox/operator/controllers/execution/scans"
 	"github.com/secureCodeBox/secureCodeBox/operator/utils"
 )
 
@@ -72,10 +76,12 @@ func (r *ScheduledScanReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// Update Finding Summary of scan with the results of the latest successful Scan
 	if len(completedScans) >= 1 {
-		lastFindings := completedScans[len(completedScans)-1].Status.Findings
-		if !reflect.DeepEqual(lastFindings, scheduledScan.Status.Findings) {
+		lastScan := completedScans[len(completedScans)-1]
+		lastFindings := lastScan.Status.Findings
+		if !reflect.DeepEqual(lastFindings, scheduledScan.Status.Findings) || !reflect.DeepEqual(lastScan.Status.FindingsDiff, scheduledScan.Status.FindingsDiff) {
 			log.V(4).Info("Updating ScheduledScans Findings as they appear to have changed")
 			scheduledScan.Status.Findings = *lastFindings.DeepCopy()
+			scheduledScan.Status.FindingsDiff = lastScan.Status.FindingsDiff.DeepCopy()
 			if err := r.Status().Update(ctx, &scheduledScan); err != nil {
 				if apierrors.IsConflict(err) {
 					r.Log.V(4).Info(
@@ -92,64 +98,95 @@ func (r *ScheduledScanReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
-	// Delete Old Failed Scans when exceeding the history limit
-	var successfulHistoryLimit int32 = 3
-	if scheduledScan.Spec.SuccessfulJobsHistoryLimit != nil {
-		successfulHistoryLimit = *scheduledScan.Spec.SuccessfulJobsHistoryLimit
-	}
-	err := r.deleteOldScans(completedScans, successfulHistoryLimit)
+	// Delete Old Successful Scans when exceeding the history limit or the history retention
+	err := r.deleteOldScans(ctx, scheduledScan, getScansToDelete(scheduledScan, completedScans, scheduledScan.Spec.SuccessfulJobsHistoryLimit, 3, time.Now()))
 	if err != nil {
 		log.Error(err, "Failed to clean up old scan")
 		return ctrl.Result{}, err
 	}
 
-	// Delete Old Failed Scans when exceeding the history limit
+	// Delete Old Failed Scans when exceeding the history limit or the history retention
 	failedScans := getScansWithState(childScans.Items, "Errored")
-	var failedHistoryLimit int32 = 1
-	if scheduledScan.Spec.FailedJobsHistoryLimit != nil {
-		failedHistoryLimit = *scheduledScan.Spec.FailedJobsHistoryLimit
-	}
-	err = r.deleteOldScans(failedScans, failedHistoryLimit)
+	err = r.deleteOldScans(ctx, scheduledScan, getScansToDelete(scheduledScan, failedScans, scheduledScan.Spec.FailedJobsHistoryLimit, 1, time.Now()))
 	if err != nil {
 		log.Error(err, "Failed to clean up old scan")
 		return ctrl.Result{}, err
 	}
 
-	// Calculate the next schedule
-	nextSchedule, err := getNextSchedule(r, scheduledScan, time.Now())
+	// ScheduledScans with triggers or a httpTrigger but without a schedule or interval are only started by their triggers
+	triggeredOnly := (len(scheduledScan.Spec.Triggers) > 0 || scheduledScan.Spec.HTTPTrigger != nil) && scheduledScan.Spec.Schedule == "" && scheduledScan.Spec.Interval.Duration == 0
+
+	InProgressScans := getScansInProgress(childScans.Items)
+
+	timeZonesValid, err := r.updateTimeZoneValidCondition(ctx, &scheduledScan)
 	if err != nil {
-		log.Error(err, "Unable to calculate next schedule")
+		log.Error(err, "Unable to update the TimeZoneValid condition of the ScheduledScan")
 		return ctrl.Result{}, err
 	}
 
-	InProgressScans := getScansInProgress(childScans.Items)
-
 	// Check if the ScheduledScan is suspended. If so, skip creating new scans.
 	if scheduledScan.Spec.Suspend != nil && *scheduledScan.Spec.Suspend {
 		log.V(7).Info("ScheduledScan is suspended, skipping scan creation")
+		if err := r.updateNextScheduleTime(ctx, &scheduledScan, nil); err != nil {
+			log.Error(err, "Unable to update the next schedule time of the ScheduledScan")
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 	}
 
+	// Start scans for the completed scans of the ScheduledScans triggering this one
+	triggerRequeueAfter, err := r.startTriggeredScans(ctx, &scheduledScan, InProgressScans)
+	if err != nil {
+		log.Error(err, "Unable to start triggered Scan")
+		return ctrl.Result{}, err
+	}
+	// scans aren't scheduled until unknown time zones are fixed, which triggers a new reconcile
+	if triggeredOnly || !timeZonesValid {
+		if err := r.updateNextScheduleTime(ctx, &scheduledScan, nil); err != nil {
+			log.Error(err, "Unable to update the next schedule time of the ScheduledScan")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: triggerRequeueAfter}, nil
+	}
+
+	// Calculate the next schedule
+	nextSchedule, err := getNextSchedule(r, scheduledScan, time.Now())
+	if err != nil {
+		log.Error(err, "Unable to calculate next schedule")
+		return ctrl.Result{}, err
+	}
+
 	// check if it is time to start the next Scan
 	if !time.Now().Before(nextSchedule) {
-		// check concurrency policy
-		if scheduledScan.Spec.ConcurrencyPolicy == executionv1.ForbidConcurrent && len(InProgressScans) > 0 {
-			log.V(8).Info("concurrency policy blocks concurrent runs, skipping", "num active", len(InProgressScans))
-			r.Recorder.Event(&scheduledScan, "Normal", "ConcurrencyPolicyBlocks", "Concurrency policy blocks concurrent runs, skipping")
-			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+		// check for runs which were missed, e.g. because the operator wasn't running
+		dueRuns, err := getDueRuns(r, scheduledScan, time.Now())
+		if err != nil {
+			log.Error(err, "Unable to calculate the due runs")
+			return ctrl.Result{}, err
 		}
-
-		// ...or instruct us to replace existing ones...
-		if scheduledScan.Spec.ConcurrencyPolicy == executionv1.ReplaceConcurrent {
-			for _, scan := range InProgressScans {
-				// we don't care if the job was already deleted
-				if err := r.Delete(context.Background(), &scan, client.PropagationPolicy(metav1.DeletePropagationBackground)); (err) != nil {
-					log.Error(err, "unable to delete active job", "job", scan)
-					r.Recorder.Event(&scheduledScan, "Warning", "JobDeletionFailed", fmt.Sprintf("Unable to delete active job: %s, error: %v", scan.Name, err))
-					return ctrl.Result{}, err
-				}
-				r.Recorder.Event(&scheduledScan, "Normal", "JobReplaced", fmt.Sprintf("Active job %s replaced", scan.Name))
+		run, skippedRuns := selectDueRun(scheduledScan, dueRuns, time.Now())
+		if err := r.skipMissedRuns(ctx, &scheduledScan, skippedRuns); err != nil {
+			log.Error(err, "Unable to record the missed runs of the ScheduledScan")
+			return ctrl.Result{}, err
+		}
+		if run == nil {
+			nextSchedule, err = getNextSchedule(r, scheduledScan, time.Now())
+			if err != nil {
+				log.Error(err, "Unable to calculate next schedule")
+				return ctrl.Result{}, err
 			}
+			if err := r.updateNextScheduleTime(ctx, &scheduledScan, &nextSchedule); err != nil {
+				log.Error(err, "Unable to update the next schedule time of the ScheduledScan")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: nextSchedule.Sub(time.Now())}, nil
+		}
+
+		// check concurrency policy
+		if blocked, err := r.applyConcurrencyPolicy(&scheduledScan, InProgressScans); err != nil {
+			return ctrl.Result{}, err
+		} else if blocked {
+			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 		}
 
 		if scheduledScan.Spec.RetriggerOnScanTypeChange == true {
@@ -172,27 +209,18 @@ func (r *ScheduledScanReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 
 		// It's time!
-		var scan = &executionv1.Scan{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace:   scheduledScan.Namespace,
-				Labels:      scheduledScan.ObjectMeta.GetLabels(),
-				Annotations: getAnnotationsForScan(scheduledScan),
-			},
-			Spec: *scheduledScan.Spec.ScanSpec.DeepCopy(),
-		}
-		scan.Name = fmt.Sprintf("%s-%d", scheduledScan.Name, nextSchedule.Unix())
-		if err := ctrl.SetControllerReference(&scheduledScan, scan, r.Scheme); err != nil {
-			log.Error(err, "Unable to set owner reference on Scan")
-			return ctrl.Result{}, err
-		}
-
-		if err := r.Create(ctx, scan); err != nil {
-			log.Error(err, "Unable to create Scan for ScheduledScan")
+		scan, err := r.createScan(ctx, &scheduledScan, fmt.Sprintf("%s-%d", scheduledScan.Name, run.start.Unix()), nil)
+		if err != nil {
 			return ctrl.Result{}, err
 		}
 
 		var now metav1.Time = metav1.Now()
 		scheduledScan.Status.LastScheduleTime = &now
+		if len(dueRuns) > 0 && run.scheduled.Before(dueRuns[len(dueRuns)-1].scheduled) {
+			// catching up on missed runs, the following runs are started in the next reconciles
+			r.Recorder.Eventf(&scheduledScan, "Normal", "MissedRunStarted", "Started scan %s for the run scheduled for %s which was missed", scan.Name, run.scheduled.UTC().Format(time.RFC3339))
+			scheduledScan.Status.LastScheduleTime = &metav1.Time{Time: run.scheduled}
+		}
 		if err := r.Status().Update(ctx, &scheduledScan); err != nil {
 			if apierrors.IsConflict(err) {
 				r.Log.V(4).Info(
@@ -209,47 +237,404 @@ func (r *ScheduledScanReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 		// Recalculate next schedule
 		nextSchedule, err = getNextSchedule(r, scheduledScan, time.Now())
+		if err != nil {
+			log.Error(err, "Unable to calculate next schedule")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.updateNextScheduleTime(ctx, &scheduledScan, &nextSchedule); err != nil {
+		log.Error(err, "Unable to update the next schedule time of the ScheduledScan")
+		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: nextSchedule.Sub(time.Now())}, nil
+	requeueAfter := nextSchedule.Sub(time.Now())
+	if triggerRequeueAfter > 0 && triggerRequeueAfter < requeueAfter {
+		requeueAfter = triggerRequeueAfter
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-func getNextSchedule(r *ScheduledScanReconciler, scheduledScan executionv1.ScheduledScan, now time.Time) (next time.Time, err error) {
-	// check if the Cron schedule is set
-	if scheduledScan.Spec.Schedule != "" {
-		sched, err := cron.ParseStandard(scheduledScan.Spec.Schedule)
-		if err != nil {
-			r.Recorder.Event(&scheduledScan, "Warning", "ScheduleParseError", fmt.Sprintf("Unparseable schedule %q: %v", scheduledScan.Spec.Schedule, err))
-			return time.Time{}, fmt.Errorf("Unparseable schedule %q: %v", scheduledScan.Spec.Schedule, err)
+// applyConcurrencyPolicy checks if the concurrency policy of the ScheduledScan allows starting a new scan while other scans are in progress, and deletes them for the Replace policy
+func (r *ScheduledScanReconciler) applyConcurrencyPolicy(scheduledScan *executionv1.ScheduledScan, inProgressScans []executionv1.Scan) (blocked bool, err error) {
+	log := r.Log.WithValues("scheduledscan", types.NamespacedName{Name: scheduledScan.Name, Namespace: scheduledScan.Namespace})
+
+	if scheduledScan.Spec.ConcurrencyPolicy == executionv1.ForbidConcurrent && len(inProgressScans) > 0 {
+		log.V(8).Info("concurrency policy blocks concurrent runs, skipping", "num active", len(inProgressScans))
+		r.Recorder.Event(scheduledScan, "Normal", "ConcurrencyPolicyBlocks", "Concurrency policy blocks concurrent runs, skipping")
+		return true, nil
+	}
+
+	// ...or instruct us to replace existing ones...
+	if scheduledScan.Spec.ConcurrencyPolicy == executionv1.ReplaceConcurrent {
+		for _, scan := range inProgressScans {
+			// we don't care if the job was already deleted
+			if err := r.Delete(context.Background(), &scan, client.PropagationPolicy(metav1.DeletePropagationBackground)); (err) != nil {
+				log.Error(err, "unable to delete active job", "job", scan)
+				r.Recorder.Event(scheduledScan, "Warning", "JobDeletionFailed", fmt.Sprintf("Unable to delete active job: %s, error: %v", scan.Name, err))
+				return false, err
+			}
+			r.Recorder.Event(scheduledScan, "Normal", "JobReplaced", fmt.Sprintf("Active job %s replaced", scan.Name))
+		}
+	}
+	return false, nil
+}
+
+// createScan creates a scan for the ScheduledScan using its scanSpec. The env vars are added to the env of the scan
+func (r *ScheduledScanReconciler) createScan(ctx context.Context, scheduledScan *executionv1.ScheduledScan, name string, env []corev1.EnvVar) (*executionv1.Scan, error) {
+	var scan = &executionv1.Scan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   scheduledScan.Namespace,
+			Labels:      scheduledScan.ObjectMeta.GetLabels(),
+			Annotations: getAnnotationsForScan(*scheduledScan),
+		},
+		Spec: *scheduledScan.Spec.ScanSpec.DeepCopy(),
+	}
+	scan.Spec.Env = append(scan.Spec.Env, env...)
+	if err := ctrl.SetControllerReference(scheduledScan, scan, r.Scheme); err != nil {
+		r.Log.Error(err, "Unable to set owner reference on Scan")
+		return nil, err
+	}
+
+	if err := r.Create(ctx, scan); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			r.Log.Error(err, "Unable to create Scan for ScheduledScan")
 		}
+		return nil, err
+	}
+	return scan, nil
+}
+
+// updateNextScheduleTime sets the nextScheduleTime in the status of the ScheduledScan if it changed. Pass nil to unset it
+func (r *ScheduledScanReconciler) updateNextScheduleTime(ctx context.Context, scheduledScan *executionv1.ScheduledScan, next *time.Time) error {
+	var nextScheduleTime *metav1.Time
+	if next != nil {
+		// the status only stores seconds, truncate to not update the status on every reconcile
+		nextScheduleTime = &metav1.Time{Time: next.Truncate(time.Second)}
+	}
+	if nextScheduleTime.Equal(scheduledScan.Status.NextScheduleTime) {
+		return nil
+	}
+
+	oldScheduledScan := scheduledScan.DeepCopy()
+	scheduledScan.Status.NextScheduleTime = nextScheduleTime
+	return r.Status().Patch(ctx, scheduledScan, client.MergeFrom(oldScheduledScan))
+}
+
+// updateTimeZoneValidCondition checks if the time zones of the schedule and allowed windows of the ScheduledScan are known and sets the TimeZoneValid condition accordingly.
+// The pattern of the CRD only checks the syntax of time zones, names like 'Mars/Olympus' are only detected when loading them
+func (r *ScheduledScanReconciler) updateTimeZoneValidCondition(ctx context.Context, scheduledScan *executionv1.ScheduledScan) (bool, error) {
+	condition := metav1.Condition{
+		Type:               executionv1.TimeZoneValidCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "TimeZonesKnown",
+		Message:            "All time zones of the ScheduledScan are known",
+		ObservedGeneration: scheduledScan.Generation,
+	}
+	if err := validateTimeZones(*scheduledScan); err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "UnknownTimeZone"
+		condition.Message = err.Error()
+	}
+	valid := condition.Status == metav1.ConditionTrue
 
+	oldScheduledScan := scheduledScan.DeepCopy()
+	if !meta.SetStatusCondition(&scheduledScan.Status.Conditions, condition) {
+		return valid, nil
+	}
+	// the event is only recorded when the condition changes, not on every reconcile
+	if !valid {
+		r.Recorder.Event(scheduledScan, "Warning", "UnknownTimeZone", condition.Message)
+	}
+	return valid, r.Status().Patch(ctx, scheduledScan, client.MergeFrom(oldScheduledScan))
+}
+
+// validateTimeZones returns an error for the first unknown time zone of the schedule or allowed windows of the ScheduledScan
+func validateTimeZones(scheduledScan executionv1.ScheduledScan) error {
+	if scheduledScan.Spec.TimeZone != nil {
+		if _, err := utils.LoadTimeZone(*scheduledScan.Spec.TimeZone); err != nil {
+			return err
+		}
+	}
+	for i, window := range scheduledScan.Spec.AllowedWindows {
+		if window.TimeZone == "" {
+			continue
+		}
+		if _, err := utils.LoadTimeZone(window.TimeZone); err != nil {
+			return fmt.Errorf("invalid allowed window %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// getNextSchedule returns the time the next scan should be started, deferred by the allowed windows and blackouts of the ScheduledScan
+func getNextSchedule(r *ScheduledScanReconciler, scheduledScan executionv1.ScheduledScan, now time.Time) (time.Time, error) {
+	scheduled, err := getScheduledTime(r, scheduledScan, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next, reason, err := deferScheduledTime(scheduledScan, scheduled)
+	if err != nil {
+		r.Recorder.Event(&scheduledScan, "Warning", "InvalidScheduleWindow", err.Error())
+		return time.Time{}, err
+	}
+	// the deferral is reported once when the next schedule time changes, not on every reconcile
+	if reason != "" && !next.Truncate(time.Second).Equal(scheduledTimeOf(scheduledScan.Status.NextScheduleTime)) {
+		r.Recorder.Eventf(&scheduledScan, "Normal", "ScanDeferred", "Scan scheduled for %s is deferred to %s: %s", scheduled.UTC().Format(time.RFC3339), next.UTC().Format(time.RFC3339), reason)
+	}
+	return next, nil
+}
+
+// deferScheduledTime applies the jitter, allowed windows and blackouts of the ScheduledScan to the scheduled time.
+// The reason is only set if the scan is deferred by a window or blackout
+func deferScheduledTime(scheduledScan executionv1.ScheduledScan, scheduled time.Time) (time.Time, string, error) {
+	scheduled = spreadScheduledTime(scheduledScan, scheduled)
+
+	// windows without a time zone use the time zone of the schedule
+	windows := make([]executionv1.TimeWindow, len(scheduledScan.Spec.AllowedWindows))
+	for i, window := range scheduledScan.Spec.AllowedWindows {
+		windows[i] = *window.DeepCopy()
+		if windows[i].TimeZone == "" && scheduledScan.Spec.TimeZone != nil {
+			windows[i].TimeZone = *scheduledScan.Spec.TimeZone
+		}
+	}
+
+	return utils.NextAllowedTime(scheduled, windows, scheduledScan.Spec.Blackouts)
+}
+
+// scheduledTimeOf returns the time of a schedule time from the status of the ScheduledScan, or the zero time if it isn't set
+func scheduledTimeOf(scheduleTime *metav1.Time) time.Time {
+	if scheduleTime == nil {
+		return time.Time{}
+	}
+	return scheduleTime.Time
+}
+
+// getScheduledTime returns the time the next scan is due according to the schedule or interval of the ScheduledScan
+func getScheduledTime(r *ScheduledScanReconciler, scheduledScan executionv1.ScheduledScan, now time.Time) (next time.Time, err error) {
+	lastHandledTime := getLastHandledTime(scheduledScan)
+
+	// check if the Cron schedule is set
+	if scheduledScan.Spec.Schedule != "" {
 		// for optimization purposes, cheat a bit and start from our last observed run time
 		// we could reconstitute this here, but there's not much point, since we've
 		// just updated it.
 		var earliestTime time.Time
-		if scheduledScan.Status.LastScheduleTime != nil {
-			earliestTime = scheduledScan.Status.LastScheduleTime.Time
+		if lastHandledTime != nil {
+			earliestTime = *lastHandledTime
 		} else {
 			earliestTime = scheduledScan.ObjectMeta.CreationTimestamp.Time
 		}
 		if earliestTime.After(now) {
-			return sched.Next(now), nil
+			return getScheduledTimeAfter(r, scheduledScan, now)
 		}
-		return sched.Next(earliestTime), nil
+		return getScheduledTimeAfter(r, scheduledScan, earliestTime)
 	}
 	if scheduledScan.Spec.Interval.Duration > 0 {
-		var nextSchedule time.Time
-		if scheduledScan.Status.LastScheduleTime != nil {
-			nextSchedule = scheduledScan.Status.LastScheduleTime.Add(scheduledScan.Spec.Interval.Duration)
-		} else {
-			nextSchedule = time.Now().Add(-1 * time.Second)
+		if lastHandledTime != nil {
+			return getScheduledTimeAfter(r, scheduledScan, *lastHandledTime)
+		}
+		if scheduledScan.Spec.SpreadMode == executionv1.HashSpread {
+			return getScheduledTimeAfter(r, scheduledScan, scheduledScan.ObjectMeta.CreationTimestamp.Time)
 		}
-		return nextSchedule, nil
+		if scheduledScan.Spec.Jitter != nil {
+			// use a fixed time for the first scan, otherwise the jitter would change on every reconcile
+			return scheduledScan.ObjectMeta.CreationTimestamp.Time, nil
+		}
+		return time.Now().Add(-1 * time.Second), nil
 	}
 	r.Recorder.Event(&scheduledScan, "Warning", "NoScheduleOrInterval", "No valid schedule or interval found")
 	return time.Time{}, fmt.Errorf("No schedule or interval found")
 }
 
+// getScheduledTimeAfter returns the first time after the given time a scan is due according to the schedule or interval of the ScheduledScan
+func getScheduledTimeAfter(r *ScheduledScanReconciler, scheduledScan executionv1.ScheduledScan, after time.Time) (time.Time, error) {
+	if scheduledScan.Spec.Schedule != "" {
+		sched, err := cron.ParseStandard(scheduledScan.Spec.Schedule)
+		if err != nil {
+			r.Recorder.Event(&scheduledScan, "Warning", "ScheduleParseError", fmt.Sprintf("Unparseable schedule %q: %v", scheduledScan.Spec.Schedule, err))
+			return time.Time{}, fmt.Errorf("Unparseable schedule %q: %v", scheduledScan.Spec.Schedule, err)
+		}
+
+		// the schedule is evaluated in the location of the times passed to it
+		location := time.Local
+		if scheduledScan.Spec.TimeZone != nil {
+			location, err = utils.LoadTimeZone(*scheduledScan.Spec.TimeZone)
+			if err != nil {
+				return time.Time{}, err
+			}
+		}
+		return sched.Next(after.In(location)), nil
+	}
+	if scheduledScan.Spec.Interval.Duration > 0 {
+		interval := scheduledScan.Spec.Interval.Duration
+		if scheduledScan.Spec.SpreadMode == executionv1.HashSpread {
+			// interval scans are started at fixed slots, so that scans with the same interval are spread evenly across it
+			window := interval
+			if scheduledScan.Spec.Jitter != nil && scheduledScan.Spec.Jitter.Duration > 0 && scheduledScan.Spec.Jitter.Duration < interval {
+				window = scheduledScan.Spec.Jitter.Duration
+			}
+			return utils.NextSpreadSlot(after, interval, utils.SpreadOffset(spreadKey(scheduledScan), window)), nil
+		}
+		return after.Add(interval), nil
+	}
+	r.Recorder.Event(&scheduledScan, "Warning", "NoScheduleOrInterval", "No valid schedule or interval found")
+	return time.Time{}, fmt.Errorf("No schedule or interval found")
+}
+
+// getLastHandledTime returns the time of the last run which was either started or skipped, or nil if there was none
+func getLastHandledTime(scheduledScan executionv1.ScheduledScan) *time.Time {
+	var last *time.Time
+	if scheduledScan.Status.LastScheduleTime != nil {
+		last = &scheduledScan.Status.LastScheduleTime.Time
+	}
+	if scheduledScan.Status.LastMissedTime != nil && (last == nil || scheduledScan.Status.LastMissedTime.After(*last)) {
+		last = &scheduledScan.Status.LastMissedTime.Time
+	}
+	return last
+}
+
+// maxDueTicks limits the number of scheduled times considered in a single reconcile, e.g. for a schedule running every minute after a long downtime of the operator
+const maxDueTicks = 1000
+
+// defaultMaxMissedRuns is the number of missed runs started with the missedRunPolicy RunAll if maxMissedRuns isn't set
+const defaultMaxMissedRuns = 10
+
+// dueRun is a run of a ScheduledScan which is due.
+// Scheduled times which are deferred to the same time by the allowed windows or blackouts, e.g. to the opening of a window, are served by a single run.
+type dueRun struct {
+	// scheduled is the latest time the run was scheduled for by the schedule or interval
+	scheduled time.Time
+	// firstScheduled is the earliest time the run was scheduled for
+	firstScheduled time.Time
+	// ticks is the number of scheduled times served by the run
+	ticks int
+	// start is the time the run should have been started, after applying the jitter, allowed windows and blackouts
+	start time.Time
+}
+
+// getDueRuns returns the runs which are due since the last run was started or skipped, oldest first
+func getDueRuns(r *ScheduledScanReconciler, scheduledScan executionv1.ScheduledScan, now time.Time) ([]dueRun, error) {
+	scheduled, err := getScheduledTime(r, scheduledScan, now)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []dueRun{}
+	for ticks := 0; ticks < maxDueTicks; ticks++ {
+		start, _, err := deferScheduledTime(scheduledScan, scheduled)
+		if err != nil {
+			return nil, err
+		}
+		if start.After(now) {
+			break
+		}
+		if last := len(runs) - 1; last >= 0 && runs[last].start.Equal(start) {
+			runs[last].scheduled = scheduled
+			runs[last].ticks++
+		} else {
+			runs = append(runs, dueRun{scheduled: scheduled, firstScheduled: scheduled, ticks: 1, start: start})
+		}
+
+		if scheduled, err = getScheduledTimeAfter(r, scheduledScan, scheduled); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+// selectDueRun decides which of the due runs is started according to the startingDeadlineSeconds and missedRunPolicy of the ScheduledScan, and which are skipped.
+// Returns nil if no run should be started. The returned run is always the oldest due run which isn't skipped.
+func selectDueRun(scheduledScan executionv1.ScheduledScan, runs []dueRun, now time.Time) (*dueRun, []dueRun) {
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	// runs which the operator didn't plan, e.g. because the ScheduledScan was retriggered by moving the lastScheduleTime into the past or was suspended, aren't missed
+	if scheduledScan.Status.NextScheduleTime == nil || runs[0].start.Truncate(time.Second).Before(scheduledScan.Status.NextScheduleTime.Time) {
+		return &runs[len(runs)-1], nil
+	}
+
+	// all runs except for the latest one are superseded by a later run, the latest one is missed if it can't be started within the deadline
+	latest := runs[len(runs)-1]
+	missed := runs[:len(runs)-1]
+	if deadline := scheduledScan.Spec.StartingDeadlineSeconds; deadline != nil && now.Sub(latest.start) > time.Duration(*deadline)*time.Second {
+		missed = runs
+	}
+
+	switch scheduledScan.Spec.MissedRunPolicy {
+	case executionv1.SkipMissedRuns:
+		if len(missed) == len(runs) {
+			return nil, missed
+		}
+		return &latest, missed
+	case executionv1.RunAllMissedRuns:
+		maxMissedRuns := defaultMaxMissedRuns
+		if scheduledScan.Spec.MaxMissedRuns != nil {
+			maxMissedRuns = int(*scheduledScan.Spec.MaxMissedRuns)
+		}
+		skipped := []dueRun{}
+		if len(missed) > maxMissedRuns {
+			skipped = missed[:len(missed)-maxMissedRuns]
+		}
+		if len(skipped) == len(runs) {
+			return nil, skipped
+		}
+		return &runs[len(skipped)], skipped
+	default:
+		// the latest run is started even if it missed the deadline, so that the missed runs are caught up once
+		return &latest, runs[:len(runs)-1]
+	}
+}
+
+// skipMissedRuns records the skipped runs in the status of the ScheduledScan, so that they aren't considered again
+func (r *ScheduledScanReconciler) skipMissedRuns(ctx context.Context, scheduledScan *executionv1.ScheduledScan, skipped []dueRun) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+	// every scheduled time served by a skipped run passed without a scan
+	missedTicks := 0
+	for _, run := range skipped {
+		missedTicks += run.ticks
+	}
+	first := skipped[0].firstScheduled.UTC().Format(time.RFC3339)
+	last := skipped[len(skipped)-1].scheduled.UTC().Format(time.RFC3339)
+	if missedTicks == 1 {
+		r.Recorder.Eventf(scheduledScan, "Warning", "MissedRun", "Skipped the run scheduled for %s which was missed", first)
+	} else {
+		r.Recorder.Eventf(scheduledScan, "Warning", "MissedRun", "Skipped %d runs scheduled between %s and %s which were missed", missedTicks, first, last)
+	}
+
+	oldScheduledScan := scheduledScan.DeepCopy()
+	scheduledScan.Status.MissedRuns += int64(missedTicks)
+	scheduledScan.Status.LastMissedTime = &metav1.Time{Time: skipped[len(skipped)-1].scheduled}
+	return r.Status().Patch(ctx, scheduledScan, client.MergeFrom(oldScheduledScan))
+}
+
+// spreadScheduledTime offsets the scheduled time by the jitter of the ScheduledScan.
+// The random offset is derived from the uid of the ScheduledScan and the scheduled time, so that it doesn't change between reconciles.
+func spreadScheduledTime(scheduledScan executionv1.ScheduledScan, scheduled time.Time) time.Time {
+	if scheduledScan.Spec.Jitter == nil || scheduledScan.Spec.Jitter.Duration <= 0 {
+		return scheduled
+	}
+	if scheduledScan.Spec.SpreadMode == executionv1.HashSpread {
+		if scheduledScan.Spec.Schedule == "" {
+			// interval scans are already placed in their slot by getScheduledTime
+			return scheduled
+		}
+		return scheduled.Add(utils.SpreadOffset(spreadKey(scheduledScan), scheduledScan.Spec.Jitter.Duration))
+	}
+	key := fmt.Sprintf("%s/%d", scheduledScan.UID, scheduled.Unix())
+	return scheduled.Add(utils.SpreadOffset(key, scheduledScan.Spec.Jitter.Duration))
+}
+
+// spreadKey identifies the ScheduledScan for the hash based spread
+func spreadKey(scheduledScan executionv1.ScheduledScan) string {
+	return scheduledScan.Namespace + "/" + scheduledScan.Name
+}
+
 // Copy over securecodebox.io annotations from the scheduledScan to the created scan
 func getAnnotationsForScan(scheduledScan executionv1.ScheduledScan) map[string]string {
 	annotations := map[string]string{}
@@ -300,13 +685,21 @@ func getScansInProgress(scans []executionv1.Scan) []executionv1.Scan {
 	return newScans
 }
 
-// DeleteOldScans when exceeding the history limit
-func (r *ScheduledScanReconciler) deleteOldScans(scans []executionv1.Scan, maxCount int32) error {
-	for i, scan := range scans {
-		if int32(i) >= int32(len(scans))-maxCount {
-			break
+// deleteOldScans deletes the scans exceeding the history retention. If the historyRetention of the ScheduledScan enables archiving, the scans are annotated first, so that their files are archived by the finalizer of the scan
+func (r *ScheduledScanReconciler) deleteOldScans(ctx context.Context, scheduledScan executionv1.ScheduledScan, scans []executionv1.Scan) error {
+	archive := scheduledScan.Spec.HistoryRetention != nil && scheduledScan.Spec.HistoryRetention.Archive
+	for _, scan := range scans {
+		if archive && scan.Annotations[scancontroller.ArchiveFilesAnnotation] != "true" {
+			patch := client.MergeFrom(scan.DeepCopy())
+			metav1.SetMetaDataAnnotation(&scan.ObjectMeta, scancontroller.ArchiveFilesAnnotation, "true")
+			if err := r.Patch(ctx, &scan, patch); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
 		}
-		if err := r.Delete(context.Background(), &scan, client.PropagationPolicy(metav1.DeletePropagationBackground)); (err) != nil {
+		if err := r.Delete(ctx, &scan, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
 			return err
 		}
 	}
@@ -339,5 +732,6 @@ func (r *ScheduledScanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&executionv1.ScheduledScan{}).
 		Owns(&executionv1.Scan{}).
+		Watches(&executionv1.Scan{}, handler.EnqueueRequestsFromMapFunc(r.getTriggeredScheduledScans)).
 		Complete(r)
 }
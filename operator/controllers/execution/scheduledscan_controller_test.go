@@ -9,6 +9,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -16,8 +17,11 @@ import (
 	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -245,4 +249,379 @@ var _ = Describe("ScheduledScan controller", func() {
 			}
 		})
 	})
+
+	Context("Time Zones", func() {
+		var r *ScheduledScanReconciler
+
+		BeforeEach(func() {
+			r = &ScheduledScanReconciler{Recorder: record.NewFakeRecorder(10)}
+		})
+
+		newScheduledScan := func(schedule string, timeZone *string, lastScheduleTime time.Time) executionv1.ScheduledScan {
+			return executionv1.ScheduledScan{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "test-namespace"},
+				Spec: executionv1.ScheduledScanSpec{
+					Schedule: schedule,
+					TimeZone: timeZone,
+					ScanSpec: &executionv1.ScanSpec{ScanType: "nmap", Parameters: []string{"scanme.nmap.org"}},
+				},
+				Status: executionv1.ScheduledScanStatus{LastScheduleTime: &metav1.Time{Time: lastScheduleTime}},
+			}
+		}
+
+		It("should evaluate the schedule in the configured time zone and follow daylight saving time", func() {
+			timeZone := "Europe/Berlin"
+			// the night before the switch to winter time on the 25th of October 2026
+			lastScheduleTime := time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC)
+
+			next, err := getNextSchedule(r, newScheduledScan("0 4 * * *", &timeZone, lastScheduleTime), lastScheduleTime)
+			Expect(err).NotTo(HaveOccurred())
+			// 04:00 CEST
+			Expect(next.UTC()).To(Equal(time.Date(2026, 10, 24, 2, 0, 0, 0, time.UTC)))
+
+			next, err = getNextSchedule(r, newScheduledScan("0 4 * * *", &timeZone, next), next)
+			Expect(err).NotTo(HaveOccurred())
+			// 04:00 CET
+			Expect(next.UTC()).To(Equal(time.Date(2026, 10, 25, 3, 0, 0, 0, time.UTC)))
+		})
+
+		It("should apply the time zone of the schedule to allowed windows without a time zone", func() {
+			timeZone := "Europe/Berlin"
+			lastScheduleTime := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+			scheduledScan := newScheduledScan("0 12 * * *", &timeZone, lastScheduleTime)
+			scheduledScan.Spec.AllowedWindows = []executionv1.TimeWindow{{Start: "22:00", End: "06:00"}}
+
+			next, err := getNextSchedule(r, scheduledScan, lastScheduleTime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next.UTC()).To(Equal(time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)))
+		})
+
+		It("should return an error for unknown time zones", func() {
+			timeZone := "Mars/Olympus_Mons"
+			_, err := getNextSchedule(r, newScheduledScan("0 2 * * *", &timeZone, time.Now()), time.Now())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix(`unknown time zone "Mars/Olympus_Mons"`))
+		})
+
+		It("should report unknown time zones in the TimeZoneValid condition and record the event only once", func() {
+			timeZone := "Mars/Olympus_Mons"
+			scheduledScan := newScheduledScan("0 2 * * *", &timeZone, time.Now())
+			scheme := runtime.NewScheme()
+			Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+			recorder := record.NewFakeRecorder(10)
+			r = &ScheduledScanReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(&scheduledScan).WithStatusSubresource(&scheduledScan).Build(),
+				Recorder: recorder,
+			}
+
+			valid, err := r.updateTimeZoneValidCondition(context.Background(), &scheduledScan)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeFalse())
+			Expect(recorder.Events).To(Receive(HavePrefix(`Warning UnknownTimeZone unknown time zone "Mars/Olympus_Mons"`)))
+
+			var updated executionv1.ScheduledScan
+			Expect(r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "test-namespace"}, &updated)).To(Succeed())
+			Expect(updated.Status.Conditions).To(HaveLen(1))
+			Expect(updated.Status.Conditions[0].Type).To(Equal(executionv1.TimeZoneValidCondition))
+			Expect(updated.Status.Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+			Expect(updated.Status.Conditions[0].Reason).To(Equal("UnknownTimeZone"))
+
+			valid, err = r.updateTimeZoneValidCondition(context.Background(), &updated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeFalse())
+			Expect(recorder.Events).NotTo(Receive())
+
+			timeZone = "Europe/Berlin"
+			updated.Spec.TimeZone = &timeZone
+			valid, err = r.updateTimeZoneValidCondition(context.Background(), &updated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeTrue())
+			Expect(updated.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should reject the Local time zone and unknown time zones of allowed windows", func() {
+			local := "Local"
+			Expect(validateTimeZones(newScheduledScan("0 2 * * *", &local, time.Now()))).To(MatchError(`unknown time zone "Local": omit the time zone to use the time zone of the operator`))
+
+			scheduledScan := newScheduledScan("0 2 * * *", nil, time.Now())
+			scheduledScan.Spec.AllowedWindows = []executionv1.TimeWindow{{Start: "22:00", End: "06:00", TimeZone: "Mars/Olympus_Mons"}}
+			err := validateTimeZones(scheduledScan)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix(`invalid allowed window 0: unknown time zone "Mars/Olympus_Mons"`))
+		})
+	})
+
+	Context("Jitter and Spread", func() {
+		var r *ScheduledScanReconciler
+
+		BeforeEach(func() {
+			r = &ScheduledScanReconciler{Recorder: record.NewFakeRecorder(10)}
+		})
+
+		newScheduledScan := func(name string, interval time.Duration, jitter *metav1.Duration, spreadMode executionv1.SpreadMode, lastScheduleTime time.Time) executionv1.ScheduledScan {
+			return executionv1.ScheduledScan{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-namespace", UID: types.UID(name)},
+				Spec: executionv1.ScheduledScanSpec{
+					Interval:   metav1.Duration{Duration: interval},
+					Jitter:     jitter,
+					SpreadMode: spreadMode,
+					ScanSpec:   &executionv1.ScanSpec{ScanType: "nmap", Parameters: []string{"scanme.nmap.org"}},
+				},
+				Status: executionv1.ScheduledScanStatus{LastScheduleTime: &metav1.Time{Time: lastScheduleTime}},
+			}
+		}
+		lastScheduleTime := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		It("should offset the scheduled time by at most the jitter", func() {
+			scheduledScan := newScheduledScan("jittered", time.Hour, &metav1.Duration{Duration: 10 * time.Minute}, executionv1.RandomSpread, lastScheduleTime)
+
+			next, err := getNextSchedule(r, scheduledScan, lastScheduleTime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next).To(BeTemporally(">=", lastScheduleTime.Add(time.Hour)))
+			Expect(next).To(BeTemporally("<", lastScheduleTime.Add(70*time.Minute)))
+
+			// the offset must not change between reconciles
+			again, err := getNextSchedule(r, scheduledScan, lastScheduleTime.Add(time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(next))
+		})
+
+		It("should spread scans with the same interval evenly across the interval in the hash mode", func() {
+			quarters := map[time.Duration]int{}
+			for i := 0; i < 200; i++ {
+				scheduledScan := newScheduledScan(fmt.Sprintf("scan-%d", i), 24*time.Hour, nil, executionv1.HashSpread, lastScheduleTime)
+
+				next, err := getNextSchedule(r, scheduledScan, lastScheduleTime)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(next).To(BeTemporally(">", lastScheduleTime))
+				Expect(next).To(BeTemporally("<=", lastScheduleTime.Add(24*time.Hour)))
+				quarters[next.Sub(lastScheduleTime)/(6*time.Hour)]++
+			}
+			Expect(quarters).To(HaveLen(4))
+		})
+
+		It("should start hash spread scans at the same slot every interval", func() {
+			scheduledScan := newScheduledScan("slotted", 24*time.Hour, nil, executionv1.HashSpread, lastScheduleTime)
+			next, err := getNextSchedule(r, scheduledScan, lastScheduleTime)
+			Expect(err).NotTo(HaveOccurred())
+
+			// the scan is started a bit after its slot
+			scheduledScan.Status.LastScheduleTime = &metav1.Time{Time: next.Add(3 * time.Second)}
+			following, err := getNextSchedule(r, scheduledScan, next)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(following).To(Equal(next.Add(24 * time.Hour)))
+		})
+
+		It("should limit the hash spread of interval scans to the jitter", func() {
+			scheduledScan := newScheduledScan("limited", 24*time.Hour, &metav1.Duration{Duration: time.Hour}, executionv1.HashSpread, lastScheduleTime)
+
+			next, err := getNextSchedule(r, scheduledScan, lastScheduleTime)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next).To(BeTemporally(">=", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)))
+			Expect(next).To(BeTemporally("<", time.Date(2026, 10, 20, 1, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Context("Missed Runs", func() {
+		var r *ScheduledScanReconciler
+
+		BeforeEach(func() {
+			r = &ScheduledScanReconciler{Recorder: record.NewFakeRecorder(10)}
+		})
+
+		// the operator was down from 02:30 until 12:30, missing the runs from 03:00 until 12:00
+		lastScheduleTime := time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+		plannedTime := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+		now := time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+
+		newScheduledScan := func(policy executionv1.MissedRunPolicy, startingDeadlineSeconds *int64) executionv1.ScheduledScan {
+			return executionv1.ScheduledScan{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "test-namespace"},
+				Spec: executionv1.ScheduledScanSpec{
+					Schedule:                "0 * * * *",
+					MissedRunPolicy:         policy,
+					StartingDeadlineSeconds: startingDeadlineSeconds,
+					ScanSpec:                &executionv1.ScanSpec{ScanType: "nmap", Parameters: []string{"scanme.nmap.org"}},
+				},
+				Status: executionv1.ScheduledScanStatus{
+					LastScheduleTime: &metav1.Time{Time: lastScheduleTime},
+					NextScheduleTime: &metav1.Time{Time: plannedTime},
+				},
+			}
+		}
+		hour := func(hour int) time.Time {
+			return time.Date(2026, 10, 19, hour, 0, 0, 0, time.UTC)
+		}
+		scheduledTimes := func(runs []dueRun) []time.Time {
+			times := []time.Time{}
+			for _, run := range runs {
+				times = append(times, run.scheduled.UTC())
+			}
+			return times
+		}
+		fiveMinutes := int64(5 * 60)
+
+		It("should list all runs which are due", func() {
+			runs, err := getDueRuns(r, newScheduledScan(executionv1.RunOnceMissedRuns, nil), now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runs).To(HaveLen(10))
+			Expect(runs[0].scheduled).To(BeTemporally("==", hour(3)))
+			Expect(runs[9].scheduled).To(BeTemporally("==", hour(12)))
+		})
+
+		It("should start a single scan for the missed runs with the RunOnce policy", func() {
+			scheduledScan := newScheduledScan(executionv1.RunOnceMissedRuns, &fiveMinutes)
+			runs, err := getDueRuns(r, scheduledScan, now)
+			Expect(err).NotTo(HaveOccurred())
+
+			run, skipped := selectDueRun(scheduledScan, runs, now)
+			Expect(run).NotTo(BeNil())
+			Expect(run.scheduled).To(BeTemporally("==", hour(12)))
+			Expect(skipped).To(HaveLen(9))
+		})
+
+		It("should skip runs which missed the starting deadline with the Skip policy", func() {
+			scheduledScan := newScheduledScan(executionv1.SkipMissedRuns, &fiveMinutes)
+			runs, err := getDueRuns(r, scheduledScan, now)
+			Expect(err).NotTo(HaveOccurred())
+
+			run, skipped := selectDueRun(scheduledScan, runs, now)
+			Expect(run).To(BeNil())
+			Expect(skipped).To(HaveLen(10))
+		})
+
+		It("should start the latest run with the Skip policy if it is within the starting deadline", func() {
+			scheduledScan := newScheduledScan(executionv1.SkipMissedRuns, &fiveMinutes)
+			runs, err := getDueRuns(r, scheduledScan, hour(12).Add(time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+
+			run, skipped := selectDueRun(scheduledScan, runs, hour(12).Add(time.Minute))
+			Expect(run).NotTo(BeNil())
+			Expect(run.scheduled).To(BeTemporally("==", hour(12)))
+			Expect(skipped).To(HaveLen(9))
+		})
+
+		It("should start the most recent missed runs one after another with the RunAll policy", func() {
+			maxMissedRuns := int32(3)
+			scheduledScan := newScheduledScan(executionv1.RunAllMissedRuns, &fiveMinutes)
+			scheduledScan.Spec.MaxMissedRuns = &maxMissedRuns
+			runs, err := getDueRuns(r, scheduledScan, now)
+			Expect(err).NotTo(HaveOccurred())
+
+			run, skipped := selectDueRun(scheduledScan, runs, now)
+			Expect(run).NotTo(BeNil())
+			Expect(run.scheduled).To(BeTemporally("==", hour(10)))
+			Expect(scheduledTimes(skipped)).To(Equal([]time.Time{hour(3), hour(4), hour(5), hour(6), hour(7), hour(8), hour(9)}))
+
+			// the next reconcile continues with the following run
+			scheduledScan.Status.LastScheduleTime = &metav1.Time{Time: run.scheduled}
+			scheduledScan.Status.LastMissedTime = &metav1.Time{Time: hour(9)}
+			scheduledScan.Status.NextScheduleTime = &metav1.Time{Time: hour(11)}
+			runs, err = getDueRuns(r, scheduledScan, now)
+			Expect(err).NotTo(HaveOccurred())
+
+			run, skipped = selectDueRun(scheduledScan, runs, now)
+			Expect(run).NotTo(BeNil())
+			Expect(run.scheduled).To(BeTemporally("==", hour(11)))
+			Expect(skipped).To(BeEmpty())
+		})
+
+		It("should not consider runs as missed which weren't planned, e.g. when the ScheduledScan was retriggered", func() {
+			scheduledScan := newScheduledScan(executionv1.SkipMissedRuns, &fiveMinutes)
+			scheduledScan.Status.NextScheduleTime = &metav1.Time{Time: hour(13)}
+			runs, err := getDueRuns(r, scheduledScan, now)
+			Expect(err).NotTo(HaveOccurred())
+
+			run, skipped := selectDueRun(scheduledScan, runs, now)
+			Expect(run).NotTo(BeNil())
+			Expect(run.scheduled).To(BeTemporally("==", hour(12)))
+			Expect(skipped).To(BeEmpty())
+		})
+
+		Context("with allowed windows", func() {
+			day := func(day int, hour int) time.Time {
+				return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC)
+			}
+			newWindowedScheduledScan := func(policy executionv1.MissedRunPolicy) executionv1.ScheduledScan {
+				scheduledScan := newScheduledScan(policy, &fiveMinutes)
+				scheduledScan.Spec.AllowedWindows = []executionv1.TimeWindow{{Start: "22:00", End: "23:00"}}
+				scheduledScan.Status.LastScheduleTime = &metav1.Time{Time: day(18, 23)}
+				scheduledScan.Status.NextScheduleTime = &metav1.Time{Time: day(19, 22)}
+				return scheduledScan
+			}
+
+			It("should serve the runs deferred to the opening of the window with a single run", func() {
+				for _, policy := range []executionv1.MissedRunPolicy{executionv1.RunOnceMissedRuns, executionv1.SkipMissedRuns, executionv1.RunAllMissedRuns} {
+					scheduledScan := newWindowedScheduledScan(policy)
+					runs, err := getDueRuns(r, scheduledScan, day(19, 22).Add(time.Minute))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(runs).To(HaveLen(1))
+					Expect(runs[0].ticks).To(Equal(23))
+					Expect(runs[0].firstScheduled).To(BeTemporally("==", day(19, 0)))
+					Expect(runs[0].scheduled).To(BeTemporally("==", day(19, 22)))
+
+					run, skipped := selectDueRun(scheduledScan, runs, day(19, 22).Add(time.Minute))
+					Expect(run).NotTo(BeNil(), string(policy))
+					Expect(run.start).To(BeTemporally("==", day(19, 22)))
+					Expect(skipped).To(BeEmpty(), string(policy))
+				}
+			})
+
+			It("should only count the scheduled times of window openings which passed without a scan as missed", func() {
+				scheduledScan := newWindowedScheduledScan(executionv1.SkipMissedRuns)
+				scheduledScan.Name = "nmap"
+				scheduledScan.Namespace = "default"
+				scheme := runtime.NewScheme()
+				Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+				recorder := record.NewFakeRecorder(10)
+				r = &ScheduledScanReconciler{
+					Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(&scheduledScan).WithStatusSubresource(&scheduledScan).Build(),
+					Recorder: recorder,
+				}
+
+				// the operator was down during the window of the 19th, the window of the 20th is still open
+				now := day(20, 22).Add(time.Minute)
+				runs, err := getDueRuns(r, scheduledScan, now)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(runs).To(HaveLen(2))
+
+				run, skipped := selectDueRun(scheduledScan, runs, now)
+				Expect(run).NotTo(BeNil())
+				Expect(run.start).To(BeTemporally("==", day(20, 22)))
+				Expect(skipped).To(HaveLen(1))
+
+				Expect(r.skipMissedRuns(context.Background(), &scheduledScan, skipped)).To(Succeed())
+				Expect(scheduledScan.Status.MissedRuns).To(Equal(int64(23)))
+				Expect(scheduledScan.Status.LastMissedTime.Time).To(BeTemporally("==", day(19, 22)))
+				Expect(recorder.Events).To(Receive(Equal("Warning MissedRun Skipped 23 runs scheduled between 2026-10-19T00:00:00Z and 2026-10-19T22:00:00Z which were missed")))
+			})
+
+			It("should only report a deferral when the next schedule time changes", func() {
+				recorder := record.NewFakeRecorder(10)
+				r = &ScheduledScanReconciler{Recorder: recorder}
+				scheduledScan := newWindowedScheduledScan(executionv1.RunOnceMissedRuns)
+				scheduledScan.Status.LastScheduleTime = &metav1.Time{Time: day(19, 22)}
+				scheduledScan.Status.NextScheduleTime = nil
+
+				next, err := getNextSchedule(r, scheduledScan, day(19, 22).Add(time.Minute))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(next).To(BeTemporally("==", day(20, 22)))
+				Expect(recorder.Events).To(Receive(HavePrefix("Normal ScanDeferred Scan scheduled for 2026-10-19T23:00:00Z is deferred to 2026-10-20T22:00:00Z")))
+
+				scheduledScan.Status.NextScheduleTime = &metav1.Time{Time: next}
+				_, err = getNextSchedule(r, scheduledScan, day(19, 23).Add(time.Minute))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(recorder.Events).NotTo(Receive())
+			})
+		})
+
+		It("should continue after the last missed run", func() {
+			scheduledScan := newScheduledScan(executionv1.SkipMissedRuns, &fiveMinutes)
+			scheduledScan.Status.LastMissedTime = &metav1.Time{Time: hour(12)}
+
+			next, err := getNextSchedule(r, scheduledScan, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next).To(BeTemporally("==", hour(13)))
+		})
+	})
 })
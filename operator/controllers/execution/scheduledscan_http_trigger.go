@@ -0,0 +1,352 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+)
+
+// maxTriggerRequestSize limits the size of the body of trigger requests
+const maxTriggerRequestSize = 1 << 20
+
+const (
+	// triggerRequestsPerSecond and triggerRequestBurst limit the requests per ScheduledScan, e.g. to slow down guessing its token
+	triggerRequestsPerSecond = 1
+	triggerRequestBurst      = 5
+	// triggerTokenCacheTTL is how long the tokens of httpTriggers are cached, as secrets aren't cached by the client of the operator
+	triggerTokenCacheTTL = 1 * time.Minute
+)
+
+// TriggerServer serves the endpoint starting scans of ScheduledScans with a httpTrigger: POST /trigger/{namespace}/{name}
+// It allows e.g. CI pipelines to start scans without access to the cluster. Requests are authenticated with the token configured in the httpTrigger of the ScheduledScan.
+type TriggerServer struct {
+	Reconciler  *ScheduledScanReconciler
+	Log         logr.Logger
+	BindAddress string
+	// CertDir contains the certificate (tls.crt) and key (tls.key) the endpoint is served with. Changes to the files are picked up without a restart
+	CertDir string
+	// Insecure serves the endpoint using plain HTTP instead of requiring a CertDir, for setups terminating TLS in front of the operator
+	Insecure bool
+
+	mu       sync.Mutex
+	limiters map[types.NamespacedName]*rate.Limiter
+	tokens   map[tokenSecretKey]cachedToken
+	// now is used to expire cached tokens, defaults to time.Now
+	now func() time.Time
+}
+
+// tokenSecretKey identifies the key of a secret containing the token of a httpTrigger
+type tokenSecretKey struct {
+	secret types.NamespacedName
+	key    string
+}
+
+// cachedToken is the token of a httpTrigger read from its secret. The token is empty if the secret or its key doesn't exist
+type cachedToken struct {
+	token     []byte
+	expiresAt time.Time
+}
+
+// TriggerRequest is the optional JSON body of a request to the trigger endpoint
+type TriggerRequest struct {
+	// Parameters replace the parameters of the started scan. Every parameter has to match one of the allowedParameterPatterns in the httpTrigger of the ScheduledScan
+	Parameters []string `json:"parameters,omitempty"`
+}
+
+// TriggerResponse is returned by the trigger endpoint after starting a scan
+type TriggerResponse struct {
+	Scan      string `json:"scan"`
+	Namespace string `json:"namespace"`
+}
+
+// Start serves the trigger endpoint until the context is cancelled.
+// The endpoint is served using TLS, as requests contain the tokens of the httpTriggers, unless Insecure is set.
+func (s *TriggerServer) Start(ctx context.Context) error {
+	if s.CertDir == "" && !s.Insecure {
+		return errors.New("the trigger endpoint requires a certificate directory, or has to be explicitly configured to be served insecurely if TLS is terminated in front of the operator")
+	}
+
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if s.CertDir != "" {
+		watcher, err := certwatcher.New(filepath.Join(s.CertDir, "tls.crt"), filepath.Join(s.CertDir, "tls.key"))
+		if err != nil {
+			return fmt.Errorf("failed to load the certificate of the trigger endpoint: %w", err)
+		}
+		go func() {
+			if err := watcher.Start(ctx); err != nil {
+				s.Log.Error(err, "Certificate watcher of the trigger endpoint failed")
+			}
+		}()
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: watcher.GetCertificate,
+		}
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		s.Log.Info("Starting trigger endpoint", "address", s.BindAddress, "tls", s.CertDir != "")
+		var err error
+		if s.CertDir != "" {
+			// the certificate is provided by the TLSConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve the trigger endpoint: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// NeedLeaderElection returns false, as every replica of the operator can start scans
+func (s *TriggerServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the handler of the trigger endpoint
+func (s *TriggerServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /trigger/{namespace}/{name}", s.handleTrigger)
+	return mux
+}
+
+func (s *TriggerServer) handleTrigger(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	name := types.NamespacedName{Namespace: req.PathValue("namespace"), Name: req.PathValue("name")}
+	log := s.Log.WithValues("scheduledscan", name)
+
+	var scheduledScan executionv1.ScheduledScan
+	if err := s.Reconciler.Get(ctx, name, &scheduledScan); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeTriggerError(w, http.StatusNotFound, "ScheduledScan not found")
+			return
+		}
+		log.Error(err, "Unable to fetch ScheduledScan")
+		writeTriggerError(w, http.StatusInternalServerError, "failed to fetch the ScheduledScan")
+		return
+	}
+	// ScheduledScans without a httpTrigger are reported as missing, to not disclose which ScheduledScans exist
+	if scheduledScan.Spec.HTTPTrigger == nil {
+		writeTriggerError(w, http.StatusNotFound, "ScheduledScan not found")
+		return
+	}
+
+	// limited after looking up the ScheduledScan, so that only existing ScheduledScans get a limiter
+	if !s.limiter(name).Allow() {
+		w.Header().Set("Retry-After", "1")
+		writeTriggerError(w, http.StatusTooManyRequests, "too many requests for this ScheduledScan")
+		return
+	}
+
+	authorized, err := s.isAuthorized(ctx, scheduledScan, req)
+	if err != nil {
+		log.Error(err, "Unable to fetch the token of the httpTrigger")
+		writeTriggerError(w, http.StatusInternalServerError, "failed to fetch the token of the httpTrigger")
+		return
+	}
+	if !authorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeTriggerError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	var triggerRequest TriggerRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxTriggerRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&triggerRequest); err != nil && !errors.Is(err, io.EOF) {
+		writeTriggerError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if len(triggerRequest.Parameters) > 0 {
+		if err := checkParameterOverrides(*scheduledScan.Spec.HTTPTrigger, triggerRequest.Parameters); err != nil {
+			writeTriggerError(w, http.StatusForbidden, err.Error())
+			return
+		}
+	}
+
+	if scheduledScan.Spec.Suspend != nil && *scheduledScan.Spec.Suspend {
+		writeTriggerError(w, http.StatusConflict, "ScheduledScan is suspended")
+		return
+	}
+
+	var childScans executionv1.ScanList
+	if err := s.Reconciler.List(ctx, &childScans, client.InNamespace(name.Namespace), client.MatchingFields{ownerKey: name.Name}); err != nil {
+		log.Error(err, "Unable to list child Scans")
+		writeTriggerError(w, http.StatusInternalServerError, "failed to list the scans of the ScheduledScan")
+		return
+	}
+	if blocked, err := s.Reconciler.applyConcurrencyPolicy(&scheduledScan, getScansInProgress(childScans.Items)); err != nil {
+		writeTriggerError(w, http.StatusInternalServerError, "failed to replace the scans in progress")
+		return
+	} else if blocked {
+		writeTriggerError(w, http.StatusConflict, "concurrency policy blocks concurrent runs")
+		return
+	}
+
+	scanSource := scheduledScan.DeepCopy()
+	if len(triggerRequest.Parameters) > 0 {
+		scanSource.Spec.ScanSpec.Parameters = triggerRequest.Parameters
+	}
+	scan, err := s.Reconciler.createScan(ctx, scanSource, fmt.Sprintf("%s-%d", scheduledScan.Name, time.Now().Unix()), nil)
+	if err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			writeTriggerError(w, http.StatusConflict, "a scan of the ScheduledScan was already started in the same second")
+			return
+		}
+		writeTriggerError(w, http.StatusInternalServerError, "failed to create the scan")
+		return
+	}
+
+	if len(triggerRequest.Parameters) > 0 {
+		s.Reconciler.Recorder.Eventf(&scheduledScan, "Normal", "HTTPTriggered", "Started scan %s with overridden parameters using the trigger endpoint", scan.Name)
+	} else {
+		s.Reconciler.Recorder.Eventf(&scheduledScan, "Normal", "HTTPTriggered", "Started scan %s using the trigger endpoint", scan.Name)
+	}
+	log.V(4).Info("Started scan using the trigger endpoint", "scan", scan.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(TriggerResponse{Scan: scan.Name, Namespace: scan.Namespace})
+}
+
+// checkParameterOverrides returns an error if a parameter doesn't fully match one of the allowedParameterPatterns of the httpTrigger
+func checkParameterOverrides(httpTrigger executionv1.HTTPTrigger, parameters []string) error {
+	if len(httpTrigger.AllowedParameterPatterns) == 0 {
+		return errors.New("parameter overrides aren't allowed for this ScheduledScan")
+	}
+	patterns := make([]*regexp.Regexp, 0, len(httpTrigger.AllowedParameterPatterns))
+	for _, pattern := range httpTrigger.AllowedParameterPatterns {
+		compiled, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid allowedParameterPattern %q of the ScheduledScan: %v", pattern, err)
+		}
+		patterns = append(patterns, compiled)
+	}
+
+	for _, parameter := range parameters {
+		allowed := false
+		for _, pattern := range patterns {
+			if pattern.MatchString(parameter) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("parameter %q doesn't match the allowedParameterPatterns of the ScheduledScan", parameter)
+		}
+	}
+	return nil
+}
+
+// limiter returns the rate limiter for the requests of the ScheduledScan
+func (s *TriggerServer) limiter(name types.NamespacedName) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limiters == nil {
+		s.limiters = map[types.NamespacedName]*rate.Limiter{}
+	}
+	limiter, ok := s.limiters[name]
+	if !ok {
+		limiter = rate.NewLimiter(triggerRequestsPerSecond, triggerRequestBurst)
+		s.limiters[name] = limiter
+	}
+	return limiter
+}
+
+// isAuthorized compares the bearer token of the request with the token referenced by the httpTrigger of the ScheduledScan
+func (s *TriggerServer) isAuthorized(ctx context.Context, scheduledScan executionv1.ScheduledScan, req *http.Request) (bool, error) {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false, nil
+	}
+
+	expected, err := s.getToken(ctx, scheduledScan)
+	if err != nil {
+		return false, err
+	}
+	if len(expected) == 0 {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(token), expected) == 1, nil
+}
+
+// getToken returns the token referenced by the httpTrigger of the ScheduledScan, or an empty token if the secret or its key doesn't exist.
+// Tokens are cached for the triggerTokenCacheTTL, so that changed tokens are picked up shortly after.
+func (s *TriggerServer) getToken(ctx context.Context, scheduledScan executionv1.ScheduledScan) ([]byte, error) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	secretRef := scheduledScan.Spec.HTTPTrigger.TokenSecretRef
+	cacheKey := tokenSecretKey{secret: types.NamespacedName{Name: secretRef.Name, Namespace: scheduledScan.Namespace}, key: secretRef.Key}
+
+	s.mu.Lock()
+	cached, ok := s.tokens[cacheKey]
+	s.mu.Unlock()
+	if ok && now().Before(cached.expiresAt) {
+		return cached.token, nil
+	}
+
+	var token []byte
+	var secret corev1.Secret
+	if err := s.Reconciler.Get(ctx, cacheKey.secret, &secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		s.Log.Info("Secret referenced by the httpTrigger doesn't exist", "scheduledscan", scheduledScan.Name, "namespace", scheduledScan.Namespace, "secret", secretRef.Name)
+	} else if token = secret.Data[secretRef.Key]; len(token) == 0 {
+		s.Log.Info("Secret referenced by the httpTrigger doesn't contain the key", "scheduledscan", scheduledScan.Name, "namespace", scheduledScan.Namespace, "secret", secretRef.Name, "key", secretRef.Key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = map[tokenSecretKey]cachedToken{}
+	}
+	s.tokens[cacheKey] = cachedToken{token: token, expiresAt: now().Add(triggerTokenCacheTTL)}
+	return token, nil
+}
+
+func writeTriggerError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
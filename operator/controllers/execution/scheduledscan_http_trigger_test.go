@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ScheduledScan HTTP Trigger", func() {
+	var (
+		server        *TriggerServer
+		scheduledScan *executionv1.ScheduledScan
+	)
+
+	setup := func(objects ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		objects = append(objects, scheduledScan, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "trigger-token", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("s3cr3t")},
+		})
+		server = &TriggerServer{
+			Reconciler: &ScheduledScanReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(objects...).
+					WithIndex(&executionv1.Scan{}, ownerKey, func(obj client.Object) []string {
+						owner := metav1.GetControllerOf(obj)
+						if owner == nil {
+							return nil
+						}
+						return []string{owner.Name}
+					}).
+					Build(),
+				Log:      logr.Discard(),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			},
+			Log: logr.Discard(),
+		}
+	}
+
+	trigger := func(path string, token string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	getScans := func() []executionv1.Scan {
+		var scans executionv1.ScanList
+		Expect(server.Reconciler.List(context.Background(), &scans, client.InNamespace("default"), client.MatchingFields{ownerKey: "nuclei"})).To(Succeed())
+		return scans.Items
+	}
+
+	BeforeEach(func() {
+		scheduledScan = &executionv1.ScheduledScan{
+			ObjectMeta: metav1.ObjectMeta{Name: "nuclei", Namespace: "default", UID: "nuclei"},
+			Spec: executionv1.ScheduledScanSpec{
+				ScanSpec: &executionv1.ScanSpec{ScanType: "nuclei", Parameters: []string{"-u", "https://example.com"}},
+				HTTPTrigger: &executionv1.HTTPTrigger{
+					TokenSecretRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "trigger-token"}, Key: "token"},
+				},
+			},
+		}
+	})
+
+	It("should start a scan for requests with a valid token", func() {
+		setup()
+
+		response := trigger("/trigger/default/nuclei", "s3cr3t", "")
+		Expect(response.Code).To(Equal(http.StatusCreated))
+
+		scans := getScans()
+		Expect(scans).To(HaveLen(1))
+		Expect(scans[0].Spec.Parameters).To(Equal([]string{"-u", "https://example.com"}))
+
+		var body TriggerResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(Equal(TriggerResponse{Scan: scans[0].Name, Namespace: "default"}))
+	})
+
+	It("should reject requests with a missing or invalid token", func() {
+		setup()
+
+		Expect(trigger("/trigger/default/nuclei", "", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(trigger("/trigger/default/nuclei", "wrong", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(getScans()).To(BeEmpty())
+	})
+
+	It("should report ScheduledScans without a httpTrigger as missing", func() {
+		scheduledScan.Spec.HTTPTrigger = nil
+		setup()
+
+		Expect(trigger("/trigger/default/nuclei", "s3cr3t", "").Code).To(Equal(http.StatusNotFound))
+		Expect(trigger("/trigger/default/amass", "s3cr3t", "").Code).To(Equal(http.StatusNotFound))
+		Expect(getScans()).To(BeEmpty())
+	})
+
+	It("should only override the parameters if they match the allowedParameterPatterns of the httpTrigger", func() {
+		setup()
+
+		Expect(trigger("/trigger/default/nuclei", "s3cr3t", `{"parameters": ["-u", "https://staging.example.com"]}`).Code).To(Equal(http.StatusForbidden))
+		Expect(getScans()).To(BeEmpty())
+
+		scheduledScan.Spec.HTTPTrigger.AllowedParameterPatterns = []string{"-u", `https://[a-z0-9-]+\.example\.com`}
+		Expect(server.Reconciler.Update(context.Background(), scheduledScan)).To(Succeed())
+
+		// patterns have to match the whole parameter
+		response := trigger("/trigger/default/nuclei", "s3cr3t", `{"parameters": ["-u", "https://staging.example.com.attacker.io"]}`)
+		Expect(response.Code).To(Equal(http.StatusForbidden))
+		Expect(response.Body.String()).To(ContainSubstring(`parameter \"https://staging.example.com.attacker.io\" doesn't match the allowedParameterPatterns of the ScheduledScan`))
+		Expect(trigger("/trigger/default/nuclei", "s3cr3t", `{"parameters": ["-u", "https://staging.example.com", "-H", "X-Debug: 1"]}`).Code).To(Equal(http.StatusForbidden))
+		Expect(getScans()).To(BeEmpty())
+
+		Expect(trigger("/trigger/default/nuclei", "s3cr3t", `{"parameters": ["-u", "https://staging.example.com"]}`).Code).To(Equal(http.StatusCreated))
+		scans := getScans()
+		Expect(scans).To(HaveLen(1))
+		Expect(scans[0].Spec.Parameters).To(Equal([]string{"-u", "https://staging.example.com"}))
+	})
+
+	It("should limit the requests per ScheduledScan", func() {
+		setup()
+
+		for i := 0; i < triggerRequestBurst; i++ {
+			Expect(trigger("/trigger/default/nuclei", "wrong", "").Code).To(Equal(http.StatusUnauthorized))
+		}
+		response := trigger("/trigger/default/nuclei", "s3cr3t", "")
+		Expect(response.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(response.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(getScans()).To(BeEmpty())
+	})
+
+	It("should cache the token of the httpTrigger", func() {
+		setup()
+		now := time.Now()
+		server.now = func() time.Time { return now }
+
+		Expect(trigger("/trigger/default/nuclei", "wrong", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(server.Reconciler.Update(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "trigger-token", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("rotated")},
+		})).To(Succeed())
+
+		// the secret is only read again once the cached token expired
+		Expect(trigger("/trigger/default/nuclei", "rotated", "").Code).To(Equal(http.StatusUnauthorized))
+		now = now.Add(triggerTokenCacheTTL)
+		Expect(trigger("/trigger/default/nuclei", "rotated", "").Code).To(Equal(http.StatusCreated))
+	})
+
+	It("should refuse to serve the endpoint without TLS unless it is explicitly insecure", func() {
+		setup()
+
+		Expect(server.Start(context.Background())).To(MatchError(ContainSubstring("the trigger endpoint requires a certificate directory")))
+	})
+
+	It("should reject invalid request bodies", func() {
+		setup()
+
+		Expect(trigger("/trigger/default/nuclei", "s3cr3t", `{"env": []}`).Code).To(Equal(http.StatusBadRequest))
+		Expect(getScans()).To(BeEmpty())
+	})
+
+	It("should respect the concurrency policy of the ScheduledScan", func() {
+		scheduledScan.Spec.ConcurrencyPolicy = executionv1.ForbidConcurrent
+		isController := true
+		setup(&executionv1.Scan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nuclei-1",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: apiGVStr, Kind: "ScheduledScan", Name: "nuclei", UID: types.UID("nuclei"), Controller: &isController},
+				},
+			},
+			Status: executionv1.ScanStatus{State: executionv1.ScanStateScanning},
+		})
+
+		Expect(trigger("/trigger/default/nuclei", "s3cr3t", "").Code).To(Equal(http.StatusConflict))
+		Expect(getScans()).To(HaveLen(1))
+	})
+})
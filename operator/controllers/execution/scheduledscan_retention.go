@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"time"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/utils"
+)
+
+// getScansToDelete returns the scans exceeding the history limit or the maxAge of the historyRetention of the ScheduledScan. The scans have to be sorted from oldest to newest.
+// The defaultHistoryLimit is used if no history limit is set, unless the historyRetention sets a maxAge. Scans kept by the historyRetention are never deleted and don't count towards the history limit.
+func getScansToDelete(scheduledScan executionv1.ScheduledScan, scans []executionv1.Scan, historyLimit *int32, defaultHistoryLimit int32, now time.Time) []executionv1.Scan {
+	retention := scheduledScan.Spec.HistoryRetention
+	kept := getKeptScans(scheduledScan, scans)
+
+	var candidates []executionv1.Scan
+	for _, scan := range scans {
+		if !kept[scan.Name] {
+			candidates = append(candidates, scan)
+		}
+	}
+
+	// a negative limit doesn't restrict the number of scans
+	limit := int32(-1)
+	if historyLimit != nil {
+		limit = *historyLimit
+	} else if retention == nil || retention.MaxAge == nil {
+		limit = defaultHistoryLimit
+	}
+
+	var scansToDelete []executionv1.Scan
+	for i, scan := range candidates {
+		exceedsLimit := limit >= 0 && int32(i) < int32(len(candidates))-limit
+		expired := retention != nil && retention.MaxAge != nil && getScanFinishedAt(scan).Before(now.Add(-retention.MaxAge.Duration))
+		if exceedsLimit || expired {
+			scansToDelete = append(scansToDelete, scan)
+		}
+	}
+	return scansToDelete
+}
+
+// getKeptScans returns the names of the successful scans which are always kept according to the historyRetention of the ScheduledScan
+func getKeptScans(scheduledScan executionv1.ScheduledScan, scans []executionv1.Scan) map[string]bool {
+	kept := map[string]bool{}
+	retention := scheduledScan.Spec.HistoryRetention
+	if retention == nil {
+		return kept
+	}
+
+	// months are evaluated in the time zone of the schedule, falling back to the time zone of the operator like the schedule does
+	location := time.Local
+	if scheduledScan.Spec.TimeZone != nil {
+		if tz, err := utils.LoadTimeZone(*scheduledScan.Spec.TimeZone); err == nil {
+			location = tz
+		}
+	}
+
+	lastWithHighFindings := ""
+	months := map[string]bool{}
+	for _, scan := range scans {
+		if scan.Status.State != executionv1.ScanStateDone {
+			continue
+		}
+		if retention.KeepLastWithHighFindings && scan.Status.Findings.FindingSeverities.High > 0 {
+			lastWithHighFindings = scan.Name
+		}
+		if retention.KeepFirstOfMonth {
+			month := scan.CreationTimestamp.In(location).Format("2006-01")
+			if !months[month] {
+				months[month] = true
+				kept[scan.Name] = true
+			}
+		}
+	}
+	if lastWithHighFindings != "" {
+		kept[lastWithHighFindings] = true
+	}
+	return kept
+}
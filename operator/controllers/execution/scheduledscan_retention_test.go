@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	scancontroller "github.com/secureCodeBox/secureCodeBox/operator/controllers/execution/scans"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ScheduledScan History Retention", func() {
+	var (
+		scheduledScan executionv1.ScheduledScan
+		now           time.Time
+	)
+
+	newScan := func(name string, finishedAt time.Time, highFindings uint64) executionv1.Scan {
+		return executionv1.Scan{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.Time{Time: finishedAt.Add(-10 * time.Minute)}},
+			Status: executionv1.ScanStatus{
+				State:      executionv1.ScanStateDone,
+				FinishedAt: &metav1.Time{Time: finishedAt},
+				Findings:   executionv1.FindingStats{FindingSeverities: executionv1.FindingSeverities{High: highFindings}},
+			},
+		}
+	}
+
+	names := func(scans []executionv1.Scan) []string {
+		result := []string{}
+		for _, scan := range scans {
+			result = append(result, scan.Name)
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2026, time.March, 20, 12, 0, 0, 0, time.UTC)
+		utc := "UTC"
+		scheduledScan = executionv1.ScheduledScan{
+			ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default"},
+			Spec:       executionv1.ScheduledScanSpec{TimeZone: &utc},
+		}
+	})
+
+	It("should only apply the history limit without a history retention", func() {
+		scans := []executionv1.Scan{
+			newScan("nmap-1", now.Add(-72*time.Hour), 0),
+			newScan("nmap-2", now.Add(-48*time.Hour), 0),
+			newScan("nmap-3", now.Add(-24*time.Hour), 0),
+		}
+
+		Expect(names(getScansToDelete(scheduledScan, scans, nil, 3, now))).To(BeEmpty())
+		limit := int32(1)
+		Expect(names(getScansToDelete(scheduledScan, scans, &limit, 3, now))).To(Equal([]string{"nmap-1", "nmap-2"}))
+	})
+
+	It("should delete scans older than the maxAge and ignore the default history limit", func() {
+		scheduledScan.Spec.HistoryRetention = &executionv1.HistoryRetention{MaxAge: &metav1.Duration{Duration: 30 * time.Hour}}
+		scans := []executionv1.Scan{
+			newScan("nmap-1", now.Add(-72*time.Hour), 0),
+			newScan("nmap-2", now.Add(-48*time.Hour), 0),
+			newScan("nmap-3", now.Add(-24*time.Hour), 0),
+			newScan("nmap-4", now.Add(-2*time.Hour), 0),
+			newScan("nmap-5", now.Add(-1*time.Hour), 0),
+		}
+
+		Expect(names(getScansToDelete(scheduledScan, scans, nil, 1, now))).To(Equal([]string{"nmap-1", "nmap-2"}))
+
+		// explicit history limits still apply
+		limit := int32(1)
+		Expect(names(getScansToDelete(scheduledScan, scans, &limit, 1, now))).To(Equal([]string{"nmap-1", "nmap-2", "nmap-3", "nmap-4"}))
+	})
+
+	It("should keep the last scan with high findings and the first scan of every month", func() {
+		scheduledScan.Spec.HistoryRetention = &executionv1.HistoryRetention{
+			MaxAge:                   &metav1.Duration{Duration: 7 * 24 * time.Hour},
+			KeepLastWithHighFindings: true,
+			KeepFirstOfMonth:         true,
+		}
+		scans := []executionv1.Scan{
+			newScan("nmap-january-1", time.Date(2026, time.January, 1, 2, 0, 0, 0, time.UTC), 1),
+			newScan("nmap-january-2", time.Date(2026, time.January, 15, 2, 0, 0, 0, time.UTC), 0),
+			newScan("nmap-february-1", time.Date(2026, time.February, 1, 2, 0, 0, 0, time.UTC), 0),
+			newScan("nmap-february-2", time.Date(2026, time.February, 15, 2, 0, 0, 0, time.UTC), 2),
+			newScan("nmap-march-1", time.Date(2026, time.March, 1, 2, 0, 0, 0, time.UTC), 0),
+			newScan("nmap-march-2", time.Date(2026, time.March, 10, 2, 0, 0, 0, time.UTC), 0),
+			newScan("nmap-march-3", time.Date(2026, time.March, 19, 2, 0, 0, 0, time.UTC), 0),
+		}
+
+		Expect(names(getScansToDelete(scheduledScan, scans, nil, 3, now))).To(Equal([]string{"nmap-january-2", "nmap-march-2"}))
+
+		// kept scans don't count towards the history limit
+		limit := int32(1)
+		Expect(names(getScansToDelete(scheduledScan, scans, &limit, 3, now))).To(Equal([]string{"nmap-january-2", "nmap-march-2"}))
+	})
+
+	It("should evaluate the months in the time zone of the ScheduledScan", func() {
+		berlin := "Europe/Berlin"
+		scheduledScan.Spec.TimeZone = &berlin
+		scheduledScan.Spec.HistoryRetention = &executionv1.HistoryRetention{KeepFirstOfMonth: true}
+		scans := []executionv1.Scan{
+			// created at 23:30 UTC on the last day of february, which is already march in Berlin
+			newScan("nmap-1", time.Date(2026, time.February, 28, 23, 40, 0, 0, time.UTC), 0),
+			newScan("nmap-2", time.Date(2026, time.March, 1, 2, 0, 0, 0, time.UTC), 0),
+		}
+
+		limit := int32(0)
+		Expect(names(getScansToDelete(scheduledScan, scans, &limit, 3, now))).To(Equal([]string{"nmap-2"}))
+	})
+
+	It("should annotate scans before deleting them if archiving is enabled", func() {
+		scheduledScan.Spec.HistoryRetention = &executionv1.HistoryRetention{Archive: true}
+		scan := newScan("nmap-1", now.Add(-72*time.Hour), 0)
+		// keeps the scan around after the deletion to check the annotation
+		scan.Finalizers = []string{"s3.storage.securecodebox.io/scan-files"}
+
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+		r := &ScheduledScanReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(&scan).Build(),
+			Log:      logr.Discard(),
+			Scheme:   scheme,
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		Expect(r.deleteOldScans(context.Background(), scheduledScan, []executionv1.Scan{scan})).To(Succeed())
+
+		var deleted executionv1.Scan
+		Expect(r.Get(context.Background(), types.NamespacedName{Name: "nmap-1", Namespace: "default"}, &deleted)).To(Succeed())
+		Expect(deleted.DeletionTimestamp).NotTo(BeNil())
+		Expect(deleted.Annotations).To(HaveKeyWithValue(scancontroller.ArchiveFilesAnnotation, "true"))
+	})
+})
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/secureCodeBox/secureCodeBox/operator/utils"
+)
+
+// startTriggeredScans starts a scan for every trigger of the ScheduledScan whose ScheduledScan completed a scan matching the condition of the trigger.
+// At most one scan is started per reconcile, as the concurrency policy is checked against the scans in progress at the start of the reconcile. Returns after which duration the triggers should be evaluated again, or 0 if there is nothing left to do.
+// Triggers forming a cycle, e.g. two ScheduledScans triggering each other, are ignored and reported in the TriggersValid condition, as their scans would start each other forever.
+func (r *ScheduledScanReconciler) startTriggeredScans(ctx context.Context, scheduledScan *executionv1.ScheduledScan, inProgressScans []executionv1.Scan) (time.Duration, error) {
+	if len(scheduledScan.Spec.Triggers) == 0 {
+		return 0, nil
+	}
+
+	var scheduledScans executionv1.ScheduledScanList
+	if err := r.List(ctx, &scheduledScans, client.InNamespace(scheduledScan.Namespace)); err != nil {
+		return 0, fmt.Errorf("failed to list the ScheduledScans to detect trigger cycles: %w", err)
+	}
+
+	oldScheduledScan := scheduledScan.DeepCopy()
+	condition := metav1.Condition{
+		Type:               executionv1.TriggersValidCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "NoTriggerCycle",
+		Message:            "The triggers of the ScheduledScan don't form a cycle",
+		ObservedGeneration: scheduledScan.Generation,
+	}
+	var requeueAfter time.Duration
+	statuses := []executionv1.TriggerStatus{}
+	for _, trigger := range scheduledScan.Spec.Triggers {
+		status := getTriggerStatus(*scheduledScan, trigger.ScheduledScan)
+		if cycle := getTriggerCycle(*scheduledScan, trigger.ScheduledScan, scheduledScans.Items); cycle != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "TriggerCycle"
+			condition.Message = fmt.Sprintf("Ignoring the trigger for ScheduledScan %s, as the triggers form a cycle: %s", trigger.ScheduledScan, strings.Join(cycle, " -> "))
+			statuses = append(statuses, status)
+			continue
+		}
+		if requeueAfter > 0 {
+			// evaluated in the next reconcile
+			statuses = append(statuses, status)
+			continue
+		}
+
+		var scans executionv1.ScanList
+		if err := r.List(ctx, &scans, client.InNamespace(scheduledScan.Namespace), client.MatchingFields{ownerKey: trigger.ScheduledScan}); err != nil {
+			return 0, fmt.Errorf("failed to list the scans of the triggering ScheduledScan %s: %w", trigger.ScheduledScan, err)
+		}
+		triggeringScan := getTriggeringScan(*scheduledScan, status, scans.Items)
+		if triggeringScan == nil {
+			statuses = append(statuses, status)
+			continue
+		}
+
+		matches, err := utils.MatchesHookCondition(trigger.When, triggeringScan)
+		if err != nil {
+			r.Recorder.Eventf(scheduledScan, "Warning", "InvalidTriggerCondition", "Invalid condition of the trigger for ScheduledScan %s: %v", trigger.ScheduledScan, err)
+		}
+		if matches {
+			if blocked, err := r.applyConcurrencyPolicy(scheduledScan, inProgressScans); err != nil {
+				return 0, err
+			} else if blocked {
+				// the triggering scan is evaluated again once the concurrency policy allows it
+				requeueAfter = 1 * time.Minute
+				statuses = append(statuses, status)
+				continue
+			}
+
+			// the name is derived from the triggering scan, so that it is only started once, even if the trigger status below isn't updated
+			name := fmt.Sprintf("%s-%s", scheduledScan.Name, triggeredScanSuffix(*triggeringScan))
+			scan, err := r.createScan(ctx, scheduledScan, name, getTriggerEnv(trigger.ScheduledScan, *triggeringScan))
+			if apierrors.IsAlreadyExists(err) {
+				r.Log.V(5).Info("Scan for the triggering scan was already started", "scheduledScan", scheduledScan.Name, "scan", name, "triggeringScan", triggeringScan.Name)
+				var existing executionv1.Scan
+				if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: scheduledScan.Namespace}, &existing); err != nil {
+					return 0, fmt.Errorf("failed to get the already triggered scan %s: %w", name, err)
+				}
+				status.LastTriggerTime = existing.CreationTimestamp.DeepCopy()
+			} else if err != nil {
+				return 0, err
+			} else {
+				r.Recorder.Eventf(scheduledScan, "Normal", "Triggered", "Started scan %s as scan %s of ScheduledScan %s is done", scan.Name, triggeringScan.Name, trigger.ScheduledScan)
+				now := metav1.Now()
+				status.LastTriggerTime = &now
+				requeueAfter = 1 * time.Second
+			}
+		} else {
+			r.Log.V(7).Info("Completed scan doesn't match the condition of the trigger", "scheduledScan", scheduledScan.Name, "scan", triggeringScan.Name)
+		}
+
+		status.LastScan = triggeringScan.Name
+		status.LastScanFinishedAt = &metav1.Time{Time: getScanFinishedAt(*triggeringScan)}
+		statuses = append(statuses, status)
+	}
+
+	scheduledScan.Status.Triggers = statuses
+	// the cycle is reported once when the condition changes, not on every reconcile
+	if meta.SetStatusCondition(&scheduledScan.Status.Conditions, condition) && condition.Status == metav1.ConditionFalse {
+		r.Recorder.Event(scheduledScan, "Warning", "TriggerCycle", condition.Message)
+	}
+	if reflect.DeepEqual(oldScheduledScan.Status, scheduledScan.Status) {
+		return requeueAfter, nil
+	}
+	if err := r.Status().Patch(ctx, scheduledScan, client.MergeFrom(oldScheduledScan)); err != nil {
+		return 0, fmt.Errorf("failed to update the trigger status of the ScheduledScan: %w", err)
+	}
+	return requeueAfter, nil
+}
+
+// triggeredScanSuffix returns the suffix of the name of the scan started for the triggering scan, derived from its uid
+func triggeredScanSuffix(triggeringScan executionv1.Scan) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(triggeringScan.UID))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+// getTriggerCycle returns the names of the ScheduledScans forming a cycle with the trigger of the ScheduledScan for the triggering ScheduledScan,
+// e.g. [a, b, a] if scans of a trigger b and scans of b trigger a. Returns nil if the trigger isn't part of a cycle.
+func getTriggerCycle(scheduledScan executionv1.ScheduledScan, triggering string, scheduledScans []executionv1.ScheduledScan) []string {
+	// triggered maps the name of a ScheduledScan to the names of the ScheduledScans triggered by its scans
+	triggered := map[string][]string{}
+	addTriggers := func(triggeredScheduledScan executionv1.ScheduledScan) {
+		for _, trigger := range triggeredScheduledScan.Spec.Triggers {
+			triggered[trigger.ScheduledScan] = append(triggered[trigger.ScheduledScan], triggeredScheduledScan.Name)
+		}
+	}
+	// the listed ScheduledScan might be outdated
+	addTriggers(scheduledScan)
+	for _, other := range scheduledScans {
+		if other.Name != scheduledScan.Name {
+			addTriggers(other)
+		}
+	}
+
+	// breadth first search for the ScheduledScans triggered by the ScheduledScan, the trigger closes a cycle if they include the triggering ScheduledScan
+	previous := map[string]string{scheduledScan.Name: ""}
+	queue := []string{scheduledScan.Name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == triggering {
+			cycle := []string{scheduledScan.Name}
+			for name := current; name != scheduledScan.Name; name = previous[name] {
+				cycle = append([]string{name}, cycle...)
+			}
+			return append([]string{scheduledScan.Name}, cycle...)
+		}
+		for _, next := range triggered[current] {
+			if _, seen := previous[next]; !seen {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// getTriggerStatus returns the status of the trigger for the given ScheduledScan, or an empty status if the trigger wasn't evaluated yet
+func getTriggerStatus(scheduledScan executionv1.ScheduledScan, triggeringScheduledScan string) executionv1.TriggerStatus {
+	for _, status := range scheduledScan.Status.Triggers {
+		if status.ScheduledScan == triggeringScheduledScan {
+			return *status.DeepCopy()
+		}
+	}
+	return executionv1.TriggerStatus{ScheduledScan: triggeringScheduledScan}
+}
+
+// getTriggeringScan returns the most recent completed scan of the triggering ScheduledScan, if it was completed after the last evaluated scan and after the ScheduledScan was created.
+func getTriggeringScan(scheduledScan executionv1.ScheduledScan, status executionv1.TriggerStatus, scans []executionv1.Scan) *executionv1.Scan {
+	since := scheduledScan.CreationTimestamp.Time
+	if status.LastScanFinishedAt != nil && status.LastScanFinishedAt.After(since) {
+		since = status.LastScanFinishedAt.Time
+	}
+
+	var latest *executionv1.Scan
+	for i, scan := range scans {
+		if scan.Status.State != executionv1.ScanStateDone || !getScanFinishedAt(scan).After(since) {
+			continue
+		}
+		if latest == nil || getScanFinishedAt(*latest).Before(getScanFinishedAt(scan)) {
+			latest = &scans[i]
+		}
+	}
+	return latest
+}
+
+// getScanFinishedAt returns the time the scan was completed, falling back to its creation for scans completed by older operator versions
+func getScanFinishedAt(scan executionv1.Scan) time.Time {
+	if scan.Status.FinishedAt != nil {
+		return scan.Status.FinishedAt.Time
+	}
+	return scan.CreationTimestamp.Time
+}
+
+// getTriggerEnv returns the env vars referencing the triggering scan, which are added to the triggered scan
+func getTriggerEnv(triggeringScheduledScan string, triggeringScan executionv1.Scan) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "SCB_TRIGGERING_SCHEDULED_SCAN", Value: triggeringScheduledScan},
+		{Name: "SCB_TRIGGERING_SCAN", Value: triggeringScan.Name},
+		{Name: "SCB_TRIGGERING_SCAN_FINDINGS_URL", Value: triggeringScan.Status.FindingDownloadLink},
+	}
+}
+
+// getTriggeredScheduledScans maps a completed scan of a ScheduledScan to the ScheduledScans triggered by it
+func (r *ScheduledScanReconciler) getTriggeredScheduledScans(ctx context.Context, obj client.Object) []reconcile.Request {
+	scan, ok := obj.(*executionv1.Scan)
+	if !ok || scan.Status.State != executionv1.ScanStateDone {
+		return nil
+	}
+	owner := metav1.GetControllerOf(scan)
+	if owner == nil || owner.APIVersion != apiGVStr || owner.Kind != "ScheduledScan" {
+		return nil
+	}
+
+	var scheduledScans executionv1.ScheduledScanList
+	if err := r.List(ctx, &scheduledScans, client.InNamespace(scan.Namespace)); err != nil {
+		r.Log.Error(err, "Unable to list ScheduledScans triggered by scan", "scan", scan.Name, "namespace", scan.Namespace)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, scheduledScan := range scheduledScans.Items {
+		for _, trigger := range scheduledScan.Spec.Triggers {
+			if trigger.ScheduledScan == owner.Name {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: scheduledScan.Name, Namespace: scheduledScan.Namespace}})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build fast
+// +build fast
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("ScheduledScan Triggers", func() {
+	var (
+		r             *ScheduledScanReconciler
+		scheduledScan *executionv1.ScheduledScan
+		created       time.Time
+	)
+
+	newScan := func(name string, owner string, state executionv1.ScanState, finishedAt time.Time, highFindings uint64) *executionv1.Scan {
+		isController := true
+		return &executionv1.Scan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				UID:       types.UID(name + "-uid"),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: apiGVStr, Kind: "ScheduledScan", Name: owner, UID: types.UID(owner), Controller: &isController},
+				},
+			},
+			Spec: executionv1.ScanSpec{ScanType: "subfinder"},
+			Status: executionv1.ScanStatus{
+				State:               state,
+				FinishedAt:          &metav1.Time{Time: finishedAt},
+				FindingDownloadLink: "https://s3.example.com/" + name + "/findings.json",
+				Findings:            executionv1.FindingStats{Count: highFindings, FindingSeverities: executionv1.FindingSeverities{High: highFindings}},
+			},
+		}
+	}
+
+	setup := func(objects ...client.Object) {
+		scheme := runtime.NewScheme()
+		Expect(executionv1.AddToScheme(scheme)).To(Succeed())
+		objects = append(objects, scheduledScan)
+		r = &ScheduledScanReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(scheduledScan).
+				WithIndex(&executionv1.Scan{}, ownerKey, func(obj client.Object) []string {
+					owner := metav1.GetControllerOf(obj)
+					if owner == nil {
+						return nil
+					}
+					return []string{owner.Name}
+				}).
+				Build(),
+			Log:      logr.Discard(),
+			Scheme:   scheme,
+			Recorder: record.NewFakeRecorder(10),
+		}
+	}
+
+	getScans := func(owner string) []executionv1.Scan {
+		var scans executionv1.ScanList
+		Expect(r.List(context.Background(), &scans, client.InNamespace("default"), client.MatchingFields{ownerKey: owner})).To(Succeed())
+		return scans.Items
+	}
+
+	BeforeEach(func() {
+		created = time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+		scheduledScan = &executionv1.ScheduledScan{
+			ObjectMeta: metav1.ObjectMeta{Name: "nuclei", Namespace: "default", UID: "nuclei", CreationTimestamp: metav1.Time{Time: created}},
+			Spec: executionv1.ScheduledScanSpec{
+				ScanSpec: &executionv1.ScanSpec{ScanType: "nuclei", Env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}}},
+				Triggers: []executionv1.ScheduledScanTrigger{{ScheduledScan: "subfinder"}},
+			},
+		}
+	})
+
+	It("should start a scan when a scan of the triggering ScheduledScan is done", func() {
+		setup(newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created.Add(10*time.Minute), 0))
+
+		requeueAfter, err := r.startTriggeredScans(context.Background(), scheduledScan, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(BeNumerically(">", 0))
+
+		scans := getScans("nuclei")
+		Expect(scans).To(HaveLen(1))
+		Expect(scans[0].Spec.ScanType).To(Equal("nuclei"))
+		Expect(scans[0].Spec.Env).To(Equal([]corev1.EnvVar{
+			{Name: "FOO", Value: "bar"},
+			{Name: "SCB_TRIGGERING_SCHEDULED_SCAN", Value: "subfinder"},
+			{Name: "SCB_TRIGGERING_SCAN", Value: "subfinder-1"},
+			{Name: "SCB_TRIGGERING_SCAN_FINDINGS_URL", Value: "https://s3.example.com/subfinder-1/findings.json"},
+		}))
+
+		Expect(scheduledScan.Status.Triggers).To(HaveLen(1))
+		Expect(scheduledScan.Status.Triggers[0].LastScan).To(Equal("subfinder-1"))
+		Expect(scheduledScan.Status.Triggers[0].LastTriggerTime).NotTo(BeNil())
+
+		// the same scan doesn't start another scan
+		requeueAfter, err = r.startTriggeredScans(context.Background(), scheduledScan, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(Equal(time.Duration(0)))
+		Expect(getScans("nuclei")).To(HaveLen(1))
+	})
+
+	It("should treat an existing scan for the triggering scan as already triggered if the trigger status wasn't updated", func() {
+		setup(newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created.Add(10*time.Minute), 0))
+		// a copy of the ScheduledScan, whose status update after starting the scan got lost
+		outdated := scheduledScan.DeepCopy()
+
+		_, err := r.startTriggeredScans(context.Background(), scheduledScan, nil)
+		Expect(err).NotTo(HaveOccurred())
+		scans := getScans("nuclei")
+		Expect(scans).To(HaveLen(1))
+		Expect(scans[0].Name).To(Equal("nuclei-" + triggeredScanSuffix(*newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created, 0))))
+		Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(HavePrefix("Normal Triggered")))
+
+		outdated.ResourceVersion = scheduledScan.ResourceVersion
+		requeueAfter, err := r.startTriggeredScans(context.Background(), outdated, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(Equal(time.Duration(0)))
+		Expect(getScans("nuclei")).To(HaveLen(1))
+		Expect(r.Recorder.(*record.FakeRecorder).Events).NotTo(Receive())
+		Expect(outdated.Status.Triggers[0].LastScan).To(Equal("subfinder-1"))
+	})
+
+	It("should ignore triggers forming a cycle and report them in the TriggersValid condition", func() {
+		subfinder := &executionv1.ScheduledScan{
+			ObjectMeta: metav1.ObjectMeta{Name: "subfinder", Namespace: "default"},
+			Spec: executionv1.ScheduledScanSpec{
+				ScanSpec: &executionv1.ScanSpec{ScanType: "subfinder"},
+				Triggers: []executionv1.ScheduledScanTrigger{{ScheduledScan: "nuclei"}},
+			},
+		}
+		setup(subfinder, newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created.Add(10*time.Minute), 0))
+
+		requeueAfter, err := r.startTriggeredScans(context.Background(), scheduledScan, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(Equal(time.Duration(0)))
+		Expect(getScans("nuclei")).To(BeEmpty())
+
+		condition := meta.FindStatusCondition(scheduledScan.Status.Conditions, executionv1.TriggersValidCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("TriggerCycle"))
+		Expect(condition.Message).To(Equal("Ignoring the trigger for ScheduledScan subfinder, as the triggers form a cycle: nuclei -> subfinder -> nuclei"))
+		Expect(r.Recorder.(*record.FakeRecorder).Events).To(Receive(Equal("Warning TriggerCycle " + condition.Message)))
+
+		// the event is only recorded once
+		_, err = r.startTriggeredScans(context.Background(), scheduledScan, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Recorder.(*record.FakeRecorder).Events).NotTo(Receive())
+	})
+
+	It("should detect ScheduledScans triggering themselves and longer cycles", func() {
+		withTriggers := func(name string, triggers ...string) executionv1.ScheduledScan {
+			scheduledScan := executionv1.ScheduledScan{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			for _, trigger := range triggers {
+				scheduledScan.Spec.Triggers = append(scheduledScan.Spec.Triggers, executionv1.ScheduledScanTrigger{ScheduledScan: trigger})
+			}
+			return scheduledScan
+		}
+		a := withTriggers("a", "a", "c")
+		others := []executionv1.ScheduledScan{withTriggers("b", "a"), withTriggers("c", "b"), withTriggers("d", "a")}
+
+		Expect(getTriggerCycle(a, "a", others)).To(Equal([]string{"a", "a"}))
+		Expect(getTriggerCycle(a, "c", others)).To(Equal([]string{"a", "b", "c", "a"}))
+		Expect(getTriggerCycle(withTriggers("e", "d"), "d", others)).To(BeNil())
+	})
+
+	It("should ignore scans which aren't done or were completed before the ScheduledScan was created", func() {
+		setup(
+			newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created.Add(-10*time.Minute), 0),
+			newScan("subfinder-2", "subfinder", executionv1.ScanStateErrored, created.Add(10*time.Minute), 0),
+			newScan("amass-1", "amass", executionv1.ScanStateDone, created.Add(10*time.Minute), 0),
+		)
+
+		_, err := r.startTriggeredScans(context.Background(), scheduledScan, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getScans("nuclei")).To(BeEmpty())
+	})
+
+	It("should only start a scan if the completed scan matches the condition of the trigger", func() {
+		scheduledScan.Spec.Triggers[0].When = &executionv1.HookCondition{Findings: []string{"findings.severities.high > 0"}}
+		setup(newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created.Add(10*time.Minute), 0))
+
+		_, err := r.startTriggeredScans(context.Background(), scheduledScan, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getScans("nuclei")).To(BeEmpty())
+		Expect(scheduledScan.Status.Triggers[0].LastScan).To(Equal("subfinder-1"))
+		Expect(scheduledScan.Status.Triggers[0].LastTriggerTime).To(BeNil())
+	})
+
+	It("should wait for running scans with the Forbid concurrency policy", func() {
+		scheduledScan.Spec.ConcurrencyPolicy = executionv1.ForbidConcurrent
+		running := newScan("nuclei-1", "nuclei", executionv1.ScanStateScanning, time.Time{}, 0)
+		setup(newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created.Add(10*time.Minute), 0), running)
+
+		requeueAfter, err := r.startTriggeredScans(context.Background(), scheduledScan, []executionv1.Scan{*running})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requeueAfter).To(Equal(1 * time.Minute))
+		Expect(getScans("nuclei")).To(HaveLen(1))
+		// the completed scan is evaluated again in the next reconcile
+		Expect(scheduledScan.Status.Triggers).To(HaveLen(1))
+		Expect(scheduledScan.Status.Triggers[0].LastScan).To(BeEmpty())
+	})
+
+	It("should map completed scans to the ScheduledScans triggered by them", func() {
+		setup()
+
+		Expect(r.getTriggeredScheduledScans(context.Background(), newScan("subfinder-1", "subfinder", executionv1.ScanStateDone, created, 0))).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Name: "nuclei", Namespace: "default"}},
+		}))
+		Expect(r.getTriggeredScheduledScans(context.Background(), newScan("subfinder-1", "subfinder", executionv1.ScanStateScanning, created, 0))).To(BeEmpty())
+		Expect(r.getTriggeredScheduledScans(context.Background(), newScan("amass-1", "amass", executionv1.ScanStateDone, created, 0))).To(BeEmpty())
+	})
+})
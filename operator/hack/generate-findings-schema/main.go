@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// generate-findings-schema prints the JSON schema of the findings generated from the Finding type
+package main
+
+import (
+	"fmt"
+	"os"
+
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+)
+
+func main() {
+	schema, err := findingsv1.JSONSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate the findings schema: %s\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(schema)
+}
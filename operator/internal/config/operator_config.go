@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// APIVersion of the OperatorConfig file format
+	APIVersion = "config.securecodebox.io/v1"
+	// Kind of the OperatorConfig file format
+	Kind = "OperatorConfig"
+)
+
+// OperatorConfig configures the secureCodeBox operator.
+type OperatorConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	S3                               S3Config                  `json:"s3"`
+	Lurker                           LurkerConfig              `json:"lurker"`
+	URLExpiration                    URLExpirationConfig       `json:"urlExpiration"`
+	CustomCACertificate              CustomCACertificateConfig `json:"customCACertificate"`
+	AllowIstioSidecarInjectionInJobs bool                      `json:"allowIstioSidecarInjectionInJobs"`
+	PodOverrides                     PodOverridesConfig        `json:"podOverrides"`
+	TelemetryEnabled                 bool                      `json:"telemetryEnabled"`
+	JobDefaults                      JobDefaultsConfig         `json:"jobDefaults"`
+	Findings                         FindingsConfig            `json:"findings"`
+	// ManageJobRBAC lets the operator create and repair the ServiceAccounts, Roles and RoleBindings of the lurker, parser and hook jobs.
+	// Disable it if they are provisioned otherwise, e.g. via GitOps.
+	ManageJobRBAC bool `json:"manageJobRBAC"`
+}
+
+// S3Config configures the connection to the s3 (or compatible) storage used to store raw results and findings.
+// Credentials are not part of the config file and are read from the MINIO_ACCESS_KEY and MINIO_SECRET_KEY env vars.
+type S3Config struct {
+	Endpoint string `json:"endpoint"`
+	// Port of the s3 endpoint, the default port of the protocol is used when not set
+	Port   int32  `json:"port,omitempty"`
+	UseSSL bool   `json:"useSSL"`
+	Bucket string `json:"bucket"`
+	// URLTemplate is a go template that generates the path used to store the files of a scan in the bucket
+	URLTemplate string `json:"urlTemplate"`
+	// AuthType is one of "access-secret-key" or "aws-iam". "aws-irsa" is still supported as an alias of "aws-iam"
+	AuthType       string `json:"authType,omitempty"`
+	AwsStsEndpoint string `json:"awsStsEndpoint,omitempty"`
+	// ArchivePrefix is the path in the bucket the files of scans are moved to when ScheduledScans archive deleted scans
+	ArchivePrefix string `json:"archivePrefix"`
+}
+
+// LurkerConfig configures the lurker sidecar which extracts the raw results from the scanner container.
+type LurkerConfig struct {
+	Image          string                    `json:"image"`
+	PullPolicy     corev1.PullPolicy         `json:"pullPolicy"`
+	SeccompProfile corev1.SeccompProfileType `json:"seccompProfile"`
+}
+
+// URLExpirationConfig configures how long the presigned urls handed to the scan, parser and hook jobs are valid.
+type URLExpirationConfig struct {
+	Scan   metav1.Duration `json:"scan"`
+	Parser metav1.Duration `json:"parser"`
+	Hook   metav1.Duration `json:"hook"`
+}
+
+// CustomCACertificateConfig configures the ConfigMap with custom CA certificates mounted into the lurker, parser and hook containers.
+type CustomCACertificateConfig struct {
+	// ExistingCertificate is the name of the ConfigMap holding the certificate(s). No certificates are mounted if empty.
+	ExistingCertificate string `json:"existingCertificate,omitempty"`
+	// Certificate is the key in the ConfigMap holding the certificate(s)
+	Certificate string `json:"certificate"`
+}
+
+// PodOverridesConfig configures which pod spec fields can be overridden via `scan.spec.podOverrides`.
+type PodOverridesConfig struct {
+	AllowedFields []string `json:"allowedFields"`
+}
+
+// JobDefaultsConfig contains the defaults for the jobs started by the operator.
+type JobDefaultsConfig struct {
+	Parser JobDefaults `json:"parser"`
+	Hook   JobDefaults `json:"hook"`
+}
+
+// JobDefaults are applied to parser and hook jobs before the settings of the respective ParseDefinition or ScanCompletionHook.
+type JobDefaults struct {
+	// Resources of the parser / hook container, used if the ParseDefinition / ScanCompletionHook doesn't specify any.
+	Resources corev1.ResourceRequirements `json:"resources"`
+	// BackoffLimit of the job
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// ActiveDeadlineSeconds of the job
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// SecurityContext of the parser / hook container
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+}
+
+// FindingsConfig configures how the operator processes the findings written by parsers and ReadAndWrite hooks.
+type FindingsConfig struct {
+	// MaxSize of a findings file. The operator validates the findings and computes the finding stats in memory, scans with larger findings are marked as Errored.
+	// Raise the memory limit of the operator when increasing it.
+	MaxSize resource.Quantity `json:"maxSize"`
+}
+
+// Default returns the configuration used when no config file is provided.
+func Default() OperatorConfig {
+	return OperatorConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: APIVersion,
+			Kind:       Kind,
+		},
+		S3: S3Config{
+			UseSSL:        true,
+			URLTemplate:   "scan-{{ .Scan.UID }}/{{ .Filename }}",
+			AuthType:      "access-secret-key",
+			ArchivePrefix: "archive/",
+		},
+		Lurker: LurkerConfig{
+			Image:          "securecodebox/lurker:latest",
+			PullPolicy:     corev1.PullAlways,
+			SeccompProfile: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+		URLExpiration: URLExpirationConfig{
+			Scan:   metav1.Duration{Duration: time.Hour},
+			Parser: metav1.Duration{Duration: time.Hour},
+			Hook:   metav1.Duration{Duration: time.Hour},
+		},
+		CustomCACertificate: CustomCACertificateConfig{
+			Certificate: "public.crt",
+		},
+		PodOverrides: PodOverridesConfig{
+			AllowedFields: []string{
+				"priorityClassName",
+				"runtimeClassName",
+				"imagePullSecrets",
+				"hostAliases",
+				"dnsConfig",
+			},
+		},
+		JobDefaults: JobDefaultsConfig{
+			Parser: defaultJobDefaults(),
+			Hook:   defaultJobDefaults(),
+		},
+		Findings: FindingsConfig{
+			MaxSize: resource.MustParse("10Mi"),
+		},
+		TelemetryEnabled: true,
+		ManageJobRBAC:    true,
+	}
+}
+
+func defaultJobDefaults() JobDefaults {
+	var backOffLimit int32 = 3
+	truePointer := true
+	falsePointer := false
+
+	return JobDefaults{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("200m"),
+				corev1.ResourceMemory: resource.MustParse("100Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("400m"),
+				corev1.ResourceMemory: resource.MustParse("200Mi"),
+			},
+		},
+		BackoffLimit: &backOffLimit,
+		SecurityContext: &corev1.SecurityContext{
+			RunAsNonRoot:             &truePointer,
+			AllowPrivilegeEscalation: &falsePointer,
+			ReadOnlyRootFilesystem:   &truePointer,
+			Privileged:               &falsePointer,
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		},
+	}
+}
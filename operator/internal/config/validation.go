@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Validate checks the config for values which would otherwise only fail once a scan is running.
+// All problems found are returned together.
+func (c OperatorConfig) Validate() error {
+	var errs []error
+
+	if c.APIVersion != APIVersion || c.Kind != Kind {
+		errs = append(errs, fmt.Errorf("unsupported config apiVersion / kind '%s/%s', expected '%s/%s'", c.APIVersion, c.Kind, APIVersion, Kind))
+	}
+
+	if c.S3.Port < 0 || c.S3.Port > 65535 {
+		errs = append(errs, fmt.Errorf("s3.port must be between 0 and 65535, got %d", c.S3.Port))
+	}
+	switch c.S3.AuthType {
+	case "", "access-secret-key", "aws-iam", "aws-irsa":
+	default:
+		errs = append(errs, fmt.Errorf("unknown s3.authType '%s', supported are 'access-secret-key' and 'aws-iam'", c.S3.AuthType))
+	}
+	if _, err := template.New("urlTemplate").Parse(c.S3.URLTemplate); err != nil {
+		errs = append(errs, fmt.Errorf("s3.urlTemplate is not a valid go template: %w", err))
+	}
+
+	if strings.Trim(c.S3.ArchivePrefix, "/") == "" {
+		errs = append(errs, errors.New("s3.archivePrefix must not be empty, archived files would overwrite the files of the scans"))
+	}
+
+	if c.Lurker.Image == "" {
+		errs = append(errs, errors.New("lurker.image must not be empty"))
+	}
+	switch c.Lurker.PullPolicy {
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+	default:
+		errs = append(errs, fmt.Errorf("unknown lurker.pullPolicy '%s'", c.Lurker.PullPolicy))
+	}
+	switch c.Lurker.SeccompProfile {
+	case corev1.SeccompProfileTypeLocalhost, corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeUnconfined:
+	default:
+		errs = append(errs, fmt.Errorf("unknown lurker.seccompProfile '%s'", c.Lurker.SeccompProfile))
+	}
+
+	for name, duration := range map[string]float64{
+		"scan":   c.URLExpiration.Scan.Seconds(),
+		"parser": c.URLExpiration.Parser.Seconds(),
+		"hook":   c.URLExpiration.Hook.Seconds(),
+	} {
+		if duration <= 0 {
+			errs = append(errs, fmt.Errorf("urlExpiration.%s must be a positive duration", name))
+		}
+	}
+
+	if c.CustomCACertificate.ExistingCertificate != "" && c.CustomCACertificate.Certificate == "" {
+		errs = append(errs, errors.New("customCACertificate.certificate must be set when customCACertificate.existingCertificate is configured"))
+	}
+
+	if c.Findings.MaxSize.Sign() <= 0 {
+		errs = append(errs, errors.New("findings.maxSize must be a positive quantity"))
+	}
+
+	return errors.Join(errs...)
+}
@@ -7,16 +7,20 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -26,6 +30,7 @@ import (
 	executioncontrollers "github.com/secureCodeBox/secureCodeBox/operator/controllers/execution"
 	scancontroller "github.com/secureCodeBox/secureCodeBox/operator/controllers/execution/scans"
 	"github.com/secureCodeBox/secureCodeBox/operator/internal/telemetry"
+	"github.com/secureCodeBox/secureCodeBox/operator/utils"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -46,8 +51,22 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var configFile string
+	var triggerAddr string
+	var triggerCertDir string
+	var triggerInsecure bool
+	flag.StringVar(&configFile, "config", "",
+		"The operator will load its configuration from this file and reload it on changes. "+
+			"Omit this flag to use the default configuration values. "+
+			"Environment variables override configuration from this file.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&triggerAddr, "trigger-bind-address", "0", "The address the trigger endpoint for ScheduledScans binds to. "+
+		"Set this to \"0\" to disable the trigger endpoint.")
+	flag.StringVar(&triggerCertDir, "trigger-cert-dir", "", "The directory containing the certificate (tls.crt) and key (tls.key) the trigger endpoint is served with. "+
+		"Required unless --trigger-insecure is set.")
+	flag.BoolVar(&triggerInsecure, "trigger-insecure", false, "Serve the trigger endpoint using plain HTTP. "+
+		"Only use this if TLS is terminated in front of the operator, as requests contain the tokens of the httpTriggers.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -61,6 +80,12 @@ func main() {
 	ctrl.SetLogger(logger)
 	klog.SetLogger(logger)
 
+	operatorConfig, err := utils.LoadOperatorConfig(configFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load the config file")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: server.Options{
@@ -69,26 +94,41 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "e341d981.securecodebox.io",
+		Cache: cache.Options{
+			// only cache the ServiceAccounts, Roles and RoleBindings managed by the operator instead of the ones of the whole cluster
+			ByObject: scancontroller.RBACCacheByObject(),
+		},
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// secrets are only read for webhook hooks, don't cache (and watch) all secrets of the cluster
+				DisableFor: []client.Object{&corev1.Secret{}},
+			},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err = (&scancontroller.ScanReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("execution").WithName("Scan"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	scanReconciler := &scancontroller.ScanReconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       ctrl.Log.WithName("controllers").WithName("execution").WithName("Scan"),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("ScanController"),
+		Config:    operatorConfig,
+	}
+	if err = scanReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Scan")
 		os.Exit(1)
 	}
-	if err = (&executioncontrollers.ScheduledScanReconciler{
+	scheduledScanReconciler := &executioncontrollers.ScheduledScanReconciler{
 		Client:   mgr.GetClient(),
 		Log:      ctrl.Log.WithName("controllers").WithName("execution").WithName("ScheduledScan"),
 		Recorder: mgr.GetEventRecorderFor("ScheduledScanController"),
 		Scheme:   mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if err = scheduledScanReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScheduledScan")
 		os.Exit(1)
 	}
@@ -101,6 +141,18 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ScanTypeController")
 		os.Exit(1)
 	}
+	if operatorConfig.ManageJobRBAC {
+		if err = (&scancontroller.RBACReconciler{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Log:       ctrl.Log.WithName("controllers").WithName("execution").WithName("RBAC"),
+			Scheme:    mgr.GetScheme(),
+			Recorder:  mgr.GetEventRecorderFor("RBACController"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "RBAC")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -111,8 +163,36 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("config", scanReconciler.ReadinessCheck); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
+	if err := mgr.Add(&utils.OperatorConfigWatcher{
+		Filename: configFile,
+		Interval: 30 * time.Second,
+		Log:      ctrl.Log.WithName("config"),
+		Current:  operatorConfig,
+		OnChange: scanReconciler.UpdateConfig,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up config watcher")
+		os.Exit(1)
+	}
+
+	if triggerAddr != "0" {
+		if err := mgr.Add(&executioncontrollers.TriggerServer{
+			Reconciler:  scheduledScanReconciler,
+			Log:         ctrl.Log.WithName("trigger"),
+			BindAddress: triggerAddr,
+			CertDir:     triggerCertDir,
+			Insecure:    triggerInsecure,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up trigger endpoint")
+			os.Exit(1)
+		}
+	}
 
-	if enabled, ok := os.LookupEnv("TELEMETRY_ENABLED"); ok && enabled == "true" {
+	if operatorConfig.TelemetryEnabled {
 		go telemetry.Loop(mgr.GetClient(), ctrl.Log.WithName("telemetry"))
 	}
 
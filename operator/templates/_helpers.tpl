@@ -54,4 +54,58 @@ Selector labels
 {{- define "operator.selectorLabels" -}}
 app.kubernetes.io/name: {{ include "operator.name" . }}
 app.kubernetes.io/instance: {{ .Release.Name }}
-{{- end }}
\ No newline at end of file
+{{- end }}
+{{/*
+Config file of the operator.
+Settings configured via the other values of this chart are rendered into the config, keys set in .Values.config take precedence over them.
+*/}}
+{{- define "operator.config" -}}
+{{- $s3 := dict }}
+{{- if .Values.minio.enabled }}
+{{- $s3 = dict "useSSL" .Values.minio.tls.enabled "endpoint" (printf "%s-minio.%s.svc.%s" .Release.Name .Release.Namespace .Values.clusterDomain) "port" 9000 "bucket" .Values.minio.defaultBuckets }}
+{{- else }}
+{{- $s3 = dict "useSSL" .Values.s3.tls.enabled "endpoint" .Values.s3.endpoint "bucket" .Values.s3.bucket "authType" .Values.s3.authType }}
+{{- if .Values.s3.port }}
+{{- $_ := set $s3 "port" (int .Values.s3.port) }}
+{{- end }}
+{{- /* todo(v6): remove support for authType = "aws-irsa" and only support "aws-iam": https://github.com/secureCodeBox/secureCodeBox/issues/3327 */}}
+{{- if or (eq .Values.s3.authType "aws-irsa") (eq .Values.s3.authType "aws-iam") }}
+{{- $_ := set $s3 "awsStsEndpoint" .Values.s3.awsStsEndpoint }}
+{{- end }}
+{{- end }}
+{{- if .Values.s3.urlTemplate }}
+{{- $_ := set $s3 "urlTemplate" .Values.s3.urlTemplate }}
+{{- end }}
+{{- $generated := dict
+  "s3" $s3
+  "lurker" (dict
+    "image" (printf "%s:%s" .Values.lurker.image.repository (.Values.lurker.image.tag | default .Chart.Version))
+    "pullPolicy" .Values.lurker.image.pullPolicy
+    "seccompProfile" .Values.securityContext.seccompProfile.type)
+  "urlExpiration" (dict
+    "scan" .Values.presignedUrlExpirationTimes.scanners
+    "parser" .Values.presignedUrlExpirationTimes.parsers
+    "hook" .Values.presignedUrlExpirationTimes.hooks)
+  "allowIstioSidecarInjectionInJobs" .Values.allowIstioSidecarInjectionInJobs
+  "podOverrides" (dict "allowedFields" .Values.podOverrides.allowedFields)
+  "telemetryEnabled" .Values.telemetryEnabled
+  "manageJobRBAC" .Values.manageJobRBAC
+}}
+{{- if .Values.customCACertificate.existingCertificate }}
+{{- $_ := set $generated "customCACertificate" (dict "existingCertificate" .Values.customCACertificate.existingCertificate "certificate" .Values.customCACertificate.certificate) }}
+{{- end }}
+{{- $config := deepCopy .Values.config }}
+{{- range $key, $value := $generated }}
+{{- if not (hasKey $config $key) }}
+{{- $_ := set $config $key $value }}
+{{- else if and (kindIs "map" $value) (kindIs "map" (get $config $key)) }}
+{{- $nested := get $config $key }}
+{{- range $nestedKey, $nestedValue := $value }}
+{{- if not (hasKey $nested $nestedKey) }}
+{{- $_ := set $nested $nestedKey $nestedValue }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- toPrettyJson $config }}
+{{- end }}
@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/robfig/cron"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+)
+
+// maxScheduleDeferral limits how far scans are deferred by time windows and blackouts, to detect windows which never open
+const maxScheduleDeferral = 366 * 24 * time.Hour
+
+// NextAllowedTime returns the earliest time at or after t which lies in one of the allowed windows and outside of all blackouts.
+// The returned reason describes why t itself isn't allowed and is empty if t is allowed.
+// Returns an error if a window is invalid or if no allowed time exists within the next year.
+func NextAllowedTime(t time.Time, windows []executionv1.TimeWindow, blackouts []executionv1.Blackout) (time.Time, string, error) {
+	parsedWindows := make([]timeWindow, len(windows))
+	for i, window := range windows {
+		parsed, err := parseTimeWindow(window)
+		if err != nil {
+			return time.Time{}, "", fmt.Errorf("invalid allowed window %d: %w", i, err)
+		}
+		parsedWindows[i] = parsed
+	}
+
+	reason := ""
+	next := t
+	for !next.After(t.Add(maxScheduleDeferral)) {
+		if blackout := activeBlackout(next, blackouts); blackout != nil {
+			if reason == "" {
+				reason = fmt.Sprintf("blackout until %s", blackout.End.UTC().Format(time.RFC3339))
+				if blackout.Reason != "" {
+					reason = fmt.Sprintf("%s (%s)", reason, blackout.Reason)
+				}
+			}
+			next = blackout.End.Time
+			continue
+		}
+
+		if len(parsedWindows) == 0 || slices.ContainsFunc(parsedWindows, func(window timeWindow) bool { return window.contains(next) }) {
+			return next.In(t.Location()), reason, nil
+		}
+		opening := nextWindowOpening(next, parsedWindows)
+		if reason == "" {
+			reason = fmt.Sprintf("outside of the allowed windows until %s", opening.UTC().Format(time.RFC3339))
+		}
+		next = opening
+	}
+	return time.Time{}, "", errors.New("no allowed time to start a scan within the next year, check the allowed windows and blackouts")
+}
+
+// activeBlackout returns the blackout t lies in, or nil if there is none
+func activeBlackout(t time.Time, blackouts []executionv1.Blackout) *executionv1.Blackout {
+	for i, blackout := range blackouts {
+		if !t.Before(blackout.Start.Time) && t.Before(blackout.End.Time) {
+			return &blackouts[i]
+		}
+	}
+	return nil
+}
+
+// nextWindowOpening returns the earliest time after t at which one of the windows opens
+func nextWindowOpening(t time.Time, windows []timeWindow) time.Time {
+	var next time.Time
+	for _, window := range windows {
+		if opening := window.nextOpening(t); next.IsZero() || opening.Before(next) {
+			next = opening
+		}
+	}
+	return next
+}
+
+// timeWindow is a parsed executionv1.TimeWindow
+type timeWindow struct {
+	location *time.Location
+	// schedule and duration are set for windows defined by a cron schedule
+	schedule cron.Schedule
+	duration time.Duration
+	// days, start and end are set for windows defined by a daily time range. Start and end are minutes since midnight
+	days       []time.Weekday
+	start, end int
+}
+
+// LoadTimeZone loads the location of an IANA time zone name used by ScheduledScans.
+// Unlike time.LoadLocation it doesn't accept "Local", as the time zone of the operator is selected by omitting the time zone.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q: omit the time zone to use the time zone of the operator", name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %w", name, err)
+	}
+	return location, nil
+}
+
+func parseTimeWindow(window executionv1.TimeWindow) (timeWindow, error) {
+	parsed := timeWindow{location: time.UTC}
+	if window.TimeZone != "" {
+		location, err := LoadTimeZone(window.TimeZone)
+		if err != nil {
+			return parsed, err
+		}
+		parsed.location = location
+	}
+
+	if window.Schedule != "" {
+		if window.Start != "" || window.End != "" || len(window.Days) > 0 {
+			return parsed, errors.New("a window can either use a schedule or start, end and days")
+		}
+		if window.Duration == nil || window.Duration.Duration <= 0 {
+			return parsed, errors.New("windows using a schedule require a positive duration")
+		}
+		schedule, err := cron.ParseStandard(window.Schedule)
+		if err != nil {
+			return parsed, fmt.Errorf("unparseable schedule %q: %w", window.Schedule, err)
+		}
+		parsed.schedule = schedule
+		parsed.duration = window.Duration.Duration
+		return parsed, nil
+	}
+
+	var err error
+	if parsed.start, err = parseClockTime(window.Start); err != nil {
+		return parsed, fmt.Errorf("invalid start: %w", err)
+	}
+	if parsed.end, err = parseClockTime(window.End); err != nil {
+		return parsed, fmt.Errorf("invalid end: %w", err)
+	}
+	for _, day := range window.Days {
+		weekday, err := parseWeekday(day)
+		if err != nil {
+			return parsed, err
+		}
+		parsed.days = append(parsed.days, weekday)
+	}
+	return parsed, nil
+}
+
+// parseClockTime parses a time in the format HH:MM into the minutes since midnight
+func parseClockTime(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("time %q must be in the format HH:MM", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func parseWeekday(day executionv1.Weekday) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if weekday.String() == string(day) {
+			return weekday, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q", day)
+}
+
+func (w timeWindow) appliesTo(day time.Weekday) bool {
+	return len(w.days) == 0 || slices.Contains(w.days, day)
+}
+
+func (w timeWindow) contains(t time.Time) bool {
+	t = t.In(w.location)
+	if w.schedule != nil {
+		// the window containing t opened at the first scheduled time after t - duration
+		return !w.schedule.Next(t.Add(-w.duration)).After(t)
+	}
+
+	minutes := t.Hour()*60 + t.Minute()
+	switch {
+	case w.start == w.end:
+		return w.appliesTo(t.Weekday())
+	case w.start < w.end:
+		return w.appliesTo(t.Weekday()) && minutes >= w.start && minutes < w.end
+	case minutes >= w.start:
+		return w.appliesTo(t.Weekday())
+	case minutes < w.end:
+		// the window spanning midnight started the day before
+		return w.appliesTo(t.AddDate(0, 0, -1).Weekday())
+	}
+	return false
+}
+
+// nextOpening returns the next time after t at which the window opens
+func (w timeWindow) nextOpening(t time.Time) time.Time {
+	t = t.In(w.location)
+	if w.schedule != nil {
+		return w.schedule.Next(t)
+	}
+
+	for days := 0; days <= 7; days++ {
+		day := t.AddDate(0, 0, days)
+		opening := time.Date(day.Year(), day.Month(), day.Day(), w.start/60, w.start%60, 0, 0, w.location)
+		if opening.After(t) && w.appliesTo(opening.Weekday()) {
+			return opening
+		}
+	}
+	// unreachable as every window applies to at least one day of the week
+	return t.AddDate(0, 0, 8)
+}
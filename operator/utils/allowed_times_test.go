@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NextAllowedTime", func() {
+	// Monday, 19th of October 2026
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, time.UTC)
+	}
+	nightly := executionv1.TimeWindow{Start: "22:00", End: "06:00"}
+
+	It("should allow every time without windows and blackouts", func() {
+		next, reason, err := NextAllowedTime(monday(12, 0), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(monday(12, 0)))
+		Expect(reason).To(BeEmpty())
+	})
+
+	It("should defer scans to the start of the next window", func() {
+		next, reason, err := NextAllowedTime(monday(12, 0), []executionv1.TimeWindow{nightly}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(monday(22, 0)))
+		Expect(reason).To(Equal("outside of the allowed windows until 2026-10-19T22:00:00Z"))
+	})
+
+	It("should allow times in windows spanning midnight", func() {
+		next, reason, err := NextAllowedTime(monday(3, 30), []executionv1.TimeWindow{nightly}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(monday(3, 30)))
+		Expect(reason).To(BeEmpty())
+	})
+
+	It("should only open windows on the configured days", func() {
+		weekend := executionv1.TimeWindow{Days: []executionv1.Weekday{"Saturday", "Sunday"}, Start: "00:00", End: "00:00"}
+		next, _, err := NextAllowedTime(monday(12, 0), []executionv1.TimeWindow{weekend}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("should respect the time zone of windows", func() {
+		window := executionv1.TimeWindow{Start: "22:00", End: "06:00", TimeZone: "Europe/Berlin"}
+		next, _, err := NextAllowedTime(monday(12, 0), []executionv1.TimeWindow{window}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		// Berlin is at UTC+2 in October before the switch to winter time
+		Expect(next).To(Equal(monday(20, 0)))
+	})
+
+	It("should support windows defined by a cron schedule", func() {
+		window := executionv1.TimeWindow{Schedule: "0 1 * * *", Duration: &metav1.Duration{Duration: 2 * time.Hour}}
+		next, _, err := NextAllowedTime(monday(2, 30), []executionv1.TimeWindow{window}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(monday(2, 30)))
+
+		next, _, err = NextAllowedTime(monday(3, 0), []executionv1.TimeWindow{window}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(time.Date(2026, 10, 20, 1, 0, 0, 0, time.UTC)))
+	})
+
+	It("should defer scans to the end of blackouts and into the next window", func() {
+		blackouts := []executionv1.Blackout{{
+			Start:  metav1.NewTime(monday(0, 0)),
+			End:    metav1.NewTime(time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC)),
+			Reason: "change freeze",
+		}}
+		next, reason, err := NextAllowedTime(monday(12, 0), []executionv1.TimeWindow{nightly}, blackouts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(time.Date(2026, 10, 21, 22, 0, 0, 0, time.UTC)))
+		Expect(reason).To(Equal("blackout until 2026-10-21T12:00:00Z (change freeze)"))
+	})
+
+	It("should return an error for invalid windows", func() {
+		_, _, err := NextAllowedTime(monday(12, 0), []executionv1.TimeWindow{{Start: "25:00", End: "06:00"}}, nil)
+		Expect(err).To(MatchError(`invalid allowed window 0: invalid start: time "25:00" must be in the format HH:MM`))
+
+		_, _, err = NextAllowedTime(monday(12, 0), []executionv1.TimeWindow{{Schedule: "0 1 * * *"}}, nil)
+		Expect(err).To(MatchError("invalid allowed window 0: windows using a schedule require a positive duration"))
+
+		_, _, err = NextAllowedTime(monday(12, 0), []executionv1.TimeWindow{{Start: "22:00", End: "06:00", TimeZone: "Mars/Olympus_Mons"}}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an error if the scan would be deferred for more than a year", func() {
+		blackouts := []executionv1.Blackout{{Start: metav1.NewTime(monday(0, 0)), End: metav1.NewTime(monday(0, 0).AddDate(2, 0, 0))}}
+		_, _, err := NextAllowedTime(monday(12, 0), nil, blackouts)
+		Expect(err).To(HaveOccurred())
+	})
+})
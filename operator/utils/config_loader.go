@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+)
+
+// LoadOperatorConfig reads a YAML config file and unmarshals it into OperatorConfig.
+// Values not set in the file keep their defaults. If no filename is given the default config is used.
+// Environment variables set for the operator take precedence over the values of the file.
+// The resulting config is validated before it is returned.
+func LoadOperatorConfig(filename string) (config.OperatorConfig, error) {
+	cfg := config.Default()
+
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return config.OperatorConfig{}, err
+		}
+
+		if err = yaml.UnmarshalStrict(data, &cfg); err != nil {
+			return config.OperatorConfig{}, err
+		}
+	}
+
+	if err := applyEnvOverrides(&cfg); err != nil {
+		return config.OperatorConfig{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return config.OperatorConfig{}, fmt.Errorf("invalid operator config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// applyEnvOverrides applies the environment variables previously used to configure the operator on top of the config.
+func applyEnvOverrides(cfg *config.OperatorConfig) error {
+	if value, ok := os.LookupEnv("S3_ENDPOINT"); ok {
+		cfg.S3.Endpoint = value
+	}
+	if value, ok := os.LookupEnv("S3_PORT"); ok && value != "" {
+		port, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("cannot parse env variable S3_PORT: %w", err)
+		}
+		cfg.S3.Port = int32(port)
+	}
+	if err := lookupBoolEnv("S3_USE_SSL", &cfg.S3.UseSSL); err != nil {
+		return err
+	}
+	if value, ok := os.LookupEnv("S3_BUCKET"); ok {
+		cfg.S3.Bucket = value
+	}
+	if value, ok := os.LookupEnv("S3_URL_TEMPLATE"); ok {
+		cfg.S3.URLTemplate = value
+	}
+	if value, ok := os.LookupEnv("S3_AUTH_TYPE"); ok {
+		cfg.S3.AuthType = strings.ToLower(value)
+	}
+	// todo(v6): remove support for S3_AWS_STS_ENDPOINT env var and only support S3_AWS_IRSA_STS_ENDPOINT: https://github.com/secureCodeBox/secureCodeBox/issues/3327
+	if value, ok := os.LookupEnv("S3_AWS_IRSA_STS_ENDPOINT"); ok {
+		cfg.S3.AwsStsEndpoint = value
+	}
+	if value, ok := os.LookupEnv("S3_AWS_STS_ENDPOINT"); ok {
+		cfg.S3.AwsStsEndpoint = value
+	}
+
+	if value, ok := os.LookupEnv("LURKER_IMAGE"); ok && value != "" {
+		cfg.Lurker.Image = value
+	}
+	if value, ok := os.LookupEnv("LURKER_PULL_POLICY"); ok && value != "" {
+		cfg.Lurker.PullPolicy = corev1.PullPolicy(value)
+	}
+	if value, ok := os.LookupEnv("LURKER_SECCOMP_PROFILE"); ok && value != "" {
+		cfg.Lurker.SeccompProfile = corev1.SeccompProfileType(value)
+	}
+
+	for controller, duration := range map[ControllerType]*metav1.Duration{
+		ScanController:   &cfg.URLExpiration.Scan,
+		ParserController: &cfg.URLExpiration.Parser,
+		HookController:   &cfg.URLExpiration.Hook,
+	} {
+		name := "URL_EXPIRATION_" + controller.String()
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("cannot parse env variable %s: %w", name, err)
+			}
+			duration.Duration = parsed
+		}
+	}
+
+	if value, ok := os.LookupEnv("CUSTOM_CA_CERTIFICATE_EXISTING_CERTIFICATE"); ok {
+		cfg.CustomCACertificate.ExistingCertificate = value
+	}
+	if value, ok := os.LookupEnv("CUSTOM_CA_CERTIFICATE_NAME"); ok {
+		cfg.CustomCACertificate.Certificate = value
+	}
+
+	if err := lookupBoolEnv("ALLOW_ISTIO_SIDECAR_INJECTION_IN_JOBS", &cfg.AllowIstioSidecarInjectionInJobs); err != nil {
+		return err
+	}
+	if value, ok := os.LookupEnv("POD_OVERRIDES_ALLOWED_FIELDS"); ok {
+		cfg.PodOverrides.AllowedFields = SplitCommaSeparatedList(value)
+	}
+	if err := lookupBoolEnv("TELEMETRY_ENABLED", &cfg.TelemetryEnabled); err != nil {
+		return err
+	}
+	if err := lookupBoolEnv("MANAGE_JOB_RBAC", &cfg.ManageJobRBAC); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func lookupBoolEnv(name string, target *bool) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("cannot parse env variable %s: %w", name, err)
+	}
+	*target = parsed
+	return nil
+}
+
+// SplitCommaSeparatedList splits a comma separated list and drops empty entries.
+func SplitCommaSeparatedList(raw string) []string {
+	values := []string{}
+	for _, value := range strings.Split(raw, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
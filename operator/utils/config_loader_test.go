@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("LoadOperatorConfig", func() {
+	It("should return the default config if no file is configured", func() {
+		cfg, err := LoadOperatorConfig("")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg).To(Equal(config.Default()))
+	})
+
+	It("should override the defaults with the values set in the config file", func() {
+		cfg, err := LoadOperatorConfig(filepath.Join("__testfiles__", "operator_config_test.yaml"))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(cfg.APIVersion).To(Equal("config.securecodebox.io/v1"))
+		Expect(cfg.Kind).To(Equal("OperatorConfig"))
+
+		parser := cfg.JobDefaults.Parser
+		Expect(parser.Resources.Requests[corev1.ResourceCPU]).To(Equal(resource.MustParse("100m")))
+		Expect(parser.Resources.Requests[corev1.ResourceMemory]).To(Equal(resource.MustParse("100Mi")))
+		Expect(parser.Resources.Limits[corev1.ResourceCPU]).To(Equal(resource.MustParse("1")))
+		Expect(parser.Resources.Limits[corev1.ResourceMemory]).To(Equal(resource.MustParse("1Gi")))
+		Expect(*parser.ActiveDeadlineSeconds).To(Equal(int64(3600)))
+		Expect(*parser.BackoffLimit).To(Equal(int32(3)))
+
+		hook := cfg.JobDefaults.Hook
+		Expect(*hook.BackoffLimit).To(Equal(int32(5)))
+		Expect(hook.ActiveDeadlineSeconds).To(BeNil())
+		Expect(hook.SecurityContext).To(Equal(config.Default().JobDefaults.Hook.SecurityContext))
+
+		Expect(cfg.S3).To(Equal(config.S3Config{
+			Endpoint:      "minio.default.svc",
+			Port:          9000,
+			UseSSL:        false,
+			Bucket:        "securecodebox",
+			URLTemplate:   "scan-{{ .Scan.UID }}/{{ .Filename }}",
+			AuthType:      "access-secret-key",
+			ArchivePrefix: "archive/",
+		}))
+		Expect(cfg.Lurker.Image).To(Equal("docker.io/securecodebox/lurker:4.0.0"))
+		Expect(cfg.Lurker.PullPolicy).To(Equal(corev1.PullIfNotPresent))
+		Expect(cfg.Lurker.SeccompProfile).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+		Expect(cfg.URLExpiration.Scan.Duration).To(Equal(12 * time.Hour))
+		Expect(cfg.URLExpiration.Parser.Duration).To(Equal(time.Hour))
+		Expect(cfg.TelemetryEnabled).To(BeTrue())
+		Expect(cfg.Findings.MaxSize).To(Equal(resource.MustParse("20Mi")))
+	})
+
+	It("should reject invalid values", func() {
+		_, err := LoadOperatorConfig(filepath.Join("__testfiles__", "invalid_operator_config_test.yaml"))
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("lurker.pullPolicy"))
+		Expect(err.Error()).To(ContainSubstring("urlExpiration.hook"))
+		Expect(err.Error()).To(ContainSubstring("findings.maxSize"))
+	})
+
+	It("should reject unknown fields", func() {
+		_, err := LoadOperatorConfig(filepath.Join("__testfiles__", "unknown_field_operator_config_test.yaml"))
+		Expect(err).Should(HaveOccurred())
+	})
+
+	Context("With environment variables set", func() {
+		env := map[string]string{
+			"S3_BUCKET":                    "from-env",
+			"S3_USE_SSL":                   "true",
+			"LURKER_PULL_POLICY":           "Never",
+			"URL_EXPIRATION_HOOK":          "2h",
+			"POD_OVERRIDES_ALLOWED_FIELDS": "securityContext, priorityClassName,,",
+			"MANAGE_JOB_RBAC":              "false",
+		}
+
+		BeforeEach(func() {
+			for name, value := range env {
+				os.Setenv(name, value)
+			}
+		})
+		AfterEach(func() {
+			for name := range env {
+				os.Unsetenv(name)
+			}
+			os.Unsetenv("URL_EXPIRATION_SCAN")
+		})
+
+		It("should prefer the environment variables over the values in the config file", func() {
+			cfg, err := LoadOperatorConfig(filepath.Join("__testfiles__", "operator_config_test.yaml"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(cfg.S3.Bucket).To(Equal("from-env"))
+			Expect(cfg.S3.UseSSL).To(BeTrue())
+			Expect(cfg.S3.Endpoint).To(Equal("minio.default.svc"))
+			Expect(cfg.Lurker.PullPolicy).To(Equal(corev1.PullNever))
+			Expect(cfg.URLExpiration.Hook.Duration).To(Equal(2 * time.Hour))
+			Expect(cfg.URLExpiration.Scan.Duration).To(Equal(12 * time.Hour))
+			Expect(cfg.PodOverrides.AllowedFields).To(Equal([]string{"securityContext", "priorityClassName"}))
+			Expect(cfg.ManageJobRBAC).To(BeFalse())
+		})
+
+		It("should return an error for malformed environment variables", func() {
+			os.Setenv("URL_EXPIRATION_SCAN", "one hour")
+
+			_, err := LoadOperatorConfig("")
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("URL_EXPIRATION_SCAN"))
+		})
+	})
+
+	Context("With the environment of the operator image", func() {
+		var imageEnv map[string]string
+
+		BeforeEach(func() {
+			dockerfile, err := os.ReadFile(filepath.Join("..", "Dockerfile"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			imageEnv = map[string]string{}
+			for _, line := range strings.Split(string(dockerfile), "\n") {
+				if name, value, ok := strings.Cut(strings.TrimPrefix(line, "ENV "), "="); ok && strings.HasPrefix(line, "ENV ") {
+					imageEnv[name] = strings.Trim(value, `"`)
+				}
+			}
+			for name, value := range imageEnv {
+				os.Setenv(name, value)
+			}
+		})
+		AfterEach(func() {
+			for name := range imageEnv {
+				os.Unsetenv(name)
+			}
+		})
+
+		It("should keep telemetry disabled if it is disabled in the config file", func() {
+			cfg, err := LoadOperatorConfig(filepath.Join("__testfiles__", "telemetry_disabled_operator_config_test.yaml"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(cfg.TelemetryEnabled).To(BeFalse())
+		})
+	})
+
+	It("should return an error if the file doesn't exist", func() {
+		_, err := LoadOperatorConfig(filepath.Join("__testfiles__", "does-not-exist.yaml"))
+		Expect(err).Should(HaveOccurred())
+	})
+})
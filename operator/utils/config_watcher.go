@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+)
+
+// OperatorConfigWatcher periodically reloads the operator config file and passes changed configs to OnChange.
+// Configs which fail to load or validate are logged and ignored, the operator keeps running with the last valid config.
+// The watcher polls instead of relying on inotify, as files of mounted ConfigMaps are replaced via symlink swaps.
+type OperatorConfigWatcher struct {
+	Filename string
+	Interval time.Duration
+	Log      logr.Logger
+	Current  config.OperatorConfig
+	OnChange func(config.OperatorConfig)
+}
+
+// Start runs the watcher until the context is cancelled. Implements manager.Runnable.
+func (w *OperatorConfigWatcher) Start(ctx context.Context) error {
+	if w.Filename == "" {
+		return nil
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.reload()
+		}
+	}
+}
+
+// NeedLeaderElection returns false as every replica of the operator has to pick up config changes.
+func (w *OperatorConfigWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *OperatorConfigWatcher) reload() {
+	cfg, err := LoadOperatorConfig(w.Filename)
+	if err != nil {
+		w.Log.Error(err, "Failed to reload operator config, keeping the previous config", "file", w.Filename)
+		return
+	}
+	if reflect.DeepEqual(cfg, w.Current) {
+		return
+	}
+
+	w.Log.Info("Operator config changed, applying new config", "file", w.Filename)
+	w.Current = cfg
+	w.OnChange(cfg)
+}
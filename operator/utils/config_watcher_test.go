@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/secureCodeBox/secureCodeBox/operator/internal/config"
+)
+
+var _ = Describe("OperatorConfigWatcher", func() {
+	var (
+		dir      string
+		filename string
+		changes  []config.OperatorConfig
+		watcher  *OperatorConfigWatcher
+	)
+
+	writeConfig := func(content string) {
+		Expect(os.WriteFile(filename, []byte(content), 0o600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "operator-config")
+		Expect(err).ShouldNot(HaveOccurred())
+		filename = filepath.Join(dir, "operator-config.yaml")
+		writeConfig("apiVersion: config.securecodebox.io/v1\nkind: OperatorConfig\n")
+		initial, err := LoadOperatorConfig(filename)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		changes = nil
+		watcher = &OperatorConfigWatcher{
+			Filename: filename,
+			Log:      logr.Discard(),
+			Current:  initial,
+			OnChange: func(cfg config.OperatorConfig) {
+				changes = append(changes, cfg)
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should not report unchanged configs", func() {
+		watcher.reload()
+		Expect(changes).To(BeEmpty())
+	})
+
+	It("should report changed configs", func() {
+		writeConfig("apiVersion: config.securecodebox.io/v1\nkind: OperatorConfig\nallowIstioSidecarInjectionInJobs: true\n")
+		watcher.reload()
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].AllowIstioSidecarInjectionInJobs).To(BeTrue())
+	})
+
+	It("should keep the previous config if the new one is invalid", func() {
+		writeConfig("apiVersion: config.securecodebox.io/v1\nkind: OperatorConfig\nlurker:\n  pullPolicy: Sometimes\n")
+		watcher.reload()
+		Expect(changes).To(BeEmpty())
+		Expect(watcher.Current.Lurker.PullPolicy).To(Equal(config.Default().Lurker.PullPolicy))
+	})
+})
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+)
+
+// findingsExpressionRegex matches expressions like `findings.count > 0`, `findings.severities.high >= 1` or `findings.categories["Open Port"] == 0`
+var findingsExpressionRegex = regexp.MustCompile(`^\s*findings\.(count|severities\.(informational|low|medium|high)|categories\["([^"]+)"\])\s*(==|!=|>=|<=|>|<)\s*(\d+)\s*$`)
+
+// MatchesHookCondition checks if the scan matches the `when` condition of a hook. Hooks without a condition match every scan.
+// Returns an error if one of the finding expressions of the condition is invalid.
+func MatchesHookCondition(condition *executionv1.HookCondition, scan *executionv1.Scan) (bool, error) {
+	if condition == nil {
+		return true, nil
+	}
+
+	if len(condition.ScanTypes) > 0 && !slices.Contains(condition.ScanTypes, scan.Spec.ScanType) {
+		return false, nil
+	}
+
+	for _, expression := range condition.Findings {
+		matches, err := evaluateFindingsExpression(expression, scan.Status.Findings)
+		if err != nil {
+			return false, err
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func evaluateFindingsExpression(expression string, stats executionv1.FindingStats) (bool, error) {
+	match := findingsExpressionRegex.FindStringSubmatch(expression)
+	if match == nil {
+		return false, fmt.Errorf("invalid findings expression '%s', expected e.g. 'findings.severities.high > 0'", expression)
+	}
+
+	var actual uint64
+	switch {
+	case match[1] == "count":
+		actual = stats.Count
+	case match[2] == "informational":
+		actual = stats.FindingSeverities.Informational
+	case match[2] == "low":
+		actual = stats.FindingSeverities.Low
+	case match[2] == "medium":
+		actual = stats.FindingSeverities.Medium
+	case match[2] == "high":
+		actual = stats.FindingSeverities.High
+	default:
+		actual = stats.FindingCategories[match[3]]
+	}
+
+	expected, err := strconv.ParseUint(match[5], 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid number in findings expression '%s': %w", expression, err)
+	}
+
+	switch match[4] {
+	case "==":
+		return actual == expected, nil
+	case "!=":
+		return actual != expected, nil
+	case ">":
+		return actual > expected, nil
+	case ">=":
+		return actual >= expected, nil
+	case "<":
+		return actual < expected, nil
+	default:
+		return actual <= expected, nil
+	}
+}
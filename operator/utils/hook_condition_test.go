@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	executionv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+)
+
+var _ = Describe("MatchesHookCondition", func() {
+	var scan *executionv1.Scan
+
+	BeforeEach(func() {
+		scan = &executionv1.Scan{
+			Spec: executionv1.ScanSpec{ScanType: "nmap"},
+			Status: executionv1.ScanStatus{
+				Findings: executionv1.FindingStats{
+					Count: 3,
+					FindingSeverities: executionv1.FindingSeverities{
+						Informational: 2,
+						High:          1,
+					},
+					FindingCategories: map[string]uint64{
+						"Open Port": 2,
+						"Host":      1,
+					},
+				},
+			},
+		}
+	})
+
+	It("should match every scan if the hook has no condition", func() {
+		Expect(MatchesHookCondition(nil, scan)).To(BeTrue())
+	})
+
+	It("should match the scan types", func() {
+		Expect(MatchesHookCondition(&executionv1.HookCondition{ScanTypes: []string{"nmap", "ssh-audit"}}, scan)).To(BeTrue())
+		Expect(MatchesHookCondition(&executionv1.HookCondition{ScanTypes: []string{"zap-baseline-scan"}}, scan)).To(BeFalse())
+	})
+
+	It("should evaluate the findings expressions", func() {
+		Expect(MatchesHookCondition(&executionv1.HookCondition{Findings: []string{"findings.count > 0"}}, scan)).To(BeTrue())
+		Expect(MatchesHookCondition(&executionv1.HookCondition{Findings: []string{"findings.severities.high>=1"}}, scan)).To(BeTrue())
+		Expect(MatchesHookCondition(&executionv1.HookCondition{Findings: []string{"findings.severities.medium != 0"}}, scan)).To(BeFalse())
+		Expect(MatchesHookCondition(&executionv1.HookCondition{Findings: []string{`findings.categories["Open Port"] == 2`}}, scan)).To(BeTrue())
+		Expect(MatchesHookCondition(&executionv1.HookCondition{Findings: []string{`findings.categories["Vulnerability"] < 1`}}, scan)).To(BeTrue())
+	})
+
+	It("should require all conditions to match", func() {
+		condition := &executionv1.HookCondition{
+			ScanTypes: []string{"nmap"},
+			Findings:  []string{"findings.count > 0", "findings.severities.low > 0"},
+		}
+		Expect(MatchesHookCondition(condition, scan)).To(BeFalse())
+	})
+
+	It("should return an error for invalid expressions", func() {
+		_, err := MatchesHookCondition(&executionv1.HookCondition{Findings: []string{"findings.severities.critical > 0"}}, scan)
+		Expect(err).To(MatchError("invalid findings expression 'findings.severities.critical > 0', expected e.g. 'findings.severities.high > 0'"))
+	})
+})
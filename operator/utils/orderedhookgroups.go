@@ -20,10 +20,16 @@ func CurrentHookGroup(orderedHookGroup [][]*executionv1.HookStatus) ([]*executio
 			case executionv1.InProgress:
 				return group, nil
 			case executionv1.Failed:
+				if IsHookFailureIgnored(hookStatus) {
+					continue
+				}
 				return nil, fmt.Errorf("hook %s failed to be executed", hookStatus.HookName)
 			case executionv1.Cancelled:
+				if IsHookFailureIgnored(hookStatus) {
+					continue
+				}
 				return nil, fmt.Errorf("hook %s was cancelled while it was executed", hookStatus.HookName)
-			case executionv1.Completed:
+			case executionv1.Completed, executionv1.Skipped:
 				// continue to next group
 			}
 		}
@@ -32,6 +38,24 @@ func CurrentHookGroup(orderedHookGroup [][]*executionv1.HookStatus) ([]*executio
 	return nil, nil
 }
 
+// IsHookFailureIgnored returns true if a failure of the hook shouldn't stop the following hooks.
+func IsHookFailureIgnored(hookStatus *executionv1.HookStatus) bool {
+	return hookStatus.FailurePolicy != nil && hookStatus.FailurePolicy.Type == executionv1.HookFailurePolicyIgnore
+}
+
+// FailedHooks returns the names of all hooks which failed or got cancelled.
+func FailedHooks(orderedHookGroup [][]*executionv1.HookStatus) []string {
+	failedHooks := []string{}
+	for _, group := range orderedHookGroup {
+		for _, hookStatus := range group {
+			if hookStatus.State == executionv1.Failed || hookStatus.State == executionv1.Cancelled {
+				failedHooks = append(failedHooks, hookStatus.HookName)
+			}
+		}
+	}
+	return failedHooks
+}
+
 func FromUnorderedList(hookStatuses []*executionv1.HookStatus) [][]*executionv1.HookStatus {
 	// Group hookStatuses into a map by their prio class
 	hooksByPrioClass := map[int][]*executionv1.HookStatus{}
@@ -70,6 +94,8 @@ func MapHooksToHookStatus(hooks []executionv1.ScanCompletionHook) []*executionv1
 			State:    executionv1.Pending,
 			Priority: hook.Spec.Priority,
 			Type:     hook.Spec.Type,
+
+			FailurePolicy: hook.Spec.FailurePolicy.DeepCopy(),
 		})
 	}
 
@@ -85,6 +111,8 @@ func MapClusterHooksToHookStatus(hooks []executionv1.ClusterScanCompletionHook)
 			State:    executionv1.Pending,
 			Priority: hook.Spec.Priority,
 			Type:     hook.Spec.Type,
+
+			FailurePolicy: hook.Spec.FailurePolicy.DeepCopy(),
 		})
 	}
 
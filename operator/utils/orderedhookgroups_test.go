@@ -261,6 +261,52 @@ var _ = Describe("HookOrderingGroup Retrival", func() {
 			Expect(currentHookGroup).To(BeNil())
 		})
 
+		It("Should skip failed hooks with the Ignore failure policy", func() {
+			ignore := &executionv1.HookFailurePolicy{Type: executionv1.HookFailurePolicyIgnore}
+			currentHookGroup, err := CurrentHookGroup([][]*executionv1.HookStatus{
+				{
+					{HookName: "rw-1", State: "Failed", JobName: "", Priority: 4, Type: "ReadAndWrite", FailurePolicy: ignore},
+				},
+				{
+					{HookName: "ro-1", State: "Pending", JobName: "", Priority: 4, Type: "ReadOnly"},
+				},
+			})
+
+			Expect(err).To(BeNil())
+			Expect(currentHookGroup).To(Equal(
+				[]*executionv1.HookStatus{
+					{HookName: "ro-1", State: "Pending", JobName: "", Priority: 4, Type: "ReadOnly"},
+				},
+			))
+		})
+
+		It("Should still fail for hooks with the Retry failure policy once they are marked as failed", func() {
+			retry := &executionv1.HookFailurePolicy{Type: executionv1.HookFailurePolicyRetry}
+			currentHookGroup, err := CurrentHookGroup([][]*executionv1.HookStatus{
+				{
+					{HookName: "rw-1", State: "Failed", JobName: "", Priority: 4, Type: "ReadAndWrite", FailurePolicy: retry},
+				},
+			})
+
+			Expect(err).To(MatchError("hook rw-1 failed to be executed"))
+			Expect(currentHookGroup).To(BeNil())
+		})
+
+		It("Should skip hooks which were skipped because of their when condition", func() {
+			currentHookGroup, err := CurrentHookGroup([][]*executionv1.HookStatus{
+				{
+					{HookName: "rw-1", State: "Skipped", JobName: "", Priority: 4, Type: "ReadAndWrite"},
+				},
+				{
+					{HookName: "ro-1", State: "Pending", JobName: "", Priority: 4, Type: "ReadOnly"},
+				},
+			})
+
+			Expect(err).To(BeNil())
+			Expect(currentHookGroup).To(HaveLen(1))
+			Expect(currentHookGroup[0].HookName).To(Equal("ro-1"))
+		})
+
 		It("Should return nil if no hooks are configured", func() {
 			currentHookGroup, err := CurrentHookGroup([][]*executionv1.HookStatus{})
 
@@ -19,6 +19,8 @@ func RetriggerScheduledScan(ctx context.Context, statusWriter client.StatusWrite
 	// past timestamp is calculated by subtracting the repeat Interval and 24 hours to ensure that it will work even when the auto-discovery and scheduledScan controller have a clock skew
 	fakedLastSchedule := metav1.Time{Time: time.Now().Add(-scheduledScan.Spec.Interval.Duration - 24*time.Hour)}
 	scheduledScan.Status.LastScheduleTime = &fakedLastSchedule
+	// runs skipped as missed must not prevent the retrigger
+	scheduledScan.Status.LastMissedTime = nil
 	err := statusWriter.Update(ctx, &scheduledScan)
 	if err != nil {
 		return fmt.Errorf("failed to restart ScheduledScan: %w", err)
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// SpreadOffset derives a deterministic offset in [0, window) from the key.
+// Different keys are distributed evenly across the window, the same key always gets the same offset. Offsets are truncated to seconds, as scheduled times are stored with second precision.
+func SpreadOffset(key string, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return time.Duration(hash.Sum64() % uint64(window)).Truncate(time.Second)
+}
+
+// NextSpreadSlot returns the first slot after the given time. Slots repeat every interval and are shifted by the offset from multiples of the interval since the unix epoch,
+// e.g. an interval of 24h and an offset of 2h places the slots at 02:00 UTC every day.
+func NextSpreadSlot(after time.Time, interval time.Duration, offset time.Duration) time.Time {
+	if interval <= 0 {
+		return after
+	}
+	offset %= interval
+	start := time.Unix(0, 0).Add(offset)
+	elapsed := after.Sub(start)
+	slot := start.Add(elapsed.Truncate(interval))
+	if !slot.After(after) {
+		slot = slot.Add(interval)
+	}
+	return slot.In(after.Location())
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpreadOffset", func() {
+	It("should always return the same offset for the same key", func() {
+		Expect(SpreadOffset("default/nmap", time.Hour)).To(Equal(SpreadOffset("default/nmap", time.Hour)))
+	})
+
+	It("should return offsets within the window", func() {
+		for i := 0; i < 100; i++ {
+			offset := SpreadOffset(fmt.Sprintf("default/scan-%d", i), time.Hour)
+			Expect(offset).To(BeNumerically(">=", 0))
+			Expect(offset).To(BeNumerically("<", time.Hour))
+		}
+	})
+
+	It("should spread different keys across the window", func() {
+		quarters := map[time.Duration]int{}
+		for i := 0; i < 1000; i++ {
+			quarters[SpreadOffset(fmt.Sprintf("default/scan-%d", i), time.Hour)/(15*time.Minute)]++
+		}
+		Expect(quarters).To(HaveLen(4))
+		for _, count := range quarters {
+			Expect(count).To(BeNumerically("~", 250, 50))
+		}
+	})
+
+	It("should return no offset without a window", func() {
+		Expect(SpreadOffset("default/nmap", 0)).To(Equal(time.Duration(0)))
+	})
+})
+
+var _ = Describe("NextSpreadSlot", func() {
+	It("should return the next slot after the given time", func() {
+		after := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		Expect(NextSpreadSlot(after, 24*time.Hour, 2*time.Hour)).To(Equal(time.Date(2026, 10, 20, 2, 0, 0, 0, time.UTC)))
+		Expect(NextSpreadSlot(after, 24*time.Hour, 14*time.Hour)).To(Equal(time.Date(2026, 10, 19, 14, 0, 0, 0, time.UTC)))
+	})
+
+	It("should return the following slot when the time is exactly on a slot", func() {
+		after := time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+		Expect(NextSpreadSlot(after, 24*time.Hour, 2*time.Hour)).To(Equal(time.Date(2026, 10, 20, 2, 0, 0, 0, time.UTC)))
+	})
+
+	It("should support intervals which aren't a divisor of a day", func() {
+		after := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		next := NextSpreadSlot(after, 7*time.Hour, 0)
+		Expect(next.After(after)).To(BeTrue())
+		Expect(next.Sub(after)).To(BeNumerically("<=", 7*time.Hour))
+		Expect(next.Unix() % int64((7 * time.Hour).Seconds())).To(Equal(int64(0)))
+	})
+})
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package parsersdk
+
+import (
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The finding format is shared with the operator, these aliases keep parsers independent of the operator module layout.
+type (
+	// Finding is a single finding in the secureCodeBox finding format, see https://www.securecodebox.io/docs/api/finding
+	// Parsers only have to set the name, category and severity, the id, parsed_at and scan fields are set by the parser-sdk.
+	Finding = findingsv1.Finding
+	// Severity indicates the severity of a finding
+	Severity = findingsv1.Severity
+	// Reference links a finding to further information, e.g. {Type: "CVE", Value: "CVE-2021-44228"}
+	Reference = findingsv1.Reference
+	// ScanSummary contains information about the scan that identified a finding
+	ScanSummary = findingsv1.ScanSummary
+)
+
+const (
+	SeverityInformational = findingsv1.SeverityInformational
+	SeverityLow           = findingsv1.SeverityLow
+	SeverityMedium        = findingsv1.SeverityMedium
+	SeverityHigh          = findingsv1.SeverityHigh
+)
+
+// AddIDsAndDates sets a random id and the parse time on all findings which don't have them yet.
+func AddIDsAndDates(findings []Finding) []Finding {
+	return findingsv1.AddIDsAndDates(findings)
+}
+
+// AddScanMetadata sets the summary of the scan on all findings.
+func AddScanMetadata(findings []Finding, summary ScanSummary) []Finding {
+	for i := range findings {
+		findings[i].Scan = summary
+	}
+	return findings
+}
+
+// scanSummaryOf returns the summary of a Scan object fetched from the kubernetes api.
+// The Scan is read as unstructured object, so that the parser-sdk doesn't depend on the operator module.
+func scanSummaryOf(scan *unstructured.Unstructured) ScanSummary {
+	scanType, _, _ := unstructured.NestedString(scan.Object, "spec", "scanType")
+	return ScanSummary{
+		CreatedAt: scan.GetCreationTimestamp().UTC(),
+		Name:      scan.GetName(),
+		Namespace: scan.GetNamespace(),
+		ScanType:  scanType,
+	}
+}
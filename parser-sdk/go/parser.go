@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package parsersdk wraps the contract between the secureCodeBox operator and parsers written in Go.
+//
+// The operator starts the parser with the url of the raw result file and the url to upload the findings to as arguments,
+// the name and namespace of the scan are passed in the SCAN_NAME and NAMESPACE environment variables.
+// Parsers only have to implement a ParseFunc and call Run from their main function:
+//
+//	func main() {
+//		parsersdk.Run(parse)
+//	}
+package parsersdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scanGVK identifies the Scan resource of the secureCodeBox operator
+var scanGVK = schema.GroupVersionKind{Group: "execution.securecodebox.io", Version: "v1", Kind: "Scan"}
+
+// ParseFunc turns the raw result file of a scanner into findings.
+// The id, parsed_at and scan fields of the findings can be omitted, they are set by the parser-sdk.
+type ParseFunc func(rawResult io.Reader) ([]Finding, error)
+
+// Parser downloads the raw result of a scan, parses it and uploads the findings.
+type Parser struct {
+	// Client is used to fetch the scan
+	Client client.Client
+	// HTTPClient is used to download the raw result and to upload the findings. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// ScanName and Namespace identify the scan which is parsed
+	ScanName  string
+	Namespace string
+	// CrashOnFailedValidation fails the parser if the findings are invalid instead of uploading them anyway
+	CrashOnFailedValidation bool
+}
+
+// Run parses the scan passed by the operator and exits the process with a non zero exit code if parsing failed.
+func Run(parse ParseFunc) {
+	log.Println("Starting Parser")
+
+	parser, err := newParserFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to start the parser: %s", err)
+	}
+	if len(os.Args) < 3 {
+		log.Fatal("Parser was started without the raw result and findings upload urls as arguments. This is normally done by the operator.")
+	}
+
+	if err := parser.Parse(context.Background(), os.Args[1], os.Args[2], parse); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Completed parser")
+}
+
+func newParserFromEnv() (*Parser, error) {
+	scanName := os.Getenv("SCAN_NAME")
+	if scanName == "" {
+		return nil, fmt.Errorf("parser was started without `SCAN_NAME` environment variable set. This is normally done by the operator")
+	}
+	namespace := os.Getenv("NAMESPACE")
+	if namespace == "" {
+		return nil, fmt.Errorf("parser was started without `NAMESPACE` environment variable set. This is normally done by the operator")
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubernetes config: %w", err)
+	}
+	kubeclient, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the kubernetes client: %w", err)
+	}
+
+	return &Parser{
+		Client:                  kubeclient,
+		ScanName:                scanName,
+		Namespace:               namespace,
+		CrashOnFailedValidation: os.Getenv("CRASH_ON_FAILED_VALIDATION") == "true",
+	}, nil
+}
+
+// Parse downloads the raw result, parses it, adds ids, dates and the scan metadata to the findings, validates them and uploads them.
+// The finding stats of the scan are computed by the operator once the parser is completed.
+func (p *Parser) Parse(ctx context.Context, rawResultURL string, findingsUploadURL string, parse ParseFunc) error {
+	scan := &unstructured.Unstructured{}
+	scan.SetGroupVersionKind(scanGVK)
+	if err := p.Client.Get(ctx, types.NamespacedName{Name: p.ScanName, Namespace: p.Namespace}, scan); err != nil {
+		return fmt.Errorf("failed to get Scan from the kubernetes api: %w", err)
+	}
+
+	log.Println("Fetching result file")
+	rawResult, err := p.fetchResultFile(ctx, rawResultURL)
+	if err != nil {
+		return err
+	}
+	defer rawResult.Close()
+	log.Println("Fetched result file")
+
+	findings, err := parse(rawResult)
+	if err != nil {
+		return fmt.Errorf("parser failed with error: %w", err)
+	}
+	if findings == nil {
+		findings = []Finding{}
+	}
+	log.Printf("Transformed raw result file into %d findings", len(findings))
+
+	findings = AddScanMetadata(AddIDsAndDates(findings), scanSummaryOf(scan))
+
+	log.Printf("Validating Findings. CRASH_ON_FAILED_VALIDATION is set to %t", p.CrashOnFailedValidation)
+	if err := Validate(findings); err != nil {
+		log.Printf("The Findings Validation failed with error(s):\n%s", err)
+		if p.CrashOnFailedValidation {
+			return fmt.Errorf("findings are invalid: %w", err)
+		}
+	} else {
+		log.Println("The Findings were successfully validated")
+	}
+
+	log.Println("Uploading results to the file storage service")
+	return p.uploadFindings(ctx, findingsUploadURL, findings)
+}
+
+func (p *Parser) fetchResultFile(ctx context.Context, rawResultURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawResultURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result file url: %w", err)
+	}
+	res, err := p.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch result file: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
+		return nil, fmt.Errorf("failed to fetch result file: HTTP %d", res.StatusCode)
+	}
+	return res.Body, nil
+}
+
+func (p *Parser) uploadFindings(ctx context.Context, findingsUploadURL string, findings []Finding) error {
+	body, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, findingsUploadURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid findings upload url: %w", err)
+	}
+	res, err := p.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("finding upload failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		return fmt.Errorf("finding upload failed with response code %d: %s", res.StatusCode, text)
+	}
+	return nil
+}
+
+func (p *Parser) httpClient() *http.Client {
+	if p.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return p.HTTPClient
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package parsersdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScan(createdAt metav1.Time) *unstructured.Unstructured {
+	scan := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"scanType": "nmap", "parameters": []any{"scanme.nmap.org"}},
+	}}
+	scan.SetGroupVersionKind(scanGVK)
+	scan.SetName("nmap")
+	scan.SetNamespace("default")
+	scan.SetCreationTimestamp(createdAt)
+	return scan
+}
+
+func TestParse(t *testing.T) {
+	testcases := []struct {
+		name                    string
+		parse                   ParseFunc
+		crashOnFailedValidation bool
+		expectedError           string
+		expectedFindings        int
+	}{
+		{
+			name: "Should upload the parsed findings with ids, dates and scan metadata",
+			parse: func(rawResult io.Reader) ([]Finding, error) {
+				data, _ := io.ReadAll(rawResult)
+				return []Finding{{Name: "Open Port: " + string(data), Category: "Open Port", Severity: SeverityInformational}}, nil
+			},
+			expectedFindings: 1,
+		},
+		{
+			name: "Should upload an empty array if there are no findings",
+			parse: func(rawResult io.Reader) ([]Finding, error) {
+				return nil, nil
+			},
+			expectedFindings: 0,
+		},
+		{
+			name: "Should upload invalid findings if CRASH_ON_FAILED_VALIDATION isn't set",
+			parse: func(rawResult io.Reader) ([]Finding, error) {
+				return []Finding{{Name: "Open Port", Category: "Open Port", Severity: "CRITICAL"}}, nil
+			},
+			expectedFindings: 1,
+		},
+		{
+			name: "Should fail for invalid findings if CRASH_ON_FAILED_VALIDATION is set",
+			parse: func(rawResult io.Reader) ([]Finding, error) {
+				return []Finding{{Name: "Open Port", Category: "Open Port", Severity: "CRITICAL"}}, nil
+			},
+			crashOnFailedValidation: true,
+			expectedError:           "findings are invalid: finding 0: field 'severity' must be one of INFORMATIONAL, LOW, MEDIUM or HIGH, got 'CRITICAL'",
+		},
+		{
+			name: "Should return the error of the parser",
+			parse: func(rawResult io.Reader) ([]Finding, error) {
+				return nil, errors.New("unexpected end of file")
+			},
+			expectedError: "parser failed with error: unexpected end of file",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var uploaded []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.Method {
+				case http.MethodGet:
+					_, _ = w.Write([]byte("22"))
+				case http.MethodPut:
+					uploaded, _ = io.ReadAll(req.Body)
+				}
+			}))
+			defer server.Close()
+
+			createdAt := metav1.NewTime(time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC))
+			parser := &Parser{
+				Client:                  fake.NewClientBuilder().WithObjects(newScan(createdAt)).Build(),
+				HTTPClient:              server.Client(),
+				ScanName:                "nmap",
+				Namespace:               "default",
+				CrashOnFailedValidation: tc.crashOnFailedValidation,
+			}
+
+			err := parser.Parse(context.Background(), server.URL+"/nmap-results.xml", server.URL+"/findings.json", tc.parse)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, uploaded)
+				return
+			}
+			assert.NoError(t, err)
+
+			var findings []Finding
+			assert.NoError(t, json.Unmarshal(uploaded, &findings))
+			assert.Len(t, findings, tc.expectedFindings)
+			for _, finding := range findings {
+				assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, finding.ID)
+				assert.False(t, finding.ParsedAt.IsZero())
+				assert.Equal(t, ScanSummary{CreatedAt: createdAt.UTC(), Name: "nmap", Namespace: "default", ScanType: "nmap"}, finding.Scan)
+			}
+		})
+	}
+}
+
+func TestParseFailsIfTheResultFileCantBeFetched(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	parser := &Parser{
+		Client:     fake.NewClientBuilder().WithObjects(newScan(metav1.Now())).Build(),
+		HTTPClient: server.Client(),
+		ScanName:   "nmap",
+		Namespace:  "default",
+	}
+
+	err := parser.Parse(context.Background(), server.URL, server.URL, func(rawResult io.Reader) ([]Finding, error) {
+		return nil, nil
+	})
+	assert.EqualError(t, err, "failed to fetch result file: HTTP 403")
+}
+
+func TestValidateParser(t *testing.T) {
+	assert.NoError(t, ValidateParser([]Finding{{Name: "Open Port", Category: "Open Port", Severity: SeverityLow}}))
+
+	err := ValidateParser([]Finding{{Severity: "critical", References: []Reference{{Type: "CVE"}}}})
+	assert.Error(t, err)
+	for _, problem := range []string{
+		"finding 0: required field 'name' is missing",
+		"finding 0: required field 'category' is missing",
+		"finding 0: field 'severity' must be one of INFORMATIONAL, LOW, MEDIUM or HIGH, got 'critical'",
+		"finding 0: field 'references[0]' must have a 'type' and a 'value'",
+	} {
+		assert.True(t, strings.Contains(err.Error(), problem), "expected %q to contain %q", err, problem)
+	}
+}
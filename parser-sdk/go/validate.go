@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package parsersdk
+
+import (
+	"time"
+
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+)
+
+// Validate checks that the findings comply with the secureCodeBox finding format.
+// The findings are validated like the operator does when the parser completes, which rejects findings suppressed by the parser.
+func Validate(findings []Finding) error {
+	return findingsv1.ValidateParsed(findings)
+}
+
+// ValidateParser checks if a parser sets all required fields of its findings. Adds sample ids, dates and scan metadata which would normally be set by the parser-sdk.
+// Intended to be used in the tests of parsers.
+func ValidateParser(findings []Finding) error {
+	sampleScan := ScanSummary{CreatedAt: time.Now().UTC(), Name: "sample-scan-name", Namespace: "sample-namespace", ScanType: "sample-scan-type"}
+	findings = AddIDsAndDates(append([]Finding(nil), findings...))
+	for i := range findings {
+		findings[i].Scan = sampleScan
+	}
+	return Validate(findings)
+}
@@ -3,12 +3,7 @@
 // SPDX-License-Identifier: Apache-2.0
 
 import { Buffer } from "node:buffer";
-import {
-  KubeConfig,
-  CustomObjectsApi,
-  setHeaderOptions,
-  PatchStrategy,
-} from "@kubernetes/client-node";
+import { KubeConfig, CustomObjectsApi } from "@kubernetes/client-node";
 
 // @ts-ignore: parsers are provided during the docker build of the acutal parsers.
 import { parse } from "./parser/parser.js";
@@ -17,7 +12,6 @@ import {
   addIdsAndDates,
   addScanMetadata,
   type Finding,
-  type Severity,
   type Scan,
 } from "./parser-utils.js";
 
@@ -46,13 +40,6 @@ if (!namespace) {
   process.exit(1);
 }
 
-function severityCount(findings: Finding[], severity: Severity) {
-  return findings.filter(
-    ({ severity: findingSeverity }) =>
-      findingSeverity.toUpperCase() === severity,
-  ).length;
-}
-
 async function uploadResultToFileStorageService(
   resultUploadUrl: string,
   findingsWithIdsAndDates: Finding[],
@@ -87,49 +74,6 @@ async function uploadResultToFileStorageService(
   }
 }
 
-async function updateScanStatus(findings: Finding[]) {
-  try {
-    const findingCategories = new Map();
-    for (const { category } of findings) {
-      if (findingCategories.has(category)) {
-        findingCategories.set(category, findingCategories.get(category) + 1);
-      } else {
-        findingCategories.set(category, 1);
-      }
-    }
-
-    await k8sApi.patchNamespacedCustomObjectStatus(
-      {
-        group: "execution.securecodebox.io",
-        version: "v1",
-        namespace,
-        plural: "scans",
-        name: scanName,
-        body: {
-          status: {
-            findings: {
-              count: findings.length,
-              severities: {
-                informational: severityCount(findings, "INFORMATIONAL"),
-                low: severityCount(findings, "LOW"),
-                medium: severityCount(findings, "MEDIUM"),
-                high: severityCount(findings, "HIGH"),
-              },
-              categories: Object.fromEntries(findingCategories.entries()),
-            },
-          },
-        },
-      },
-      setHeaderOptions("Content-Type", PatchStrategy.MergePatch),
-    );
-    console.log("Updated status successfully");
-  } catch (err) {
-    console.error("Failed to update Scan Status via the kubernetes api");
-    console.error(err);
-    process.exit(1);
-  }
-}
-
 async function extractScan(): Promise<Scan> {
   try {
     return await k8sApi.getNamespacedCustomObject({
@@ -237,8 +181,6 @@ async function main() {
     }
   }
 
-  await updateScanStatus(findings);
-
   console.log(`Uploading results to the file storage service`);
 
   await uploadResultToFileStorageService(resultUploadUrl, findingsWithMetadata);
@@ -8,14 +8,28 @@ go 1.26.2
 
 require (
 	github.com/google/go-github/v79 v79.0.0
+	github.com/secureCodeBox/secureCodeBox/operator/apis/findings v1.0.0
 	gitlab.com/gitlab-org/api/client-go v0.160.1
 	golang.org/x/oauth2 v0.30.0
 )
 
+require (
+	github.com/fxamacker/cbor/v2 v2.9.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	k8s.io/apimachinery v0.36.3 // indirect
+	k8s.io/klog/v2 v2.140.0 // indirect
+	sigs.k8s.io/json v0.0.0-20250730193827-2d320260d730 // indirect
+	sigs.k8s.io/randfill v1.0.0 // indirect
+)
+
 require (
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/hashicorp/go-retryablehttp v0.7.8 // indirect
 	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
-	golang.org/x/time v0.12.0 // indirect
+	golang.org/x/time v0.14.0 // indirect
 )
+
+// Only used when developing inside of the secureCodeBox repository, the image build passes the finding format as the "findings" build context
+replace github.com/secureCodeBox/secureCodeBox/operator/apis/findings => ../../../operator/apis/findings
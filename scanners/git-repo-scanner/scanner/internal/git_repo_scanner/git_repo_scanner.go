@@ -6,23 +6,16 @@ package gitreposcanner
 
 import (
 	"time"
-)
 
-type Finding struct {
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Category    string         `json:"category"`
-	OSILayer    string         `json:"osi_layer"`
-	Severity    string         `json:"severity"`
-	Attributes  map[string]any `json:"attributes"`
-}
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+)
 
 type GitType string
 
 // GitRepoScanner defines the interface that all scanners must implement
 type GitRepoScanner interface {
 	GitType() GitType
-	Process(startTime, endTime *time.Time) ([]Finding, error)
+	Process(startTime, endTime *time.Time) ([]findingsv1.Finding, error)
 }
 
 // BaseScanner provides common functionality for scanner implementations
@@ -42,13 +35,13 @@ func (b *BaseScanner) CreateFinding(
 	archived bool,
 	topics []string,
 	lastCommitID *string,
-) Finding {
-	finding := Finding{
+) findingsv1.Finding {
+	finding := findingsv1.Finding{
 		Name:        string(gitType) + " Repo",
 		Description: "A " + string(gitType) + " repository",
 		Category:    "Git Repository",
 		OSILayer:    "APPLICATION",
-		Severity:    "INFORMATIONAL",
+		Severity:    findingsv1.SeverityInformational,
 		Attributes: map[string]any{
 			"id":               repoID,
 			"web_url":          webURL,
@@ -71,3 +64,20 @@ func (b *BaseScanner) CreateFinding(
 
 	return finding
 }
+
+// PrepareFindings sets the id and parse date of the findings and checks that they comply with the finding format before they are written.
+// The scan of the findings is only known to the parser, which sets it when it completes the findings, so it is checked with a placeholder.
+func PrepareFindings(findings []findingsv1.Finding) ([]findingsv1.Finding, error) {
+	findings = findingsv1.AddIDsAndDates(findings)
+
+	placeholderScan := findingsv1.ScanSummary{CreatedAt: time.Now().UTC(), Name: "git-repo-scanner", Namespace: "default", ScanType: "git-repo-scanner"}
+	withScan := make([]findingsv1.Finding, len(findings))
+	for i, finding := range findings {
+		finding.Scan = placeholderScan
+		withScan[i] = finding
+	}
+	if err := findingsv1.Validate(withScan); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitreposcanner
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
+)
+
+func TestPrepareFindings(t *testing.T) {
+	scanner := BaseScanner{}
+	findings := []findingsv1.Finding{
+		scanner.CreateFinding(GitHub, "1", "https://github.com/secureCodeBox/secureCodeBox", "secureCodeBox/secureCodeBox", "Organization", "2", "secureCodeBox", "2019-01-01T00:00:00Z", "2026-01-01T00:00:00Z", "public", false, []string{"security"}, nil),
+	}
+
+	prepared, err := PrepareFindings(findings)
+	if err != nil {
+		t.Fatalf("PrepareFindings() returned an error for valid findings: %v", err)
+	}
+	if prepared[0].ID == "" || prepared[0].ParsedAt.IsZero() {
+		t.Errorf("PrepareFindings() didn't set the id and parse date: %+v", prepared[0])
+	}
+	if prepared[0].Scan != (findingsv1.ScanSummary{}) {
+		t.Errorf("PrepareFindings() must leave the scan to the parser, got %+v", prepared[0].Scan)
+	}
+
+	data, err := json.Marshal(prepared)
+	if err != nil {
+		t.Fatalf("failed to marshal findings: %v", err)
+	}
+	if !strings.Contains(string(data), `"severity":"INFORMATIONAL"`) || !strings.Contains(string(data), `"full_name":"secureCodeBox/secureCodeBox"`) {
+		t.Errorf("unexpected findings json: %s", data)
+	}
+}
+
+func TestPrepareFindingsRejectsInvalidFindings(t *testing.T) {
+	findings := []findingsv1.Finding{{Name: "GitHub Repo", Severity: "SEVERE"}}
+
+	_, err := PrepareFindings(findings)
+	if err == nil {
+		t.Fatal("PrepareFindings() didn't return an error for invalid findings")
+	}
+	if !strings.Contains(err.Error(), "required field 'category' is missing") || !strings.Contains(err.Error(), "field 'severity' must be one of") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/google/go-github/v79/github"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
 	"golang.org/x/oauth2"
 )
 
@@ -76,7 +77,7 @@ func (g *GitHubRepoScanner) GitType() GitType {
 	return GitHub
 }
 
-func (g *GitHubRepoScanner) Process(startTime, endTime *time.Time) ([]Finding, error) {
+func (g *GitHubRepoScanner) Process(startTime, endTime *time.Time) ([]findingsv1.Finding, error) {
 	if err := g.setup(); err != nil {
 		return nil, fmt.Errorf("failed to setup GitHub client: %w", err)
 	}
@@ -137,8 +138,8 @@ func (g *GitHubRepoScanner) trackAPICall() {
 	g.requestsSinceCheck++
 }
 
-func (g *GitHubRepoScanner) processRepos(startTime, endTime *time.Time) ([]Finding, error) {
-	var findings []Finding
+func (g *GitHubRepoScanner) processRepos(startTime, endTime *time.Time) ([]findingsv1.Finding, error) {
+	var findings []findingsv1.Finding
 
 	org, _, err := g.client.Organizations.Get(g.ctx, g.organization)
 	g.trackAPICall()
@@ -187,8 +188,8 @@ func (g *GitHubRepoScanner) processRepos(startTime, endTime *time.Time) ([]Findi
 func (g *GitHubRepoScanner) processReposPage(
 	repos []*github.Repository,
 	startTime, endTime *time.Time,
-) ([]Finding, bool, error) {
-	var findings []Finding
+) ([]findingsv1.Finding, bool, error) {
+	var findings []findingsv1.Finding
 
 	for _, repo := range repos {
 		if g.ignoreRepos[repo.GetID()] {
@@ -306,7 +307,7 @@ func (g *GitHubRepoScanner) respectGitHubRateLimit() error {
 	return nil
 }
 
-func (g *GitHubRepoScanner) createFindingFromRepo(repo *github.Repository) (Finding, error) {
+func (g *GitHubRepoScanner) createFindingFromRepo(repo *github.Repository) (findingsv1.Finding, error) {
 	var latestCommitID *string
 
 	if g.annotateLatestCommitID {
@@ -9,6 +9,7 @@ import (
 	"log"
 	"time"
 
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 	"golang.org/x/oauth2"
 )
@@ -76,7 +77,7 @@ func (g *GitLabRepoScanner) GitType() GitType {
 	return GitLab
 }
 
-func (g *GitLabRepoScanner) Process(startTime, endTime *time.Time) ([]Finding, error) {
+func (g *GitLabRepoScanner) Process(startTime, endTime *time.Time) ([]findingsv1.Finding, error) {
 	if err := g.authenticate(); err != nil {
 		return nil, fmt.Errorf("failed to authenticate: %w", err)
 	}
@@ -210,9 +211,9 @@ func (g *GitLabRepoScanner) respectRateLimit(resp *gitlab.Response) {
 	}
 }
 
-func (g *GitLabRepoScanner) processProjects(projects []*gitlab.Project) ([]Finding, error) {
+func (g *GitLabRepoScanner) processProjects(projects []*gitlab.Project) ([]findingsv1.Finding, error) {
 	projectCount := len(projects)
-	findings := make([]Finding, 0, projectCount)
+	findings := make([]findingsv1.Finding, 0, projectCount)
 
 	for i, project := range projects {
 		if !g.isNotIgnored(project) {
@@ -250,7 +251,7 @@ func (g *GitLabRepoScanner) createFindingFromProject(
 	project *gitlab.Project,
 	index int,
 	total int,
-) (Finding, error) {
+) (findingsv1.Finding, error) {
 	g.logger.Printf("(%d/%d) Add finding for repo %s with last activity at %s",
 		index+1, total, project.Name, project.LastActivityAt.String())
 
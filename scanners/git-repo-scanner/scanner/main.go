@@ -13,6 +13,7 @@ import (
 	"github.com/secureCodeBox/scanners/git-repo-scanner/scanner/internal/config"
 	gitreposcanner "github.com/secureCodeBox/scanners/git-repo-scanner/scanner/internal/git_repo_scanner"
 	"github.com/secureCodeBox/scanners/git-repo-scanner/scanner/internal/output"
+	findingsv1 "github.com/secureCodeBox/secureCodeBox/operator/apis/findings/v1"
 )
 
 var (
@@ -30,6 +31,11 @@ func main() {
 		logger.Fatalf("Error processing: %v", err)
 	}
 
+	findings, err = gitreposcanner.PrepareFindings(findings)
+	if err != nil {
+		logger.Fatalf("Findings don't comply with the finding format: %v", err)
+	}
+
 	logger.Println("Write findings to file...")
 	if err := output.WriteFindings(config.FileOutput, findings); err != nil {
 		logger.Fatalf("Failed to write findings: %v", err)
@@ -37,7 +43,7 @@ func main() {
 	logger.Println("Finished!")
 }
 
-func process(config *config.Config) ([]gitreposcanner.Finding, error) {
+func process(config *config.Config) ([]findingsv1.Finding, error) {
 	var scanner gitreposcanner.GitRepoScanner
 	var err error
 
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rerunHooksAnnotation is picked up by the operator to rerun the hooks of a finished scan
+const rerunHooksAnnotation = "securecodebox.io/rerun-hooks"
+
+func NewRerunHooksCommand() *cobra.Command {
+	rerunHooksCmd := &cobra.Command{
+		Use:   "rerun-hooks [scan name] [hook names...]",
+		Short: "Rerun the hooks of a finished scan",
+		Long:  `Rerun the ScanCompletionHooks of a scan which is already Done or Errored. Without hook names all hooks of the scan are rerun. Hooks which weren't part of the scan yet, e.g. newly installed persistence hooks, are added to the scan.`,
+		Args:  cobra.MinimumNArgs(1),
+		Example: `
+		# Rerun all hooks of scan "nmap"
+		scbctl rerun-hooks nmap
+
+		# Rerun only the hooks "slack" and "persistence-defectdojo"
+		scbctl rerun-hooks nmap slack persistence-defectdojo
+
+		# Rerun the hooks of a scan outside of your current namespace
+		scbctl rerun-hooks nmap --namespace foobar
+		`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scanName := args[0]
+			hookNames := args[1:]
+			kubeclient, namespace, err := clientProvider.GetClient(kubeconfigArgs)
+			if err != nil {
+				return fmt.Errorf("error initializing kubernetes client, your kubeconfig is likely malformed or invalid")
+			}
+
+			if namespaceFlag, err := cmd.Flags().GetString("namespace"); err == nil && namespaceFlag != "" {
+				namespace = namespaceFlag
+			}
+
+			var scan v1.Scan
+			err = kubeclient.Get(cmd.Context(), types.NamespacedName{Name: scanName, Namespace: namespace}, &scan)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					return fmt.Errorf("could not find Scan '%s' in namespace '%s'", scanName, namespace)
+				}
+				return fmt.Errorf("failed to get Scan: %s", err)
+			}
+
+			if scan.Status.State != "Done" && scan.Status.State != "Errored" {
+				return fmt.Errorf("scan '%s' is still running (state: '%s'), hooks can only be rerun for finished scans", scanName, scan.Status.State)
+			}
+
+			requestedHooks := "*"
+			if len(hookNames) > 0 {
+				requestedHooks = strings.Join(hookNames, ",")
+			}
+
+			patch := client.MergeFrom(scan.DeepCopy())
+			if scan.Annotations == nil {
+				scan.Annotations = map[string]string{}
+			}
+			scan.Annotations[rerunHooksAnnotation] = requestedHooks
+			if err := kubeclient.Patch(cmd.Context(), &scan, patch); err != nil {
+				return fmt.Errorf("failed to request rerun of hooks: %s", err)
+			}
+
+			if len(hookNames) > 0 {
+				fmt.Printf("🔁 Requested rerun of hooks '%s' for Scan '%s'\n", strings.Join(hookNames, "', '"), scanName)
+			} else {
+				fmt.Printf("🔁 Requested rerun of all hooks for Scan '%s'\n", scanName)
+			}
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			kubeclient, namespace, err := clientProvider.GetClient(kubeconfigArgs)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			if namespaceFlag, err := cmd.Flags().GetString("namespace"); err == nil && namespaceFlag != "" {
+				namespace = namespaceFlag
+			}
+
+			if len(args) == 0 {
+				var scans v1.ScanList
+				if err := kubeclient.List(cmd.Context(), &scans, client.InNamespace(namespace)); err != nil {
+					return nil, cobra.ShellCompDirectiveError
+				}
+
+				scanNames := make([]string, len(scans.Items))
+				for i, scan := range scans.Items {
+					scanNames[i] = scan.Name
+				}
+				return scanNames, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			var hooks v1.ScanCompletionHookList
+			if err := kubeclient.List(cmd.Context(), &hooks, client.InNamespace(namespace)); err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			hookNames := make([]string, len(hooks.Items))
+			for i, hook := range hooks.Items {
+				hookNames[i] = hook.Name
+			}
+			return hookNames, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+
+	return rerunHooksCmd
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: the secureCodeBox authors
+//
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/secureCodeBox/secureCodeBox/operator/apis/execution/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRerunHooksCommand(t *testing.T) {
+	testcases := []struct {
+		name               string
+		args               []string
+		state              v1.ScanState
+		expectedError      error
+		expectedAnnotation string
+	}{
+		{
+			name:               "Should request a rerun of all hooks",
+			args:               []string{"rerun-hooks", "nmap"},
+			state:              "Done",
+			expectedAnnotation: "*",
+		},
+		{
+			name:               "Should request a rerun of the given hooks",
+			args:               []string{"rerun-hooks", "nmap", "slack", "persistence-defectdojo"},
+			state:              "Errored",
+			expectedAnnotation: "slack,persistence-defectdojo",
+		},
+		{
+			name:          "Should return error if the scan is still running",
+			args:          []string{"rerun-hooks", "nmap"},
+			state:         "Scanning",
+			expectedError: errors.New("scan 'nmap' is still running (state: 'Scanning'), hooks can only be rerun for finished scans"),
+		},
+		{
+			name:          "Should return error if scan not found",
+			args:          []string{"rerun-hooks", "nonexistent-scan"},
+			state:         "Done",
+			expectedError: errors.New("could not find Scan 'nonexistent-scan' in namespace 'foobar'"),
+		},
+		{
+			name:          "Should return error if no scan name is provided",
+			args:          []string{"rerun-hooks"},
+			expectedError: errors.New("requires at least 1 arg(s), only received 0"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			utilruntime.Must(v1.AddToScheme(scheme))
+			scan := &v1.Scan{
+				ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "foobar"},
+				Status:     v1.ScanStatus{State: tc.state},
+			}
+			kubeclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(scan).Build()
+			clientProvider = &TestClientProvider{
+				Client:    kubeclient,
+				namespace: "foobar",
+				err:       nil,
+			}
+
+			rootCmd := NewRootCommand()
+			rootCmd.SetArgs(tc.args)
+			rootCmd.SilenceUsage = true
+
+			err := rootCmd.Execute()
+
+			if tc.expectedError != nil {
+				assert.Equal(t, tc.expectedError.Error(), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+
+			var updatedScan v1.Scan
+			assert.NoError(t, kubeclient.Get(context.Background(), types.NamespacedName{Name: "nmap", Namespace: "foobar"}, &updatedScan))
+			assert.Equal(t, tc.expectedAnnotation, updatedScan.Annotations[rerunHooksAnnotation])
+		})
+	}
+}
+
+func TestRerunHooksCommandCompletion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1.AddToScheme(scheme))
+	kubeclient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1.Scan{ObjectMeta: metav1.ObjectMeta{Name: "nmap", Namespace: "default"}},
+		&v1.ScanCompletionHook{ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "default"}},
+	).Build()
+	clientProvider = &TestClientProvider{Client: kubeclient, namespace: "default"}
+
+	cmd := NewRerunHooksCommand()
+
+	scans, _ := cmd.ValidArgsFunction(cmd, []string{}, "")
+	assert.Equal(t, []string{"nmap"}, scans)
+
+	hooks, _ := cmd.ValidArgsFunction(cmd, []string{"nmap"}, "")
+	assert.Equal(t, []string{"slack"}, hooks)
+}
@@ -26,6 +26,7 @@ func NewRootCommand() *cobra.Command {
 	rootCmd.AddCommand(NewScanCommand())
 	rootCmd.AddCommand(NewTriggerCommand())
 	rootCmd.AddCommand(NewCascadeCommand())
+	rootCmd.AddCommand(NewRerunHooksCommand())
 
 	return rootCmd
 }